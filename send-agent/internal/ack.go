@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+)
+
+// Delivery acknowledgement
+//
+// After a message is submitted the pane is captured until the prompt no longer
+// holds the message and Claude shows its busy indicator. Unconfirmed deliveries
+// are retried with backoff and finally reported as ErrDeliveryUnconfirmed.
+
+// ErrDeliveryUnconfirmed is returned when a message could not be confirmed as received
+var ErrDeliveryUnconfirmed = errors.New("delivery could not be confirmed")
+
+// deliveryState is what the pane tells about a submitted message
+type deliveryState struct {
+	inPrompt  bool // message is still in the input box (not submitted)
+	submitted bool // message left the input box
+	busy      bool // agent shows the busy indicator
+}
+
+// ConfirmDelivery waits for the agent to accept the message, retrying with backoff.
+// A message still sitting in the prompt is re-submitted; a dropped message is pasted again.
+func ConfirmDelivery(socket, target, message string, out io.Writer) error {
+	timeout := AckTimeout
+	for attempt := 1; attempt <= AckRetries; attempt++ {
+		state := waitForAck(socket, target, message, timeout)
+		if state.submitted && state.busy {
+			return nil
+		}
+
+		if attempt == AckRetries {
+			break
+		}
+
+		fmt.Fprintf(out, "🔁 Delivery not confirmed, retrying (attempt %d/%d)...\n", attempt+1, AckRetries)
+		switch {
+		case state.inPrompt:
+			if err := TmuxSendKeys(socket, target, "Enter"); err != nil {
+				return fmt.Errorf("Enter sending failed: %v", err)
+			}
+		case !state.submitted:
+			if err := DeliverMessage(socket, target, message); err != nil {
+				return fmt.Errorf("message re-sending failed: %v", err)
+			}
+		}
+		timeout *= AckBackoffFactor
+	}
+
+	return fmt.Errorf("%w: agent did not start working on the message in %s", ErrDeliveryUnconfirmed, target)
+}
+
+// waitForAck polls the pane until the message is submitted and the agent is busy, or the timeout expires
+func waitForAck(socket, target, message string, timeoutMs int) deliveryState {
+	snippet := messageSnippet(message)
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+
+	var state deliveryState
+	for {
+		if content, err := TmuxCapturePane(socket, target); err == nil {
+			current := inspectDelivery(content, snippet)
+			current.busy = current.busy || state.busy
+			state = current
+			if state.submitted && state.busy {
+				return state
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return state
+		}
+		time.Sleep(time.Duration(PollInterval) * time.Millisecond)
+	}
+}
+
+// inspectDelivery evaluates captured pane content against the start of the message
+func inspectDelivery(content, snippet string) deliveryState {
+	state := deliveryState{busy: agentstate.IsBusy(content)}
+
+	prompt, found := agentstate.PromptText(content)
+	if found {
+		state.inPrompt = strings.Contains(prompt, snippet) || strings.Contains(prompt, "[Pasted text")
+		state.submitted = !state.inPrompt
+		return state
+	}
+
+	// No input box (not a Claude pane): the message is submitted once it appears at all
+	state.submitted = strings.Contains(content, snippet)
+	return state
+}
+
+// messageSnippet returns the beginning of the first non-empty message line, used to find the message in the pane
+func messageSnippet(message string) string {
+	for _, line := range strings.Split(normalizeMessage(message), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > 30 {
+			line = string(runes[:30])
+		}
+		return line
+	}
+	return ""
+}
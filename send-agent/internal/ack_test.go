@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const paneWithPendingPrompt = `
+> previous question
+
+⏺ Previous answer
+
+╭──────────────────────────────────────────────╮
+│ > Please implement the login API and report  │
+│   back when done                             │
+╰──────────────────────────────────────────────╯
+  ? for shortcuts
+`
+
+const paneWorking = `
+> Please implement the login API and report back when done
+
+✻ Thinking… (3s · ↑ 120 tokens · esc to interrupt)
+
+╭──────────────────────────────────────────────╮
+│ >                                            │
+╰──────────────────────────────────────────────╯
+`
+
+const paneIdleAfterSubmit = `
+> Please implement the login API and report back when done
+
+⏺ Done.
+
+────────────────────────────────────────────────
+> 
+────────────────────────────────────────────────
+`
+
+func TestInspectDelivery(t *testing.T) {
+	snippet := messageSnippet("Please implement the login API and report back when done")
+
+	state := inspectDelivery(paneWithPendingPrompt, snippet)
+	assert.True(t, state.inPrompt)
+	assert.False(t, state.submitted)
+	assert.False(t, state.busy)
+
+	state = inspectDelivery(paneWorking, snippet)
+	assert.False(t, state.inPrompt)
+	assert.True(t, state.submitted)
+	assert.True(t, state.busy)
+
+	state = inspectDelivery(paneIdleAfterSubmit, snippet)
+	assert.True(t, state.submitted)
+	assert.False(t, state.busy)
+
+	state = inspectDelivery("│ > [Pasted text #1 +42 lines] │", snippet)
+	assert.True(t, state.inPrompt)
+}
+
+func TestMessageSnippet(t *testing.T) {
+	assert.Equal(t, "first line", messageSnippet("\n\n  first line  \nsecond"))
+	assert.Equal(t, "123456789012345678901234567890", messageSnippet("1234567890123456789012345678901234567890"))
+	assert.Equal(t, "", messageSnippet("\n\n"))
+}
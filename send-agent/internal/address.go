@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Cross-session addressing
+//
+// Agents of another team session are addressed as <session>:<agent>, e.g.
+// "send-agent team-b:manager ...". The target session must have been launched
+// by start-agents: its agents are found through the session manifest and the
+// @cca_agent pane options, never by counting panes. Messages to another
+// session carry the qualified sender ("from: team-a:manager") so that replies
+// find their way back, and are logged in both sessions.
+
+// ResolveAddress returns the session and recipient of an address; unqualified recipients
+// (agents, lists and groups) belong to defaultSession
+func ResolveAddress(address, defaultSession string) (string, string, error) {
+	session, recipient := topology.SplitAddress(address)
+	if session == "" {
+		if address != recipient {
+			return "", "", fmt.Errorf("invalid address '%s' (use <session>:<agent>)", address)
+		}
+		return defaultSession, recipient, nil
+	}
+
+	if recipient == "" {
+		return "", "", fmt.Errorf("invalid address '%s' (use <session>:<agent>)", address)
+	}
+	if !HasSession(session) {
+		return "", "", fmt.Errorf("session '%s' not found", session)
+	}
+	if !IsTeamSession(session) {
+		return "", "", fmt.Errorf("session '%s' was not launched by start-agents (cross-session messages need its team manifest)", session)
+	}
+	return session, recipient, nil
+}
+
+// IsQualifiedAddress reports whether the address names a session ("team-b:manager")
+func IsQualifiedAddress(address string) bool {
+	session, _ := topology.SplitAddress(address)
+	return session != ""
+}
+
+// SenderAddress returns how the recipient session sees the sender: agents of another
+// session are qualified with the session of the calling pane
+func SenderAddress(from, targetSession string) string {
+	if from == SenderUser || IsQualifiedAddress(from) {
+		return from
+	}
+	current := CurrentSession()
+	if current == "" || current == targetSession {
+		return from
+	}
+	return topology.QualifyAddress(current, from)
+}
+
+// mirrorHistory logs a message sent to another session in the sender's session as well,
+// with the recipient qualified so it is not mistaken for a local agent
+func mirrorHistory(record HistoryRecord) error {
+	home, from := topology.SplitAddress(record.From)
+	if home == "" || home == record.Session {
+		return nil
+	}
+
+	record.To = topology.QualifyAddress(record.Session, record.To)
+	record.From = from
+	record.Session = home
+	return AppendHistory(record)
+}
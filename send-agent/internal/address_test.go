@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAddress(t *testing.T) {
+	session, agent, err := ResolveAddress("manager", "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", session)
+	assert.Equal(t, "manager", agent)
+
+	session, agent, err = ResolveAddress("devs", "team-a")
+	require.NoError(t, err)
+	assert.Equal(t, "team-a", session)
+	assert.Equal(t, "devs", agent)
+
+	for _, address := range []string{":manager", "team-b:"} {
+		_, _, err := ResolveAddress(address, "team-a")
+		assert.ErrorContains(t, err, "invalid address", address)
+	}
+
+	_, _, err = ResolveAddress("no-such-session-12345:manager", "team-a")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestSenderAddress(t *testing.T) {
+	t.Setenv("TMUX_PANE", "")
+
+	assert.Equal(t, SenderUser, SenderAddress(SenderUser, "team-b"))
+	assert.Equal(t, "team-a:dev1", SenderAddress("team-a:dev1", "team-b"))
+	// Without a calling pane the sender's session is unknown
+	assert.Equal(t, "dev1", SenderAddress("dev1", "team-b"))
+}
+
+func TestReplyAgent_QualifiedSender(t *testing.T) {
+	to, err := HistoryRecord{ID: "3", From: "team-a:manager", To: "manager"}.ReplyAgent()
+	require.NoError(t, err)
+	assert.Equal(t, "team-a:manager", to)
+}
+
+func TestSend_MirrorsCrossSessionHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sender := &MessageSender{ID: "5", SessionName: "team-b", From: "team-a:manager", Agent: "manager", Message: "API is ready", Out: io.Discard}
+	sender.recordHistory(ResultDelivered, nil)
+
+	remote, err := LoadHistory("team-b", HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, remote, 1)
+	assert.Equal(t, "team-a:manager", remote[0].From)
+	assert.Equal(t, "manager", remote[0].To)
+
+	home, err := LoadHistory("team-a", HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, home, 1)
+	assert.Equal(t, "manager", home[0].From)
+	assert.Equal(t, "team-b:manager", home[0].To)
+	assert.Equal(t, "team-a", home[0].Session)
+
+	// The mirrored record is not a message received by the local manager
+	_, err = LastReceived("team-a", "manager")
+	assert.Error(t, err)
+
+	// Local messages are logged once
+	local := &MessageSender{ID: "6", SessionName: "team-a", From: "dev1", Agent: "manager", Message: "done", Out: io.Discard}
+	local.recordHistory(ResultDelivered, nil)
+	home, err = LoadHistory("team-a", HistoryFilter{Since: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, home, 2)
+}
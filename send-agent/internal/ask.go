@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Request/response mode
+//
+// "send-agent ask" sends a question and blocks until the target answers it
+// with "send-agent reply --to <id>". While the asker waits, the marker file
+// replies/<id>.wait exists in the session directory; replies to such messages
+// are written to replies/<id>.json instead of being pasted into a pane, and
+// the waiting asker prints them.
+
+// RepliesDirName is the name of the reply directory inside the session directory
+const RepliesDirName = "replies"
+
+// Ask timing
+const (
+	// ReplyPollInterval is how often a waiting asker checks for its reply (milliseconds)
+	ReplyPollInterval = 500
+	// DefaultAskTimeout is how long "send-agent ask" waits for a reply by default
+	DefaultAskTimeout = 10 * time.Minute
+)
+
+// Reply file suffixes
+const (
+	replyWaitSuffix = ".wait"
+	replySuffix     = ".json"
+)
+
+// ErrAskTimeout is returned when no reply arrives before the ask timeout
+var ErrAskTimeout = errors.New("no reply received")
+
+// ErrNotRecipient is returned when a question is answered by an agent it was not asked to
+var ErrNotRecipient = errors.New("question was not sent to the answering agent")
+
+// Reply is the answer to an asked question
+type Reply struct {
+	ID        string    `json:"id"`
+	InReplyTo string    `json:"in_reply_to"`
+	From      string    `json:"from"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+}
+
+// AskInstructions returns the note appended to a question telling the target how to answer
+func AskInstructions(id string) string {
+	return fmt.Sprintf("\n\n(The sender is waiting for your answer. Reply with: send-agent reply --to %s \"<answer>\")", id)
+}
+
+// replyPath returns the path of a reply file of the message
+func replyPath(sessionName, id, suffix string) string {
+	return filepath.Join(SessionDir(sessionName), RepliesDirName, id+suffix)
+}
+
+// validMessageID reports whether the ID can be used in a file name
+func validMessageID(id string) bool {
+	if id == "" || id[0] == '.' {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAwaitingReply reports whether an asker is blocked waiting for the answer to the message
+func IsAwaitingReply(sessionName, id string) bool {
+	if !validMessageID(id) {
+		return false
+	}
+	_, err := os.Stat(replyPath(sessionName, id, replyWaitSuffix))
+	return err == nil
+}
+
+// Ask sends the message as a question and blocks until it is answered or the timeout
+// (0 = no timeout) expires
+func (ms *MessageSender) Ask(timeout time.Duration) (*Reply, error) {
+	if ms.ID == "" {
+		ms.ID = ms.allocateID()
+	}
+	ms.Message += AskInstructions(ms.ID)
+
+	wait := replyPath(ms.SessionName, ms.ID, replyWaitSuffix)
+	if err := os.MkdirAll(filepath.Dir(wait), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create reply directory: %w", err)
+	}
+	if err := os.WriteFile(wait, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to register question: %w", err)
+	}
+	defer os.Remove(wait)
+
+	if err := ms.Send(); err != nil {
+		if !errors.Is(err, ErrDeliveryUnconfirmed) {
+			return nil, err
+		}
+		ms.printf("⚠️ %v; waiting for a reply anyway\n", err)
+	}
+
+	ms.printf("⏳ Waiting for %s to answer msg %s...\n", ms.Agent, ms.ID)
+	return waitForReply(ms.SessionName, ms.ID, timeout)
+}
+
+// waitForReply polls for the reply file of the message and removes it once read
+func waitForReply(sessionName, id string, timeout time.Duration) (*Reply, error) {
+	path := replyPath(sessionName, id, replySuffix)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		if data, err := os.ReadFile(path); err == nil { // #nosec G304
+			var reply Reply
+			if err := json.Unmarshal(data, &reply); err != nil {
+				return nil, fmt.Errorf("failed to parse reply %s: %w", path, err)
+			}
+			_ = os.Remove(path)
+			return &reply, nil
+		}
+
+		if timeout > 0 && !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w to msg %s within %s", ErrAskTimeout, id, timeout)
+		}
+		time.Sleep(time.Duration(ReplyPollInterval) * time.Millisecond)
+	}
+}
+
+// AnswerAsk hands the answer to the asker waiting for the question and logs it. Only the agent
+// the question was sent to may answer it unless force is set; the routing policy and the loop
+// guard apply as for sent messages.
+func AnswerAsk(sessionName, from string, question *HistoryRecord, message string, force bool) (*Reply, error) {
+	if !validMessageID(question.ID) {
+		return nil, fmt.Errorf("invalid message ID '%s'", question.ID)
+	}
+	if from != question.To && !force {
+		return nil, fmt.Errorf("%w: msg %s was sent to %s, not %s (use --force to answer anyway)", ErrNotRecipient, question.ID, question.To, from)
+	}
+
+	answer := &MessageSender{
+		From:        from,
+		SessionName: sessionName,
+		Agent:       question.ReplyAddress(),
+		InReplyTo:   question.ID,
+		Message:     message,
+		Force:       force,
+	}
+	if err := answer.admit(); err != nil {
+		return nil, err
+	}
+
+	reply := &Reply{
+		ID:        newMessageID(from),
+		InReplyTo: question.ID,
+		From:      from,
+		Time:      time.Now(),
+		Message:   message,
+	}
+	if n, err := NextMessageNumber(sessionName); err == nil {
+		reply.ID = strconv.Itoa(n)
+	}
+
+	data, err := json.MarshalIndent(reply, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode reply: %w", err)
+	}
+
+	// Write to a temporary file first so the asker never reads a partial reply
+	path := replyPath(sessionName, question.ID, replySuffix)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write reply: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to write reply: %w", err)
+	}
+
+	record := HistoryRecord{
+		Time:      reply.Time,
+		ID:        reply.ID,
+		Session:   sessionName,
+		From:      from,
+		To:        question.ReplyAddress(),
+		Result:    ResultDelivered,
+		InReplyTo: question.ID,
+		Message:   message,
+	}
+	if err := AppendHistory(record); err != nil {
+		fmt.Printf("⚠️ Failed to record message history: %v\n", err)
+	}
+	return reply, nil
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+func TestValidMessageID(t *testing.T) {
+	for _, id := range []string{"42", "dev1-1700000000", "msg_1.2"} {
+		assert.True(t, validMessageID(id), id)
+	}
+	for _, id := range []string{"", "..", ".hidden", "../42", "a/b", "4 2"} {
+		assert.False(t, validMessageID(id), id)
+	}
+}
+
+func TestAnswerAsk_WaitForReply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "ask-test-session-12345"
+
+	question := HistoryRecord{Time: time.Now(), ID: "7", Session: session, From: SenderUser, To: "dev1", Result: ResultDelivered, Message: "Which port?"}
+	require.NoError(t, AppendHistory(question))
+
+	assert.False(t, IsAwaitingReply(session, "7"))
+	wait := replyPath(session, "7", replyWaitSuffix)
+	require.NoError(t, os.MkdirAll(filepath.Dir(wait), 0750))
+	require.NoError(t, os.WriteFile(wait, nil, 0600))
+	assert.True(t, IsAwaitingReply(session, "7"))
+
+	found, err := FindMessage(session, "7")
+	require.NoError(t, err)
+
+	done := make(chan *Reply)
+	go func() {
+		reply, err := waitForReply(session, "7", 5*time.Second)
+		assert.NoError(t, err)
+		done <- reply
+	}()
+
+	sent, err := AnswerAsk(session, "dev1", found, "8080", false)
+	require.NoError(t, err)
+
+	reply := <-done
+	require.NotNil(t, reply)
+	assert.Equal(t, "8080", reply.Message)
+	assert.Equal(t, "7", reply.InReplyTo)
+	assert.Equal(t, sent.ID, reply.ID)
+
+	// The reply file is consumed and the answer is logged
+	_, err = os.Stat(replyPath(session, "7", replySuffix))
+	assert.True(t, os.IsNotExist(err))
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	last := records[len(records)-1]
+	assert.Equal(t, "dev1", last.From)
+	assert.Equal(t, SenderUser, last.To)
+	assert.Equal(t, "7", last.InReplyTo)
+}
+
+func TestAnswerAsk_Checks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "ask-check-session-12345"
+
+	m := &manifest.Manifest{SessionName: session, Routes: map[string][]string{topology.RoleDev: {topology.RoleManager}}}
+	_, err := m.Save(manifest.ConfigDir())
+	require.NoError(t, err)
+
+	question := &HistoryRecord{Time: time.Now(), ID: "9", Session: session, From: "manager", To: "dev1", Result: ResultDelivered}
+	require.NoError(t, AppendHistory(*question))
+	wait := replyPath(session, "9", replyWaitSuffix)
+	require.NoError(t, os.MkdirAll(filepath.Dir(wait), 0750))
+	require.NoError(t, os.WriteFile(wait, nil, 0600))
+
+	// Only the agent the question was sent to may answer it
+	_, err = AnswerAsk(session, "dev2", question, "8080", false)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNotRecipient))
+
+	// The routing policy applies to answers as well, even when forced
+	question.From = "po"
+	_, err = AnswerAsk(session, "dev1", question, "8080", true)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRouteDenied))
+	_, err = os.Stat(replyPath(session, "9", replySuffix))
+	assert.True(t, os.IsNotExist(err))
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	last := records[len(records)-1]
+	assert.Equal(t, ResultRejected, last.Result)
+	assert.Equal(t, "po", last.To)
+
+	question.From = "manager"
+	_, err = AnswerAsk(session, "dev2", question, "8080", true)
+	require.NoError(t, err)
+}
+
+func TestWaitForReply_Timeout(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := waitForReply("ask-timeout-session-12345", "1", 10*time.Millisecond)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrAskTimeout))
+}
+
+func TestFindMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "find-test-session-12345"
+
+	require.NoError(t, AppendHistory(HistoryRecord{Time: time.Now(), ID: "3", Session: session, From: "po", To: "manager", Result: ResultFailed}))
+	_, err := FindMessage(session, "3")
+	assert.Error(t, err, "failed deliveries cannot be answered")
+
+	require.NoError(t, AppendHistory(HistoryRecord{Time: time.Now(), ID: "4", Session: session, From: "po", To: "manager", Result: ResultQueued}))
+	record, err := FindMessage(session, "4")
+	require.NoError(t, err)
+	to, err := record.ReplyAgent()
+	require.NoError(t, err)
+	assert.Equal(t, "po", to)
+}
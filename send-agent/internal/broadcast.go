@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Broadcast and group addressing
+//
+// A recipient spec may list several agents ("dev1,dev3") or name a group:
+// "devs", "all" or a user-defined group from the GROUP_<NAME> entries of
+// agents.conf (recorded in the session manifest at launch).
+
+// BroadcastResult is the outcome of delivering a broadcast to one recipient
+type BroadcastResult struct {
+	Agent  string
+	Result string
+	Err    error
+	Log    string
+}
+
+// ResolveRecipients expands a recipient spec into the agents of the session
+func ResolveRecipients(sessionName, spec string) ([]string, error) {
+	_, targets, err := resolveAgentTargets(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	agents := make([]string, 0, len(targets))
+	for _, t := range targets {
+		agents = append(agents, t.name)
+	}
+
+	var groups map[string][]string
+	if m, err := LoadSessionManifest(sessionName); err == nil {
+		groups = m.Groups
+	}
+
+	return topology.ExpandRecipients(spec, agents, groups)
+}
+
+// Broadcast sends the message of the template sender to every recipient concurrently.
+// Results are returned in recipient order; each sender's progress output is kept in Log.
+func Broadcast(template MessageSender, recipients []string) []BroadcastResult {
+	results := make([]BroadcastResult, len(recipients))
+
+	var wg sync.WaitGroup
+	for i, agent := range recipients {
+		wg.Add(1)
+		go func(i int, agent string) {
+			defer wg.Done()
+
+			var buf bytes.Buffer
+			sender := template
+			sender.Agent = agent
+			sender.ID = ""
+			sender.Out = &buf
+
+			err := sender.Send()
+			results[i] = BroadcastResult{Agent: agent, Result: sender.Result, Err: err, Log: buf.String()}
+		}(i, agent)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// WriteBroadcastReport writes one line per recipient and returns an error when any delivery failed.
+// The error wraps ErrDeliveryUnconfirmed when all failures are unconfirmed deliveries.
+func WriteBroadcastReport(w io.Writer, results []BroadcastResult) error {
+	var failed []string
+	unconfirmedOnly := true
+
+	fmt.Fprintf(w, "📣 Broadcast to %d agents:\n", len(results))
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed = append(failed, r.Agent)
+			if !errors.Is(r.Err, ErrDeliveryUnconfirmed) {
+				unconfirmedOnly = false
+			}
+			fmt.Fprintf(w, "   ❌ %-8s %v\n", r.Agent, r.Err)
+		case r.Result == ResultQueued:
+			fmt.Fprintf(w, "   📥 %-8s queued (agent busy)\n", r.Agent)
+		default:
+			fmt.Fprintf(w, "   ✅ %-8s %s\n", r.Agent, r.Result)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	summary := fmt.Sprintf("delivery failed for %d of %d agents (%s)", len(failed), len(results), strings.Join(failed, ", "))
+	if unconfirmedOnly {
+		return fmt.Errorf("%w: %s", ErrDeliveryUnconfirmed, summary)
+	}
+	return errors.New(summary)
+}
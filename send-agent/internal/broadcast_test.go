@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBroadcastReport(t *testing.T) {
+	t.Run("All delivered or queued", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteBroadcastReport(&buf, []BroadcastResult{
+			{Agent: "dev1", Result: ResultDelivered},
+			{Agent: "dev2", Result: ResultQueued},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Broadcast to 2 agents")
+		assert.Contains(t, buf.String(), "✅ dev1")
+		assert.Contains(t, buf.String(), "📥 dev2")
+	})
+
+	t.Run("Unconfirmed only", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteBroadcastReport(&buf, []BroadcastResult{
+			{Agent: "dev1", Result: ResultDelivered},
+			{Agent: "dev2", Result: ResultUnconfirmed, Err: fmt.Errorf("%w: timeout", ErrDeliveryUnconfirmed)},
+		})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrDeliveryUnconfirmed))
+		assert.Contains(t, err.Error(), "1 of 2 agents (dev2)")
+	})
+
+	t.Run("Other failures", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteBroadcastReport(&buf, []BroadcastResult{
+			{Agent: "dev1", Result: ResultUnconfirmed, Err: ErrDeliveryUnconfirmed},
+			{Agent: "dev3", Err: errors.New("pane not found")},
+		})
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrDeliveryUnconfirmed))
+		assert.Contains(t, err.Error(), "2 of 2 agents (dev1, dev3)")
+		assert.Contains(t, buf.String(), "❌ dev3")
+	})
+}
+
+func TestBroadcast_ReportsEachRecipient(t *testing.T) {
+	template := MessageSender{SessionName: "nonexistent-broadcast-session-12345", Message: "hello"}
+	results := Broadcast(template, []string{"dev1", "dev2"})
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "dev1", results[0].Agent)
+	assert.Equal(t, "dev2", results[1].Agent)
+	for _, r := range results {
+		assert.Error(t, r.Err)
+		assert.Contains(t, r.Log, "individual session mode")
+	}
+}
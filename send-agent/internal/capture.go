@@ -0,0 +1,255 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Reading agent output
+//
+// "send-agent tail", "capture" and "grep" read pane content through
+// capture-pane (scrollback included), with escape sequences removed.
+// --follow polls the pane and prints lines that appeared since the last
+// poll; a line is only printed once it has stayed on screen for
+// FollowStableFor, so spinners and progress lines that redraw in place are
+// not repeated on every poll.
+
+// Capture settings
+const (
+	// DefaultTailLines is the number of lines printed by "send-agent tail"
+	DefaultTailLines = 50
+	// FollowPollInterval is how often a followed pane is captured (milliseconds)
+	FollowPollInterval = 500
+	// FollowStableFor is how long a new line must stay unchanged before --follow prints it
+	FollowStableFor = 1500 * time.Millisecond
+	// FollowScrollback is the number of scrollback lines compared between polls
+	FollowScrollback = 200
+)
+
+// ScrollbackAll captures the whole scrollback of a pane
+const ScrollbackAll = -1
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+
+// StripANSI removes terminal escape sequences and carriage returns
+func StripANSI(text string) string {
+	return strings.ReplaceAll(ansiPattern.ReplaceAllString(text, ""), "\r", "")
+}
+
+// AgentTarget returns the tmux target of an agent of the session
+func AgentTarget(sessionName, agent string) (string, error) {
+	_, targets, err := resolveAgentTargets(sessionName)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range targets {
+		if t.name == agent {
+			return t.target, nil
+		}
+	}
+	return "", fmt.Errorf("agent '%s' not found in session '%s'", agent, sessionName)
+}
+
+// ReadPaneLines returns the content of the target pane of the socket's server as lines without escape
+// sequences and trailing blank lines. scrollback is the number of history lines to include (0 = visible
+// screen only, ScrollbackAll = everything).
+func ReadPaneLines(socket, target string, scrollback int) ([]string, error) {
+	var content string
+	var err error
+	switch {
+	case scrollback == 0:
+		content, err = TmuxCapturePane(socket, target)
+	case scrollback < 0:
+		content, err = TmuxCapturePaneHistory(socket, target, "-")
+	default:
+		content, err = TmuxCapturePaneHistory(socket, target, fmt.Sprintf("-%d", scrollback))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return splitPaneLines(content), nil
+}
+
+// splitPaneLines splits captured content into lines, dropping trailing whitespace and blank lines at the end
+func splitPaneLines(content string) []string {
+	lines := strings.Split(StripANSI(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LastLines returns the last n lines (all lines when n <= 0)
+func LastLines(lines []string, n int) []string {
+	if n <= 0 || len(lines) <= n {
+		return lines
+	}
+	return lines[len(lines)-n:]
+}
+
+// FollowPane writes the last n lines of the pane and then new lines as they appear, until stop is closed
+func FollowPane(w io.Writer, socket, target string, n int, stop <-chan struct{}) error {
+	lines, err := ReadPaneLines(socket, target, FollowScrollback)
+	if err != nil {
+		return err
+	}
+	for _, line := range LastLines(lines, n) {
+		fmt.Fprintln(w, line)
+	}
+
+	follower := newPaneFollower(lines)
+	ticker := time.NewTicker(time.Duration(FollowPollInterval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := ReadPaneLines(socket, target, FollowScrollback)
+		if err != nil {
+			return err
+		}
+		for _, line := range follower.update(current, time.Now()) {
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// paneFollower finds lines added to a pane between captures
+type paneFollower struct {
+	base    []string             // content already accounted for
+	pending map[string]time.Time // new lines not yet printed, with the time they first appeared
+}
+
+func newPaneFollower(lines []string) *paneFollower {
+	return &paneFollower{base: lines, pending: map[string]time.Time{}}
+}
+
+// update returns the lines of current that are new compared to the printed content and have stayed
+// on screen for FollowStableFor
+func (f *paneFollower) update(current []string, now time.Time) []string {
+	inserted := insertedLines(f.base, current)
+
+	var ready []string
+	var base []string
+	pending := map[string]time.Time{}
+	for i, line := range current {
+		if !inserted[i] {
+			base = append(base, line)
+			continue
+		}
+
+		firstSeen, ok := f.pending[line]
+		if !ok {
+			firstSeen = now
+		}
+		if now.Sub(firstSeen) >= FollowStableFor {
+			ready = append(ready, line)
+			base = append(base, line)
+			continue
+		}
+		pending[line] = firstSeen
+	}
+
+	f.base = base
+	f.pending = pending
+	return ready
+}
+
+// insertedLines marks the lines of current that are not part of the longest common subsequence with previous
+func insertedLines(previous, current []string) []bool {
+	n, m := len(previous), len(current)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case previous[i] == current[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	inserted := make([]bool, m)
+	i, j := 0, 0
+	for j < m {
+		switch {
+		case i < n && previous[i] == current[j]:
+			i++
+			j++
+		case i < n && lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			inserted[j] = true
+			j++
+		}
+	}
+	return inserted
+}
+
+// GrepMatch is a pane line matching a grep pattern
+type GrepMatch struct {
+	Agent string `json:"agent" yaml:"agent"`
+	Line  int    `json:"line" yaml:"line"`
+	Text  string `json:"text" yaml:"text"`
+}
+
+// GrepResult is the outcome of "send-agent grep"
+type GrepResult struct {
+	Session string      `json:"session" yaml:"session"`
+	Pattern string      `json:"pattern" yaml:"pattern"`
+	Matches []GrepMatch `json:"matches" yaml:"matches"`
+}
+
+// WriteTable writes one "agent:line: text" line per match
+func (r *GrepResult) WriteTable(w io.Writer) error {
+	for _, m := range r.Matches {
+		fmt.Fprintf(w, "%s:%d: %s\n", m.Agent, m.Line, m.Text)
+	}
+	return nil
+}
+
+// GrepPanes searches the scrollback of the given agents of the session
+func GrepPanes(sessionName string, agents []string, pattern *regexp.Regexp) ([]GrepMatch, error) {
+	_, targets, err := resolveAgentTargets(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{}
+	for _, agent := range agents {
+		selected[agent] = true
+	}
+
+	var matches []GrepMatch
+	for _, t := range targets {
+		if len(selected) > 0 && !selected[t.name] {
+			continue
+		}
+
+		lines, err := ReadPaneLines(SessionSocket(sessionName), t.target, ScrollbackAll)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.name, err)
+		}
+		for i, line := range lines {
+			if pattern.MatchString(line) {
+				matches = append(matches, GrepMatch{Agent: t.name, Line: i + 1, Text: line})
+			}
+		}
+	}
+	return matches, nil
+}
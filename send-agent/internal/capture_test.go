@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "ok done", StripANSI("\x1b[32mok\x1b[0m done\r"))
+	assert.Equal(t, "title", StripANSI("\x1b]0;window\x07title"))
+	assert.Equal(t, "a b", StripANSI("a\x1b[?25l \x1b[2Kb"))
+}
+
+func TestSplitPaneLines(t *testing.T) {
+	lines := splitPaneLines("one  \n\x1b[1mtwo\x1b[0m\n\nthree\n\n\n")
+	assert.Equal(t, []string{"one", "two", "", "three"}, lines)
+
+	assert.Equal(t, []string{"two", "", "three"}, LastLines(lines, 3))
+	assert.Equal(t, lines, LastLines(lines, 0))
+	assert.Equal(t, lines, LastLines(lines, 10))
+}
+
+func TestInsertedLines(t *testing.T) {
+	// Output scrolled by one line and one line was appended
+	assert.Equal(t, []bool{false, false, true}, insertedLines([]string{"a", "b", "c"}, []string{"b", "c", "d"}))
+
+	// Output appended above a fixed footer (input box of a TUI)
+	assert.Equal(t, []bool{false, true, false, false}, insertedLines([]string{"a", "╭─", "╰─"}, []string{"a", "b", "╭─", "╰─"}))
+}
+
+func TestPaneFollower(t *testing.T) {
+	start := time.Now()
+	follower := newPaneFollower([]string{"$ make test", "╭─", "╰─"})
+
+	// New lines are held back until they stayed on screen long enough
+	assert.Empty(t, follower.update([]string{"$ make test", "ok pkg/a", "⠋ running", "╭─", "╰─"}, start))
+	assert.Empty(t, follower.update([]string{"$ make test", "ok pkg/a", "⠙ running", "╭─", "╰─"}, start.Add(time.Second)))
+
+	ready := follower.update([]string{"$ make test", "ok pkg/a", "ok pkg/b", "╭─", "╰─"}, start.Add(2*time.Second))
+	assert.Equal(t, []string{"ok pkg/a"}, ready, "spinner lines that changed are never printed")
+
+	ready = follower.update([]string{"$ make test", "ok pkg/a", "ok pkg/b", "╭─", "╰─"}, start.Add(4*time.Second))
+	assert.Equal(t, []string{"ok pkg/b"}, ready)
+
+	assert.Empty(t, follower.update([]string{"ok pkg/a", "ok pkg/b", "╭─", "╰─"}, start.Add(6*time.Second)))
+}
+
+func TestGrepResult_WriteTable(t *testing.T) {
+	result := &GrepResult{Matches: []GrepMatch{{Agent: "dev1", Line: 12, Text: "FAIL pkg/a"}, {Agent: "dev3", Line: 4, Text: "panic: nil map"}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, result.WriteTable(&buf))
+	assert.Equal(t, "dev1:12: FAIL pkg/a\ndev3:4: panic: nil map\n", buf.String())
+}
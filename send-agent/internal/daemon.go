@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// Per-session delivery daemon
+//
+// The daemon is started on demand by send-agent when a message is queued. It
+// polls the state of every agent with queued messages, delivers the oldest
+// message as soon as the agent is idle, and exits after the queue has been
+// empty for DaemonIdleExit or when the session is gone.
+
+// Daemon files inside the session directory
+const (
+	DaemonLockFile = "delivery.lock"
+	DaemonLogFile  = "delivery.log"
+
+	// DaemonCommand is the hidden send-agent subcommand that runs the daemon
+	DaemonCommand = "delivery-daemon"
+)
+
+// Daemon timing (milliseconds)
+const (
+	DaemonPollInterval = 1000
+	DaemonIdleExit     = 5 * 60 * 1000
+	DaemonMaxAttempts  = 5
+)
+
+// daemonStartMu serializes daemon start-up when several messages are queued concurrently (broadcast);
+// startedDaemons remembers sessions whose daemon this process already started
+var (
+	daemonStartMu  sync.Mutex
+	startedDaemons = map[string]bool{}
+)
+
+// EnsureDeliveryDaemon starts the delivery daemon of the session in the background unless it is running
+func EnsureDeliveryDaemon(sessionName string) error {
+	daemonStartMu.Lock()
+	defer daemonStartMu.Unlock()
+	if startedDaemons[sessionName] {
+		return nil
+	}
+
+	if err := startSessionProcess(sessionName, DaemonCommand, DaemonLockFile, DaemonLogFile); err != nil {
+		return fmt.Errorf("failed to start delivery daemon: %w", err)
+	}
+	startedDaemons[sessionName] = true
+	return nil
+}
+
+// startSessionProcess starts "send-agent <command> <session>" detached from the caller, with its output
+// appended to logName in the session directory, unless a process already holds lockName
+func startSessionProcess(sessionName, command, lockName, logName string) error {
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	lock, err := tryLock(filepath.Join(dir, lockName))
+	if err != nil {
+		// Lock is held: the process is already running
+		return nil
+	}
+	// Release immediately; the started process takes the lock itself
+	lock.release()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate send-agent executable: %w", err)
+	}
+
+	logFile, err := os.OpenFile(filepath.Join(dir, logName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, command, sessionName) // #nosec G204
+	// Hand the session's tmux server to the process
+	cmd.Env = append(os.Environ(), tmuxsocket.EnvSocket+"="+SessionSocket(sessionName))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// RunDeliveryDaemon delivers queued messages of the session until the queue stays empty
+func RunDeliveryDaemon(sessionName string) error {
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	lock, err := tryLock(filepath.Join(dir, DaemonLockFile))
+	if err != nil {
+		return fmt.Errorf("delivery daemon for session '%s' is already running", sessionName)
+	}
+	defer lock.release()
+
+	fmt.Printf("[%s] 📮 Delivery daemon started (session: %s, pid: %d)\n", timestamp(), sessionName, os.Getpid())
+
+	queue := NewMessageQueue(sessionName)
+	idleSince := time.Now()
+	for {
+		if !HasSession(sessionName) {
+			fmt.Printf("[%s] 🛑 Session '%s' is gone, stopping\n", timestamp(), sessionName)
+			return nil
+		}
+
+		pending, err := queue.Pending()
+		if err != nil {
+			fmt.Printf("[%s] ❌ %v\n", timestamp(), err)
+		}
+
+		if len(pending) == 0 {
+			if time.Since(idleSince) >= time.Duration(DaemonIdleExit)*time.Millisecond {
+				fmt.Printf("[%s] 💤 Queue empty, stopping\n", timestamp())
+				return nil
+			}
+		} else {
+			idleSince = time.Now()
+			deliverPending(queue, pending)
+		}
+
+		time.Sleep(time.Duration(DaemonPollInterval) * time.Millisecond)
+	}
+}
+
+// deliverPending delivers the next message (highest priority, then oldest) of each idle agent
+func deliverPending(queue *MessageQueue, pending []*QueuedMessage) {
+	handled := map[string]bool{}
+	for _, msg := range pending {
+		if handled[msg.Agent] {
+			continue
+		}
+		handled[msg.Agent] = true
+
+		snapshot, err := agentstate.InspectOn(SessionSocket(msg.SessionName), msg.Target)
+		if err != nil || snapshot.State != agentstate.StateIdle {
+			continue
+		}
+
+		fmt.Printf("[%s] 📤 Delivering %s to %s (%s)\n", timestamp(), msg.ID, msg.Agent, msg.Target)
+		sender := &MessageSender{
+			ID:           msg.ID,
+			From:         msg.From,
+			ReplyTo:      msg.ReplyTo,
+			InReplyTo:    msg.InReplyTo,
+			SessionName:  msg.SessionName,
+			Agent:        msg.Agent,
+			Message:      msg.Message,
+			ResetContext: msg.ResetContext,
+			Meta:         msg.Meta,
+			File:         msg.File,
+		}
+
+		err = sender.deliver(msg.Target)
+		switch {
+		case errors.Is(err, ErrDeliveryUnconfirmed):
+			// ConfirmDelivery already retried; pasting the message again could deliver it twice
+			fmt.Printf("[%s] ⚠️ Delivered %s but could not confirm it: %v\n", timestamp(), msg.ID, err)
+		case err != nil:
+			msg.Attempts++
+			fmt.Printf("[%s] ❌ Delivery of %s failed (attempt %d/%d): %v\n", timestamp(), msg.ID, msg.Attempts, DaemonMaxAttempts, err)
+			if msg.Attempts < DaemonMaxAttempts {
+				if err := queue.Update(msg); err != nil {
+					fmt.Printf("[%s] ❌ %v\n", timestamp(), err)
+				}
+				continue
+			}
+			fmt.Printf("[%s] 🗑️ Dropping %s after %d attempts\n", timestamp(), msg.ID, msg.Attempts)
+		}
+
+		if err := queue.Remove(msg); err != nil {
+			fmt.Printf("[%s] ❌ %v\n", timestamp(), err)
+		}
+	}
+}
+
+func timestamp() string {
+	return time.Now().Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package internal
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileLock is an exclusive lock held by creating the lock file
+type fileLock struct {
+	path string
+	file *os.File
+}
+
+// tryLock creates the lock file exclusively
+func tryLock(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	return &fileLock{path: path, file: file}, nil
+}
+
+func (l *fileLock) release() {
+	l.file.Close()
+	_ = os.Remove(l.path)
+}
+
+// detachedProcAttr returns no special attributes on Windows
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Message delivery through tmux paste buffers
+//
+// Messages are loaded into a named buffer and pasted with bracketed paste, so
+// the text is never interpreted as key names and newlines do not submit the
+// prompt early. The prompt is submitted once, after the pasted text has
+// settled in the pane.
+
+var bufferCounter uint64
+
+// newBufferName returns a buffer name unique to this process and call
+func newBufferName() string {
+	n := atomic.AddUint64(&bufferCounter, 1)
+	return fmt.Sprintf("cca-send-%d-%d-%d", os.Getpid(), time.Now().UnixNano(), n)
+}
+
+// normalizeMessage converts line endings to LF and drops trailing newlines
+func normalizeMessage(message string) string {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	message = strings.ReplaceAll(message, "\r", "\n")
+	return strings.TrimRight(message, "\n")
+}
+
+// DeliverMessage pastes the message into the target pane of the socket's server and submits it
+// with a single Enter
+func DeliverMessage(socket, target, message string) error {
+	message = normalizeMessage(message)
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("message is empty")
+	}
+
+	before, err := TmuxCapturePane(socket, target)
+	if err != nil {
+		return fmt.Errorf("failed to read pane %s: %v", target, err)
+	}
+
+	buffer := newBufferName()
+	if err := TmuxLoadBuffer(socket, buffer, message); err != nil {
+		return err
+	}
+	if err := TmuxPasteBuffer(socket, buffer, target); err != nil {
+		_ = TmuxDeleteBuffer(socket, buffer)
+		return err
+	}
+
+	if _, changed := waitForPaneSettled(socket, target, before, true, StableWindow, PasteSettleTimeout); !changed {
+		return fmt.Errorf("pasted message did not appear in pane %s", target)
+	}
+
+	if err := TmuxSendKeys(socket, target, "Enter"); err != nil {
+		return fmt.Errorf("Enter sending failed: %v", err)
+	}
+	return nil
+}
+
+// SendControlKey sends a control key and waits for the pane to settle instead of sleeping a fixed time
+func SendControlKey(socket, target, key string) error {
+	before, err := TmuxCapturePane(socket, target)
+	if err != nil {
+		return fmt.Errorf("failed to read pane %s: %v", target, err)
+	}
+	if err := TmuxSendKeys(socket, target, key); err != nil {
+		return err
+	}
+	waitForPaneSettled(socket, target, before, false, StableWindow, ClearSettleTimeout)
+	return nil
+}
+
+// waitForPaneSettled polls the pane until its content has not changed for stableMs.
+// With requireChange the content must first differ from before.
+// It returns the last captured content and whether it differs from before.
+func waitForPaneSettled(socket, target, before string, requireChange bool, stableMs, timeoutMs int) (string, bool) {
+	interval := time.Duration(PollInterval) * time.Millisecond
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+
+	last := before
+	stableSince := time.Now()
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		content, err := TmuxCapturePane(socket, target)
+		if err != nil {
+			return last, last != before
+		}
+
+		if content != last {
+			last = content
+			stableSince = time.Now()
+			continue
+		}
+
+		if requireChange && content == before {
+			continue
+		}
+		if time.Since(stableSince) >= time.Duration(stableMs)*time.Millisecond {
+			return content, content != before
+		}
+	}
+
+	return last, last != before
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Plain", "hello", "hello"},
+		{"CRLF", "line1\r\nline2\r\n", "line1\nline2"},
+		{"CR", "line1\rline2", "line1\nline2"},
+		{"TrailingNewlines", "report\n\n\n", "report"},
+		{"KeyNames", "Enter Escape C-c", "Enter Escape C-c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeMessage(tt.input))
+		})
+	}
+}
+
+func TestNewBufferName_Unique(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		name := newBufferName()
+		assert.True(t, strings.HasPrefix(name, "cca-send-"))
+		assert.False(t, seen[name], "buffer name %s is reused", name)
+		seen[name] = true
+	}
+}
+
+func TestDeliverMessage_EmptyMessage(t *testing.T) {
+	err := DeliverMessage("", "non-existing-session-12345:0", "\n\n")
+	assert.ErrorContains(t, err, "message is empty")
+}
+
+func TestDeliverMessage_Tmux(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux is not installed")
+	}
+
+	sessionName := fmt.Sprintf("cca-delivery-test-%d", time.Now().UnixNano())
+	require.NoError(t, exec.Command("tmux", "new-session", "-d", "-s", sessionName, "-x", "200", "-y", "50", "cat").Run())
+	defer func() { _ = exec.Command("tmux", "kill-session", "-t", sessionName).Run() }()
+
+	message := "-n Enter Escape C-c\nsecond line: 日本語 ünïcödé\n" + strings.Repeat("x", 5000)
+	require.NoError(t, DeliverMessage("", sessionName, message))
+
+	content, _ := waitForPaneSettled("", sessionName, "", true, StableWindow, PasteSettleTimeout)
+	assert.Contains(t, content, "-n Enter Escape C-c")
+	assert.Contains(t, content, "second line: 日本語 ünïcödé")
+
+	output, err := exec.Command("tmux", "list-buffers", "-F", "#{buffer_name}").Output()
+	if err == nil {
+		assert.NotContains(t, string(output), "cca-send-", "paste buffer should be deleted")
+	}
+}
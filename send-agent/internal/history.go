@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Persistent message log
+//
+// Every message handled by send-agent is appended as one JSON line to
+// ~/.claude/claude-code-agents/sessions/<session>/messages.jsonl, so the
+// conversation between the agents survives the tmux scrollback.
+
+// HistoryFileName is the name of the message log inside the session directory
+const HistoryFileName = "messages.jsonl"
+
+// SenderUser is the sender recorded for messages sent from outside an agent pane
+const SenderUser = "user"
+
+// Delivery results recorded in the message log
+const (
+	ResultDelivered   = "delivered"
+	ResultUnverified  = "unverified"
+	ResultUnconfirmed = "unconfirmed"
+	ResultQueued      = "queued"
+	ResultFailed      = "failed"
+	ResultRejected    = "rejected"
+	ResultBlocked     = "blocked"
+)
+
+// HistoryRecord is one entry of the message log
+type HistoryRecord struct {
+	Time      time.Time         `json:"time"`
+	ID        string            `json:"id"`
+	Session   string            `json:"session"`
+	From      string            `json:"from"`
+	To        string            `json:"to"`
+	Result    string            `json:"result"`
+	Error     string            `json:"error,omitempty"`
+	ReplyTo   string            `json:"reply_to,omitempty"`
+	InReplyTo string            `json:"in_reply_to,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	File      string            `json:"file,omitempty"`
+	Message   string            `json:"message"`
+}
+
+// HistoryFilter selects records of the message log; zero values match everything
+type HistoryFilter struct {
+	Agent string // sender or recipient
+	Since time.Time
+	Until time.Time
+	Text  string // case-insensitive substring of the message
+}
+
+// HistoryPath returns the message log path of the session
+func HistoryPath(sessionName string) string {
+	return filepath.Join(SessionDir(sessionName), HistoryFileName)
+}
+
+// AppendHistory appends a record to the session's message log
+func AppendHistory(record HistoryRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+
+	path := HistoryPath(record.Session)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+	if err != nil {
+		return fmt.Errorf("failed to open message log: %w", err)
+	}
+	defer file.Close()
+
+	// A single write per record keeps concurrent appends line-atomic
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write message log: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory reads the session's message log and returns the matching records, oldest first
+func LoadHistory(sessionName string, filter HistoryFilter) ([]HistoryRecord, error) {
+	file, err := os.Open(HistoryPath(sessionName)) // #nosec G304
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open message log: %w", err)
+	}
+	defer file.Close()
+
+	return readHistory(file, filter)
+}
+
+// readHistory decodes JSONL records, skipping lines that cannot be parsed
+func readHistory(r io.Reader, filter HistoryFilter) ([]HistoryRecord, error) {
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if filter.Match(record) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read message log: %w", err)
+	}
+	return records, nil
+}
+
+// Match reports whether the record passes the filter
+func (f HistoryFilter) Match(record HistoryRecord) bool {
+	if f.Agent != "" && record.From != f.Agent && record.To != f.Agent {
+		return false
+	}
+	if !f.Since.IsZero() && record.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && record.Time.After(f.Until) {
+		return false
+	}
+	if f.Text != "" && !strings.Contains(strings.ToLower(record.Message), strings.ToLower(f.Text)) {
+		return false
+	}
+	return true
+}
+
+// ParseHistoryTime parses a --since/--until value: RFC3339, "2006-01-02 15:04", "2006-01-02",
+// or a duration such as "30m" meaning that long ago
+func ParseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s' (use RFC3339, YYYY-MM-DD [HH:MM[:SS]] or a duration like 2h)", value)
+}
+
+// WriteHistory writes records in a human readable form
+func WriteHistory(w io.Writer, records []HistoryRecord) {
+	for _, record := range records {
+		result := record.Result
+		if record.Error != "" {
+			result += ": " + record.Error
+		}
+		fmt.Fprintf(w, "%s  %s → %s  [%s]  %s\n", record.Time.Local().Format("2006-01-02 15:04:05"), record.From, record.To, result, record.ID)
+		if record.File != "" {
+			fmt.Fprintf(w, "    📄 %s\n", record.File)
+		}
+		for _, line := range strings.Split(record.Message, "\n") {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
+
+// DetectSender infers the sending agent from the tmux pane send-agent runs in.
+// The @cca_agent pane option is preferred, then the pane title; outside an agent pane SenderUser is returned.
+func DetectSender() string {
+	pane := os.Getenv("TMUX_PANE")
+	if pane == "" {
+		return SenderUser
+	}
+
+	format := "#{" + manifest.OptionAgent + "}\t#{pane_title}"
+	output, err := tmuxsocket.CallerCommand("display-message", "-p", "-t", pane, format).Output()
+	if err != nil {
+		return SenderUser
+	}
+	return senderFromPane(strings.TrimRight(string(output), "\n"))
+}
+
+// senderFromPane picks the agent name out of "<@cca_agent>\t<pane_title>"
+func senderFromPane(info string) string {
+	fields := strings.SplitN(info, "\t", 2)
+	for _, field := range fields {
+		name := strings.ToLower(strings.TrimSpace(field))
+		if topology.IsAgentName(name) {
+			return name
+		}
+	}
+	return SenderUser
+}
+
+// newMessageID returns a unique ID for a message to the agent
+func newMessageID(agent string) string {
+	return fmt.Sprintf("%d-%s", time.Now().UnixNano(), agent)
+}
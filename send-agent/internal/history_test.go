@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendAndLoadHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "history-test-session-12345"
+
+	base := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	records := []HistoryRecord{
+		{Time: base, ID: "1-manager", Session: session, From: "po", To: "manager", Result: ResultDelivered, Message: "Start the project"},
+		{Time: base.Add(time.Hour), ID: "2-dev1", Session: session, From: "manager", To: "dev1", Result: ResultQueued, Message: "Implement login\nwith tests"},
+		{Time: base.Add(2 * time.Hour), ID: "3-manager", Session: session, From: "dev1", To: "manager", Result: ResultFailed, Error: "pane gone", Message: "Login DONE"},
+	}
+	for _, record := range records {
+		require.NoError(t, AppendHistory(record))
+	}
+
+	all, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, records, all)
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		ids    []string
+	}{
+		{"By agent as sender or recipient", HistoryFilter{Agent: "dev1"}, []string{"2-dev1", "3-manager"}},
+		{"Since", HistoryFilter{Since: base.Add(30 * time.Minute)}, []string{"2-dev1", "3-manager"}},
+		{"Until", HistoryFilter{Until: base.Add(time.Hour)}, []string{"1-manager", "2-dev1"}},
+		{"Text is case-insensitive", HistoryFilter{Text: "login done"}, []string{"3-manager"}},
+		{"Combined", HistoryFilter{Agent: "manager", Text: "login"}, []string{"2-dev1", "3-manager"}},
+		{"No match", HistoryFilter{Agent: "dev4"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := LoadHistory(session, tt.filter)
+			require.NoError(t, err)
+			var ids []string
+			for _, record := range found {
+				ids = append(ids, record.ID)
+			}
+			assert.Equal(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestLoadHistory_Missing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	records, err := LoadHistory("history-test-missing-12345", HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestReadHistory_SkipsBrokenLines(t *testing.T) {
+	input := `{"id":"1","to":"po","message":"ok"}
+not json
+{"id":"2","to":"dev1","message":"also ok"}
+`
+	records, err := readHistory(strings.NewReader(input), HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "2", records[1].ID)
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"2h", now.Add(-2 * time.Hour)},
+		{"2025-01-01T08:30:00Z", time.Date(2025, 1, 1, 8, 30, 0, 0, time.UTC)},
+		{"2025-01-01 08:30", time.Date(2025, 1, 1, 8, 30, 0, 0, time.UTC)},
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			parsed, err := ParseHistoryTime(tt.value, now)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), "expected %v, got %v", tt.expected, parsed)
+		})
+	}
+
+	_, err := ParseHistoryTime("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestSenderFromPane(t *testing.T) {
+	tests := []struct {
+		info     string
+		expected string
+	}{
+		{"manager\tManager", "manager"},
+		{"\tDev3", "dev3"},
+		{"\tPO", "po"},
+		{"\tbash", SenderUser},
+		{"", SenderUser},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, senderFromPane(tt.info), tt.info)
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	var buf bytes.Buffer
+	WriteHistory(&buf, []HistoryRecord{
+		{Time: time.Now(), ID: "1-dev1", From: "manager", To: "dev1", Result: ResultFailed, Error: "pane gone", Message: "line1\nline2"},
+	})
+
+	output := buf.String()
+	assert.Contains(t, output, "manager → dev1")
+	assert.Contains(t, output, "[failed: pane gone]")
+	assert.Contains(t, output, "    line1\n    line2\n")
+}
@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Message input
+//
+// Messages can be given as an argument, read from a file (-f/--file) or from
+// stdin ("-"). An optional front-matter block carries metadata:
+//
+//	---
+//	priority: high
+//	task: AUTH-12
+//	---
+//	Implement the login endpoint ...
+//
+// Messages larger than the inline limit are stored in the session's shared
+// directory and the agent receives a short pointer to the file instead.
+
+// StdinArg is the message argument that reads the message from stdin
+const StdinArg = "-"
+
+// SharedDirName is the directory inside the session directory holding large messages
+const SharedDirName = "shared"
+
+// DefaultMaxInlineSize is the largest message (bytes) pasted into the pane as is
+const DefaultMaxInlineSize = 4096
+
+// Well-known front-matter keys
+const (
+	MetaPriority = "priority"
+	MetaTask     = "task"
+)
+
+// Message priorities, lowest first
+var Priorities = []string{"low", "normal", "high", "urgent"}
+
+// frontMatterDelimiter opens and closes the front-matter block
+const frontMatterDelimiter = "---"
+
+// ReadMessageInput returns the raw message from the message argument (args following the recipient), --file or stdin
+func ReadMessageInput(args []string, file string, stdin io.Reader) (string, error) {
+	var raw []byte
+	var err error
+
+	switch {
+	case file != "":
+		if len(args) > 0 {
+			return "", fmt.Errorf("a message argument cannot be combined with --file")
+		}
+		if file == StdinArg {
+			raw, err = io.ReadAll(stdin)
+		} else {
+			raw, err = os.ReadFile(file) // #nosec G304
+		}
+	case len(args) > 0 && args[0] == StdinArg:
+		raw, err = io.ReadAll(stdin)
+	case len(args) > 0:
+		return args[0], nil
+	default:
+		return "", fmt.Errorf("no message given (pass it as argument, with --file <path>, or '-' to read stdin)")
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read message: %w", err)
+	}
+	if strings.TrimSpace(string(raw)) == "" {
+		return "", fmt.Errorf("message is empty")
+	}
+	return string(raw), nil
+}
+
+// ParseFrontMatter splits an optional leading "---" metadata block from the message body.
+// Keys are lower-cased; "task_id" and "task-id" are accepted for "task".
+func ParseFrontMatter(raw string) (map[string]string, string, error) {
+	text := strings.ReplaceAll(raw, "\r\n", "\n")
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		return nil, raw, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	meta := map[string]string{}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == frontMatterDelimiter {
+			body := strings.TrimLeft(strings.Join(lines[i+1:], "\n"), "\n")
+			if err := validateMeta(meta); err != nil {
+				return nil, "", err
+			}
+			return meta, body, nil
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("invalid front-matter line %d: %q (expected key: value)", i+1, line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "task_id" || key == "task-id" {
+			key = MetaTask
+		}
+		meta[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	return nil, "", fmt.Errorf("front-matter is not closed with '%s'", frontMatterDelimiter)
+}
+
+// validateMeta checks the values of well-known front-matter keys
+func validateMeta(meta map[string]string) error {
+	if priority, ok := meta[MetaPriority]; ok {
+		meta[MetaPriority] = strings.ToLower(priority)
+		if PriorityRank(meta[MetaPriority]) < 0 {
+			return fmt.Errorf("invalid priority '%s' (%s)", priority, strings.Join(Priorities, "|"))
+		}
+	}
+	return nil
+}
+
+// PriorityRank returns the position of the priority in Priorities (normal when empty, -1 when unknown)
+func PriorityRank(priority string) int {
+	if priority == "" {
+		priority = "normal"
+	}
+	for i, p := range Priorities {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+// FormatMetaHeader renders metadata as "[priority: high] [task: AUTH-12] ..." (priority and task first)
+func FormatMetaHeader(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		if key != MetaPriority && key != MetaTask {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	keys = append([]string{MetaPriority, MetaTask}, keys...)
+
+	var parts []string
+	for _, key := range keys {
+		if value := meta[key]; value != "" {
+			parts = append(parts, fmt.Sprintf("[%s: %s]", key, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ComposeMessage prefixes the body with the metadata header
+func ComposeMessage(meta map[string]string, body string) string {
+	header := FormatMetaHeader(meta)
+	if header == "" {
+		return body
+	}
+	return header + "\n" + body
+}
+
+// StoreLargeMessage writes a message that exceeds maxSize bytes to the session's shared directory
+// and returns the pointer message to deliver instead. Smaller messages are returned unchanged
+// with an empty path; maxSize <= 0 disables the limit.
+func StoreLargeMessage(sessionName, name, message string, meta map[string]string, maxSize int) (string, string, error) {
+	if maxSize <= 0 || len(message) <= maxSize {
+		return message, "", nil
+	}
+
+	dir := filepath.Join(SessionDir(sessionName), SharedDirName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", "", fmt.Errorf("failed to create shared directory: %w", err)
+	}
+
+	path := filepath.Join(dir, name+".md")
+	if err := os.WriteFile(path, []byte(message), 0600); err != nil {
+		return "", "", fmt.Errorf("failed to store message: %w", err)
+	}
+
+	pointer := fmt.Sprintf("The full message (%d bytes) is too long to paste and was saved to %s. Please read that file and follow its instructions.", len(message), path)
+	return ComposeMessage(meta, pointer), path, nil
+}
+
+// PreparedMessage is a message ready for delivery
+type PreparedMessage struct {
+	Text string            // text pasted into the agent's pane
+	Meta map[string]string // front-matter metadata
+	File string            // shared file holding the full message, if it was too large
+}
+
+// PrepareMessage parses the front-matter of a raw message, adds the metadata header
+// and moves oversized messages into the session's shared directory
+func PrepareMessage(sessionName, from, raw string, maxInline int) (*PreparedMessage, error) {
+	meta, body, err := ParseFrontMatter(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	text, file, err := StoreLargeMessage(sessionName, newMessageID(from), ComposeMessage(meta, body), meta, maxInline)
+	if err != nil {
+		return nil, err
+	}
+	return &PreparedMessage{Text: text, Meta: meta, File: file}, nil
+}
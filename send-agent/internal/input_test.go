@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMessageInput(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spec.md")
+	require.NoError(t, os.WriteFile(file, []byte("from file\nline 2\n"), 0600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		file     string
+		stdin    string
+		expected string
+		wantErr  bool
+	}{
+		{"Argument", []string{"hello"}, "", "", "hello", false},
+		{"Stdin argument", []string{"-"}, "", "from stdin", "from stdin", false},
+		{"File flag", nil, file, "", "from file\nline 2\n", false},
+		{"File flag with stdin", nil, "-", "piped", "piped", false},
+		{"File and argument", []string{"hello"}, file, "", "", true},
+		{"Missing file", nil, filepath.Join(t.TempDir(), "missing.md"), "", "", true},
+		{"No message", nil, "", "", "", true},
+		{"Empty stdin", []string{"-"}, "", " \n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := ReadMessageInput(tt.args, tt.file, strings.NewReader(tt.stdin))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	t.Run("With metadata", func(t *testing.T) {
+		meta, body, err := ParseFrontMatter("---\nPriority: High\ntask_id: \"AUTH-12\"\n# comment\nowner: manager\n---\n\nImplement login\n")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"priority": "high", "task": "AUTH-12", "owner": "manager"}, meta)
+		assert.Equal(t, "Implement login\n", body)
+	})
+
+	t.Run("Without metadata", func(t *testing.T) {
+		meta, body, err := ParseFrontMatter("Implement login\n---\n")
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+		assert.Equal(t, "Implement login\n---\n", body)
+	})
+
+	t.Run("CRLF", func(t *testing.T) {
+		meta, body, err := ParseFrontMatter("---\r\ntask: T-1\r\n---\r\nbody")
+		require.NoError(t, err)
+		assert.Equal(t, "T-1", meta[MetaTask])
+		assert.Equal(t, "body", body)
+	})
+
+	errorCases := map[string]string{
+		"Not closed":       "---\npriority: high\nbody",
+		"Invalid line":     "---\njust text\n---\nbody",
+		"Unknown priority": "---\npriority: asap\n---\nbody",
+	}
+	for name, input := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := ParseFrontMatter(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestComposeMessage(t *testing.T) {
+	assert.Equal(t, "body", ComposeMessage(nil, "body"))
+	assert.Equal(t, "[priority: high] [task: T-1] [area: ui] [owner: po]\nbody",
+		ComposeMessage(map[string]string{"owner": "po", "task": "T-1", "priority": "high", "area": "ui"}, "body"))
+}
+
+func TestPriorityRank(t *testing.T) {
+	assert.Equal(t, PriorityRank("normal"), PriorityRank(""))
+	assert.Greater(t, PriorityRank("urgent"), PriorityRank("high"))
+	assert.Greater(t, PriorityRank("high"), PriorityRank("low"))
+	assert.Equal(t, -1, PriorityRank("asap"))
+}
+
+func TestPrepareMessage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "input-test-session-12345"
+
+	t.Run("Small message is sent inline", func(t *testing.T) {
+		prepared, err := PrepareMessage(session, "manager", "---\ntask: T-1\n---\nshort", 100)
+		require.NoError(t, err)
+		assert.Equal(t, "[task: T-1]\nshort", prepared.Text)
+		assert.Empty(t, prepared.File)
+	})
+
+	t.Run("Large message is stored in the shared directory", func(t *testing.T) {
+		body := strings.Repeat("x", 200)
+		prepared, err := PrepareMessage(session, "manager", "---\npriority: urgent\n---\n"+body, 100)
+		require.NoError(t, err)
+
+		require.NotEmpty(t, prepared.File)
+		assert.Equal(t, SharedDirName, filepath.Base(filepath.Dir(prepared.File)))
+		assert.True(t, strings.HasPrefix(prepared.Text, "[priority: urgent]\n"))
+		assert.Contains(t, prepared.Text, prepared.File)
+		assert.Less(t, len(prepared.Text), 300)
+
+		stored, err := os.ReadFile(prepared.File)
+		require.NoError(t, err)
+		assert.Equal(t, "[priority: urgent]\n"+body, string(stored))
+	})
+
+	t.Run("No limit", func(t *testing.T) {
+		prepared, err := PrepareMessage(session, "manager", strings.Repeat("y", 200), 0)
+		require.NoError(t, err)
+		assert.Empty(t, prepared.File)
+	})
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// Raw keys and Claude slash commands
+//
+// "send-agent keys" passes tmux key names (Escape, C-c, Enter, Up, ...) to an
+// agent's pane unchanged. "send-agent slash" types a Claude slash command such
+// as /compact or /clear and submits it. Neither adds a sender header, and
+// unlike messages they skip the Ctrl+C / Ctrl+U prompt clearing, so they can
+// be used to steer an agent without disturbing its input.
+
+// InterruptKey is the key that stops Claude's current turn
+const InterruptKey = "Escape"
+
+// ErrAgentBusy is returned when a slash command is sent to an agent that is working
+var ErrAgentBusy = errors.New("agent is busy")
+
+// SendKeys sends tmux key names to the target pane of the socket's server; with literal the
+// arguments are typed as text
+func SendKeys(socket, target string, keys []string, literal bool) error {
+	if len(keys) == 0 {
+		return errors.New("no keys given")
+	}
+
+	args := []string{"send-keys", "-t", target}
+	if literal {
+		args = append(args, "-l", strings.Join(keys, " "))
+	} else {
+		args = append(args, keys...)
+	}
+
+	cmd := tmuxsocket.CommandOn(socket, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to send keys: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SendRawKeys sends keys to the agent's pane after checking the routing policy
+func (ms *MessageSender) SendRawKeys(keys []string, literal bool) error {
+	if err := ms.checkRoute(); err != nil {
+		return err
+	}
+
+	target, err := AgentTarget(ms.SessionName, ms.Agent)
+	if err != nil {
+		return err
+	}
+	if err := SendKeys(ms.socket(), target, keys, literal); err != nil {
+		return err
+	}
+
+	ms.printf("⌨️ Sent %s to %s (%s)\n", strings.Join(keys, " "), ms.Agent, target)
+	return nil
+}
+
+// ValidateSlashCommand checks that the command is a single-line slash command such as "/compact"
+func ValidateSlashCommand(command string) error {
+	if !strings.HasPrefix(command, "/") || len(strings.TrimSpace(command)) < 2 || strings.HasPrefix(command, "/ ") {
+		return fmt.Errorf("invalid slash command '%s' (e.g. /compact, /clear, /cost)", command)
+	}
+	if strings.ContainsAny(command, "\r\n") {
+		return fmt.Errorf("slash command must be a single line")
+	}
+	return nil
+}
+
+// SendSlashCommand types the slash command in ms.Message into the agent's prompt and submits it.
+// The agent must be idle unless ms.Interrupt is set, in which case its turn is stopped first.
+func (ms *MessageSender) SendSlashCommand() error {
+	command := strings.TrimSpace(ms.Message)
+	if err := ValidateSlashCommand(command); err != nil {
+		return err
+	}
+	ms.Message = command
+
+	if err := ms.checkRoute(); err != nil {
+		ms.printf("🚫 %v\n", err)
+		ms.recordHistory(ResultRejected, err)
+		return err
+	}
+
+	target, err := AgentTarget(ms.SessionName, ms.Agent)
+	if err != nil {
+		return err
+	}
+	if ms.ID == "" {
+		ms.ID = ms.allocateID()
+	}
+
+	if snapshot, err := agentstate.InspectOn(ms.socket(), target); err == nil {
+		switch snapshot.State {
+		case agentstate.StateIdle:
+		case agentstate.StateExited:
+			return fmt.Errorf("agent '%s' is not running Claude CLI (pane %s runs %s)", ms.Agent, snapshot.PaneID, snapshot.Command)
+		default:
+			if !ms.Interrupt {
+				return fmt.Errorf("%w: %s is %s; use --interrupt to stop its current work first", ErrAgentBusy, ms.Agent, snapshot.State)
+			}
+		}
+	}
+
+	if ms.Interrupt {
+		ms.printf("⛔ Interrupting current work (%s)...\n", InterruptKey)
+		if err := SendControlKey(ms.socket(), target, InterruptKey); err != nil {
+			return fmt.Errorf("interrupt failed: %v", err)
+		}
+	}
+
+	err = ms.typeSlashCommand(target, command)
+	result := ResultDelivered
+	if err != nil {
+		result = ResultFailed
+	}
+	ms.recordHistory(result, err)
+	if err != nil {
+		return err
+	}
+
+	ms.printf("✅ %s sent to %s (%s)\n", command, ms.Agent, target)
+	return nil
+}
+
+// typeSlashCommand types the command so Claude's command menu recognizes it, then submits it
+func (ms *MessageSender) typeSlashCommand(target, command string) error {
+	before, err := TmuxCapturePane(ms.socket(), target)
+	if err != nil {
+		return fmt.Errorf("failed to read pane %s: %v", target, err)
+	}
+
+	ms.printf("⌨️ Typing %s...\n", command)
+	if err := SendKeys(ms.socket(), target, []string{command}, true); err != nil {
+		return err
+	}
+	if _, changed := waitForPaneSettled(ms.socket(), target, before, true, StableWindow, PasteSettleTimeout); !changed {
+		return fmt.Errorf("slash command did not appear in pane %s", target)
+	}
+
+	if err := TmuxSendKeys(ms.socket(), target, "Enter"); err != nil {
+		return fmt.Errorf("Enter sending failed: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSlashCommand(t *testing.T) {
+	for _, command := range []string{"/compact", "/clear", "/cost", "/compact keep the API notes"} {
+		assert.NoError(t, ValidateSlashCommand(command), command)
+	}
+	for _, command := range []string{"", "/", "compact", "/ compact", "/compact\nrm -rf", " /clear"} {
+		assert.Error(t, ValidateSlashCommand(command), command)
+	}
+}
+
+func TestSendKeys_NoKeys(t *testing.T) {
+	assert.Error(t, SendKeys("", "session:1.1", nil, false))
+}
+
+func TestSendSlashCommand_RejectedByRoutingPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "slash-test-session-12345"
+
+	sender := &MessageSender{
+		SessionName: session,
+		From:        "dev2",
+		Agent:       "dev3",
+		Message:     "/clear",
+		Policy:      topology.RoutingPolicy{topology.RoleDev: {topology.RoleManager}},
+		Out:         io.Discard,
+	}
+	err := sender.SendSlashCommand()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRouteDenied))
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, ResultRejected, records[0].Result)
+	assert.Equal(t, "/clear", records[0].Message)
+
+	// Raw keys follow the same policy
+	assert.True(t, errors.Is(sender.SendRawKeys([]string{"Escape"}, false), ErrRouteDenied))
+}
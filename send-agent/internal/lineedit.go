@@ -0,0 +1,396 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Minimal line editor for the interactive shell
+//
+// On a terminal the editor switches to character mode (via stty) and supports
+// cursor movement, history (↑/↓), tab completion and Emacs-style shortcuts.
+// Output written through the editor while a line is being edited (the live
+// message feed) is printed above the prompt and the input line is redrawn.
+// Without a terminal (pipes, Windows) it falls back to plain line reading.
+
+// ErrInterrupted is returned by ReadLine when the line is discarded with Ctrl+C
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns the start of the word being completed and the candidates for it
+type Completer func(line []rune, pos int) (start int, candidates []string)
+
+// LineEditor reads lines with editing support
+type LineEditor struct {
+	Complete Completer
+
+	in      *bufio.Reader
+	out     io.Writer
+	raw     bool
+	restore func()
+
+	mu      sync.Mutex
+	prompt  string
+	buf     []rune
+	pos     int
+	editing bool
+
+	history []string
+	histPos int
+	pending []rune // line being edited while browsing the history
+}
+
+// NewLineEditor returns an editor reading from in and writing to out
+func NewLineEditor(in io.Reader, out io.Writer) *LineEditor {
+	return &LineEditor{in: bufio.NewReader(in), out: out}
+}
+
+// EnableRawMode switches the terminal to character mode; without a terminal the editor reads plain lines
+func (e *LineEditor) EnableRawMode(terminal *os.File) error {
+	restore, err := enableRawMode(terminal)
+	if err != nil {
+		return err
+	}
+	e.raw = true
+	e.restore = restore
+	return nil
+}
+
+// Close restores the terminal mode
+func (e *LineEditor) Close() {
+	if e.restore != nil {
+		e.restore()
+		e.restore = nil
+	}
+	e.raw = false
+}
+
+// SetHistory replaces the input history (oldest first)
+func (e *LineEditor) SetHistory(history []string) {
+	e.history = append([]string(nil), history...)
+}
+
+// History returns the input history (oldest first)
+func (e *LineEditor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// Write prints output above the line being edited (safe to call from other goroutines)
+func (e *LineEditor) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.raw || !e.editing {
+		return e.out.Write(p)
+	}
+
+	fmt.Fprint(e.out, "\r\x1b[K")
+	n, err := e.out.Write(p)
+	if len(p) > 0 && p[len(p)-1] != '\n' {
+		fmt.Fprint(e.out, "\n")
+	}
+	e.redraw()
+	return n, err
+}
+
+// Printf formats output through Write
+func (e *LineEditor) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(e, format, args...)
+}
+
+// ReadLine shows the prompt and returns the entered line (io.EOF on Ctrl+D or end of input)
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	if !e.raw {
+		return e.readPlainLine(prompt)
+	}
+
+	e.mu.Lock()
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.histPos = len(e.history)
+	e.editing = true
+	e.redraw()
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		e.editing = false
+		e.mu.Unlock()
+	}()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		line, done, err := e.handleKey(r)
+		if done || err != nil {
+			return line, err
+		}
+	}
+}
+
+// readPlainLine reads a line without editing support
+func (e *LineEditor) readPlainLine(prompt string) (string, error) {
+	e.mu.Lock()
+	fmt.Fprint(e.out, prompt)
+	e.mu.Unlock()
+
+	line, err := e.in.ReadString('\n')
+	if err != nil && (line == "" || err != io.EOF) {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	e.addHistory(line)
+	return line, nil
+}
+
+// handleKey applies one key; it returns the line and true when the line is complete
+func (e *LineEditor) handleKey(r rune) (string, bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch r {
+	case '\r', '\n':
+		line := string(e.buf)
+		fmt.Fprint(e.out, "\n")
+		e.editing = false
+		e.addHistory(line)
+		return line, true, nil
+	case 3: // Ctrl+C
+		fmt.Fprint(e.out, "^C\n")
+		e.editing = false
+		return "", true, ErrInterrupted
+	case 4: // Ctrl+D
+		if len(e.buf) == 0 {
+			fmt.Fprint(e.out, "\n")
+			e.editing = false
+			return "", true, io.EOF
+		}
+		e.deleteAt(e.pos)
+	case 127, 8: // Backspace
+		if e.pos > 0 {
+			e.pos--
+			e.deleteAt(e.pos)
+		}
+	case 1: // Ctrl+A
+		e.pos = 0
+	case 5: // Ctrl+E
+		e.pos = len(e.buf)
+	case 2: // Ctrl+B
+		e.moveLeft()
+	case 6: // Ctrl+F
+		e.moveRight()
+	case 11: // Ctrl+K
+		e.buf = e.buf[:e.pos]
+	case 21: // Ctrl+U
+		e.buf = append([]rune(nil), e.buf[e.pos:]...)
+		e.pos = 0
+	case 23: // Ctrl+W
+		e.deleteWordBefore()
+	case 12: // Ctrl+L
+		fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+	case 16: // Ctrl+P
+		e.historyPrev()
+	case 14: // Ctrl+N
+		e.historyNext()
+	case '\t':
+		e.complete()
+	case 27: // Escape sequence
+		e.handleEscape()
+	default:
+		if unicode.IsPrint(r) {
+			e.insert(r)
+		}
+	}
+
+	e.redraw()
+	return "", false, nil
+}
+
+// handleEscape handles arrow, home, end and delete keys (CSI and SS3 sequences)
+func (e *LineEditor) handleEscape() {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return
+	}
+
+	r, _, err = e.in.ReadRune()
+	if err != nil {
+		return
+	}
+	switch r {
+	case 'A':
+		e.historyPrev()
+	case 'B':
+		e.historyNext()
+	case 'C':
+		e.moveRight()
+	case 'D':
+		e.moveLeft()
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	case '3':
+		if next, _, err := e.in.ReadRune(); err == nil && next == '~' {
+			e.deleteAt(e.pos)
+		}
+	}
+}
+
+func (e *LineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *LineEditor) deleteAt(pos int) {
+	if pos < len(e.buf) {
+		e.buf = append(e.buf[:pos], e.buf[pos+1:]...)
+	}
+}
+
+func (e *LineEditor) deleteWordBefore() {
+	start := e.pos
+	for start > 0 && e.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *LineEditor) moveLeft() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *LineEditor) moveRight() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func (e *LineEditor) historyPrev() {
+	if e.histPos == 0 {
+		return
+	}
+	if e.histPos == len(e.history) {
+		e.pending = append([]rune(nil), e.buf...)
+	}
+	e.histPos--
+	e.setBuffer([]rune(e.history[e.histPos]))
+}
+
+func (e *LineEditor) historyNext() {
+	if e.histPos >= len(e.history) {
+		return
+	}
+	e.histPos++
+	if e.histPos == len(e.history) {
+		e.setBuffer(e.pending)
+		return
+	}
+	e.setBuffer([]rune(e.history[e.histPos]))
+}
+
+func (e *LineEditor) setBuffer(line []rune) {
+	e.buf = append(e.buf[:0], line...)
+	e.pos = len(e.buf)
+}
+
+// addHistory appends a line to the history, skipping empty lines and direct repetitions
+func (e *LineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// complete completes the word before the cursor; with several candidates the common
+// prefix is inserted and the candidates are listed
+func (e *LineEditor) complete() {
+	if e.Complete == nil {
+		return
+	}
+
+	start, candidates := e.Complete(e.buf, e.pos)
+	if len(candidates) == 0 || start < 0 || start > e.pos {
+		return
+	}
+
+	word := string(e.buf[start:e.pos])
+	replacement := candidates[0]
+	if len(candidates) > 1 {
+		replacement = commonPrefix(candidates)
+		if replacement == word {
+			fmt.Fprintf(e.out, "\r\x1b[K%s\n", strings.Join(candidates, "  "))
+			return
+		}
+	} else if !strings.HasSuffix(replacement, ",") {
+		replacement += " "
+	}
+
+	rest := append([]rune(nil), e.buf[e.pos:]...)
+	e.buf = append(append(e.buf[:start], []rune(replacement)...), rest...)
+	e.pos = start + len([]rune(replacement))
+}
+
+// redraw renders the prompt and the line and places the cursor (caller holds mu)
+func (e *LineEditor) redraw() {
+	fmt.Fprintf(e.out, "\r\x1b[K%s%s", e.prompt, string(e.buf))
+	if back := displayWidth(e.buf[e.pos:]); back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func commonPrefix(values []string) string {
+	prefix := []rune(values[0])
+	for _, value := range values[1:] {
+		runes := []rune(value)
+		n := 0
+		for n < len(prefix) && n < len(runes) && prefix[n] == runes[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
+
+// displayWidth returns the terminal columns used by the runes (wide East Asian characters and emoji use two)
+func displayWidth(runes []rune) int {
+	width := 0
+	for _, r := range runes {
+		switch {
+		case r >= 0x1100 && r <= 0x115F,
+			r >= 0x2E80 && r <= 0xA4CF,
+			r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF,
+			r >= 0xFE30 && r <= 0xFE4F,
+			r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6,
+			r >= 0x1F300 && r <= 0x1F64F,
+			r >= 0x1F900 && r <= 0x1F9FF,
+			r >= 0x20000 && r <= 0x3FFFD:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEditor returns an editor in character mode reading the given keys
+func newTestEditor(keys string) (*LineEditor, *bytes.Buffer) {
+	var out bytes.Buffer
+	editor := NewLineEditor(strings.NewReader(keys), &out)
+	editor.raw = true
+	return editor, &out
+}
+
+func TestLineEditor_Editing(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{"plain", "hello\r", "hello"},
+		{"backspace", "helx\x7flo\r", "hello"},
+		{"cursor movement", "world\x01hello \r", "hello world"},
+		{"arrow keys", "hllo\x1b[D\x1b[D\x1b[De\r", "hello"},
+		{"kill line", "hello world\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0b\r", "hello"},
+		{"delete word", "hello world\x17there\r", "hello there"},
+		{"multibyte", "こんにちは\x7f\r", "こんにち"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor, _ := newTestEditor(tt.keys)
+			line, err := editor.ReadLine("> ")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, line)
+		})
+	}
+}
+
+func TestLineEditor_History(t *testing.T) {
+	editor, _ := newTestEditor("first\rsecond\r\x1b[A\x1b[A\r\x1b[A\x1b[B\r")
+	editor.SetHistory([]string{"old"})
+
+	var lines []string
+	for i := 0; i < 4; i++ {
+		line, err := editor.ReadLine("> ")
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, []string{"first", "second", "first", ""}, lines)
+	assert.Equal(t, []string{"old", "first", "second", "first"}, editor.History())
+}
+
+func TestLineEditor_Completion(t *testing.T) {
+	editor, out := newTestEditor("@ma\thi\r@de\t\t\r")
+	editor.Complete = func(line []rune, pos int) (int, []string) {
+		start := strings.LastIndex(string(line[:pos]), "@") + 1
+		var candidates []string
+		for _, name := range []string{"manager", "dev1", "dev2"} {
+			if strings.HasPrefix(name, string(line[start:pos])) {
+				candidates = append(candidates, name)
+			}
+		}
+		return start, candidates
+	}
+
+	line, err := editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "@manager hi", line)
+
+	// Several candidates: the common prefix is inserted, a second tab lists the candidates
+	line, err = editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "@dev", line)
+	assert.Contains(t, out.String(), "dev1  dev2")
+}
+
+func TestLineEditor_ControlKeys(t *testing.T) {
+	editor, _ := newTestEditor("abc\x03\x04")
+
+	_, err := editor.ReadLine("> ")
+	assert.True(t, errors.Is(err, ErrInterrupted))
+
+	_, err = editor.ReadLine("> ")
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestLineEditor_PlainMode(t *testing.T) {
+	var out bytes.Buffer
+	editor := NewLineEditor(strings.NewReader("@dev1 hello\r\nlast"), &out)
+
+	line, err := editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "@dev1 hello", line)
+
+	line, err = editor.ReadLine("> ")
+	require.NoError(t, err)
+	assert.Equal(t, "last", line)
+
+	_, err = editor.ReadLine("> ")
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestDisplayWidth(t *testing.T) {
+	assert.Equal(t, 5, displayWidth([]rune("hello")))
+	assert.Equal(t, 4, displayWidth([]rune("日本")))
+}
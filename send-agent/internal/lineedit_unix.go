@@ -0,0 +1,29 @@
+//go:build !windows
+
+package internal
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// enableRawMode switches the terminal to character mode without echo and signal keys,
+// returning a function restoring the previous mode. It fails when the file is not a terminal.
+func enableRawMode(terminal *os.File) (func(), error) {
+	state, err := stty(terminal, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(terminal, "-icanon", "-echo", "-isig", "-ixon", "min", "1", "time", "0"); err != nil {
+		return nil, err
+	}
+	return func() { _, _ = stty(terminal, strings.TrimSpace(state)) }, nil
+}
+
+func stty(terminal *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...) // #nosec G204
+	cmd.Stdin = terminal
+	output, err := cmd.Output()
+	return string(output), err
+}
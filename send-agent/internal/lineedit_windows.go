@@ -0,0 +1,13 @@
+//go:build windows
+
+package internal
+
+import (
+	"errors"
+	"os"
+)
+
+// enableRawMode is not supported on Windows; the editor reads plain lines
+func enableRawMode(terminal *os.File) (func(), error) {
+	return nil, errors.New("character mode is not supported on Windows")
+}
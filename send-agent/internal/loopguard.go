@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Loop and message-storm detection
+//
+// Before an agent sends a message, the recent part of the session's message
+// log is checked for conversational loops: two agents exchanging many messages
+// in a short window (ping-pong), the same content sent repeatedly, or a single
+// agent flooding others. Such sends are blocked, logged, and the PO (or the
+// operator, via the tmux status line) is notified once per alert window.
+
+// ErrLoopDetected is returned when a send is blocked by loop detection
+var ErrLoopDetected = errors.New("message loop detected")
+
+// SenderSystem is the sender of notifications generated by send-agent itself
+const SenderSystem = "send-agent"
+
+// Loop detection thresholds
+const (
+	// LoopWindow is the rolling window inspected for loops
+	LoopWindow = 10 * time.Minute
+	// LoopPairLimit is the number of messages two agents may exchange within the window
+	LoopPairLimit = 12
+	// LoopRepeatLimit is how often the same content may be sent to the same agent within the window
+	LoopRepeatLimit = 3
+	// LoopStormLimit is the number of messages one agent may send within the window
+	LoopStormLimit = 30
+)
+
+// Loop alert kinds
+const (
+	LoopKindPingPong = "ping-pong"
+	LoopKindRepeat   = "repeat"
+	LoopKindStorm    = "storm"
+)
+
+// metaAlert is the metadata key marking loop notifications in the message log
+const metaAlert = "alert"
+
+// LoopAlert describes a detected loop
+type LoopAlert struct {
+	Kind   string
+	Agents []string
+	Count  int
+}
+
+// Key identifies the alert for notification de-duplication
+func (a *LoopAlert) Key() string {
+	return a.Kind + ":" + strings.Join(a.Agents, ",")
+}
+
+// Describe returns a human readable explanation of the alert
+func (a *LoopAlert) Describe() string {
+	window := LoopWindow.String()
+	switch a.Kind {
+	case LoopKindPingPong:
+		return fmt.Sprintf("%s and %s exchanged %d messages within %s", a.Agents[0], a.Agents[1], a.Count, window)
+	case LoopKindRepeat:
+		return fmt.Sprintf("%s sent the same message to %s %d times within %s", a.Agents[0], a.Agents[1], a.Count, window)
+	default:
+		return fmt.Sprintf("%s sent %d messages within %s", a.Agents[0], a.Count, window)
+	}
+}
+
+// DetectLoop checks whether sending message from → to would continue a loop, given the log records.
+// Agents are compared by their full address, so agents of other sessions ("session:agent") are covered.
+// A message logged several times (queued, then delivered) counts once, with its latest result.
+// Rejected, blocked and failed records as well as records older than LoopWindow are ignored.
+func DetectLoop(records []HistoryRecord, from, to, message string, now time.Time) *LoopAlert {
+	since := now.Add(-LoopWindow)
+	normalized := normalizeContent(message)
+
+	latest := make(map[string]int)
+	for i, r := range records {
+		if r.ID != "" {
+			latest[messageKey(r)] = i
+		}
+	}
+
+	var pair, forward, backward, repeat, sent int
+	for i, r := range records {
+		if r.Time.Before(since) || !countsAsSent(r.Result) {
+			continue
+		}
+		if r.ID != "" && latest[messageKey(r)] != i {
+			continue
+		}
+		if r.From == from {
+			sent++
+		}
+		switch {
+		case r.From == from && r.To == to:
+			pair++
+			forward++
+			if normalizeContent(r.Message) == normalized {
+				repeat++
+			}
+		case r.From == to && r.To == from:
+			pair++
+			backward++
+		}
+	}
+
+	switch {
+	case repeat >= LoopRepeatLimit:
+		return &LoopAlert{Kind: LoopKindRepeat, Agents: []string{from, to}, Count: repeat + 1}
+	case pair >= LoopPairLimit && forward > 0 && backward > 0:
+		agents := []string{from, to}
+		sort.Strings(agents)
+		return &LoopAlert{Kind: LoopKindPingPong, Agents: agents, Count: pair + 1}
+	case sent >= LoopStormLimit:
+		return &LoopAlert{Kind: LoopKindStorm, Agents: []string{from}, Count: sent + 1}
+	}
+	return nil
+}
+
+// countsAsSent reports whether a logged message reached (or is waiting for) its recipient
+func countsAsSent(result string) bool {
+	switch result {
+	case ResultRejected, ResultBlocked, ResultFailed:
+		return false
+	}
+	return true
+}
+
+// messageKey identifies the records of one message in the log (IDs are numbered per session, and
+// messages of other sessions are logged with a qualified sender or recipient)
+func messageKey(r HistoryRecord) string {
+	return strings.Join([]string{r.Session, r.ID, r.From, r.To}, "\x00")
+}
+
+// normalizeContent makes repeated messages comparable (case and whitespace insensitive)
+func normalizeContent(message string) string {
+	return strings.ToLower(strings.Join(strings.Fields(message), " "))
+}
+
+// checkLoop blocks messages between agents that continue a loop and notifies the PO / operator
+func (ms *MessageSender) checkLoop() error {
+	// Agents of other sessions are checked as well; the log holds both directions of their exchanges
+	if _, agent := topology.SplitAddress(ms.sender()); !IsValidAgent(agent) {
+		return nil
+	}
+
+	now := time.Now()
+	records, err := LoadHistory(ms.SessionName, HistoryFilter{Since: now.Add(-LoopWindow)})
+	if err != nil {
+		// The log is advisory; never block delivery because it cannot be read
+		return nil
+	}
+
+	alert := DetectLoop(records, ms.sender(), ms.Agent, ms.Message, now)
+	if alert == nil {
+		return nil
+	}
+
+	if !alertNotified(records, alert) {
+		ms.notifyLoop(alert)
+	}
+	return fmt.Errorf("%w: %s; further messages are blocked (use --force to send anyway)", ErrLoopDetected, alert.Describe())
+}
+
+// alertNotified reports whether the alert was already sent to the PO within the window
+func alertNotified(records []HistoryRecord, alert *LoopAlert) bool {
+	for _, r := range records {
+		if r.From == SenderSystem && r.Meta[metaAlert] == alert.Key() {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyLoop tells the PO (unless it is part of the loop) and the operator about the alert
+func (ms *MessageSender) notifyLoop(alert *LoopAlert) {
+	text := fmt.Sprintf("⚠️ Message loop detected (%s): %s. send-agent is blocking further messages; please check whether this exchange is still needed and intervene.", alert.Kind, alert.Describe())
+
+	// Operator: tmux status line of clients attached to the session
+	_ = sessionCommand(ms.SessionName, "display-message", "-t", ms.SessionName, "send-agent: "+text).Run()
+
+	for _, agent := range alert.Agents {
+		if agent == topology.AgentPO {
+			return
+		}
+	}
+
+	notification := &MessageSender{
+		From:        SenderSystem,
+		SessionName: ms.SessionName,
+		Socket:      ms.Socket,
+		Agent:       topology.AgentPO,
+		Message:     text,
+		Meta:        map[string]string{metaAlert: alert.Key()},
+		NoVerify:    true,
+		Out:         ms.Out,
+	}
+	if err := notification.Send(); err != nil {
+		ms.printf("⚠️ Failed to notify %s about the loop: %v\n", topology.AgentPO, err)
+	}
+}
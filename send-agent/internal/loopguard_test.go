@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exchange(now time.Time, n int, a, b string) []HistoryRecord {
+	var records []HistoryRecord
+	for i := 0; i < n; i++ {
+		from, to := a, b
+		if i%2 == 1 {
+			from, to = b, a
+		}
+		records = append(records, HistoryRecord{
+			Time:    now.Add(-time.Duration(n-i) * time.Second),
+			From:    from,
+			To:      to,
+			Result:  ResultDelivered,
+			Message: fmt.Sprintf("ack %d", i),
+		})
+	}
+	return records
+}
+
+func TestDetectLoop_PingPong(t *testing.T) {
+	now := time.Now()
+
+	assert.Nil(t, DetectLoop(exchange(now, LoopPairLimit-1, "manager", "dev1"), "manager", "dev1", "ok", now))
+
+	alert := DetectLoop(exchange(now, LoopPairLimit, "manager", "dev1"), "dev1", "manager", "ok", now)
+	require.NotNil(t, alert)
+	assert.Equal(t, LoopKindPingPong, alert.Kind)
+	assert.Equal(t, []string{"dev1", "manager"}, alert.Agents)
+	assert.Equal(t, LoopPairLimit+1, alert.Count)
+	assert.Contains(t, alert.Describe(), "dev1 and manager exchanged")
+
+	// Other pairs are not affected
+	assert.Nil(t, DetectLoop(exchange(now, LoopPairLimit, "manager", "dev1"), "manager", "dev2", "ok", now))
+}
+
+func TestDetectLoop_OneWayIsNotPingPong(t *testing.T) {
+	now := time.Now()
+	var records []HistoryRecord
+	for i := 0; i < LoopPairLimit; i++ {
+		records = append(records, HistoryRecord{Time: now, From: "manager", To: "dev1", Result: ResultDelivered, Message: fmt.Sprintf("task %d", i)})
+	}
+	assert.Nil(t, DetectLoop(records, "manager", "dev1", "task x", now))
+}
+
+func TestDetectLoop_Repeat(t *testing.T) {
+	now := time.Now()
+	var records []HistoryRecord
+	for i := 0; i < LoopRepeatLimit; i++ {
+		records = append(records, HistoryRecord{Time: now, From: "dev2", To: "manager", Result: ResultQueued, Message: "Done!  Waiting for next task"})
+	}
+
+	alert := DetectLoop(records, "dev2", "manager", "done! waiting for next task", now)
+	require.NotNil(t, alert)
+	assert.Equal(t, LoopKindRepeat, alert.Kind)
+
+	assert.Nil(t, DetectLoop(records, "dev2", "manager", "PR #3 is ready", now))
+}
+
+func TestDetectLoop_Storm(t *testing.T) {
+	now := time.Now()
+	var records []HistoryRecord
+	for i := 0; i < LoopStormLimit; i++ {
+		records = append(records, HistoryRecord{Time: now, From: "manager", To: fmt.Sprintf("dev%d", i%4+1), Result: ResultDelivered, Message: fmt.Sprintf("task %d", i)})
+	}
+
+	alert := DetectLoop(records, "manager", "po", "status", now)
+	require.NotNil(t, alert)
+	assert.Equal(t, LoopKindStorm, alert.Kind)
+	assert.Equal(t, []string{"manager"}, alert.Agents)
+}
+
+func TestDetectLoop_IgnoresOldAndUndelivered(t *testing.T) {
+	now := time.Now()
+	records := exchange(now.Add(-LoopWindow), LoopPairLimit, "manager", "dev1")
+	assert.Nil(t, DetectLoop(records, "manager", "dev1", "ok", now))
+
+	records = exchange(now, LoopPairLimit, "manager", "dev1")
+	for i := range records {
+		records[i].Result = ResultBlocked
+	}
+	assert.Nil(t, DetectLoop(records, "manager", "dev1", "ok", now))
+}
+
+func TestSend_BlockedByLoopDetection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "loop-test-session-12345"
+
+	for _, record := range exchange(time.Now(), LoopPairLimit, "manager", "dev1") {
+		record.Session = session
+		require.NoError(t, AppendHistory(record))
+	}
+
+	sender := &MessageSender{SessionName: session, From: "dev1", Agent: "manager", Message: "ok", Out: io.Discard}
+	err := sender.Send()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLoopDetected))
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, ResultBlocked, records[len(records)-1].Result)
+
+	// The operator is exempt from loop detection
+	user := &MessageSender{SessionName: session, Agent: "manager", Message: "ok", Out: io.Discard}
+	assert.NoError(t, user.checkLoop())
+}
+
+func TestAlertNotified(t *testing.T) {
+	alert := &LoopAlert{Kind: LoopKindPingPong, Agents: []string{"dev1", "manager"}}
+	records := []HistoryRecord{
+		{From: "manager", To: "po", Meta: map[string]string{metaAlert: alert.Key()}},
+		{From: SenderSystem, To: "po", Meta: map[string]string{metaAlert: "storm:manager"}},
+	}
+	assert.False(t, alertNotified(records, alert))
+
+	records = append(records, HistoryRecord{From: SenderSystem, To: "po", Meta: map[string]string{metaAlert: alert.Key()}})
+	assert.True(t, alertNotified(records, alert))
+}
+
+func TestDetectLoop_CountsQueuedMessagesOnce(t *testing.T) {
+	now := time.Now()
+	var records []HistoryRecord
+	for i, r := range exchange(now, LoopPairLimit-1, "manager", "dev1") {
+		r.Session = "proj"
+		r.ID = fmt.Sprintf("%d", i+1)
+		queued := r
+		queued.Result = ResultQueued
+		records = append(records, queued, r)
+	}
+	assert.Nil(t, DetectLoop(records, "manager", "dev1", "ok", now))
+
+	// A queued message dropped after failed attempts does not count
+	var storm []HistoryRecord
+	for i := 0; i < LoopStormLimit; i++ {
+		r := HistoryRecord{Time: now, Session: "proj", ID: fmt.Sprintf("%d", i+1), From: "manager", To: fmt.Sprintf("dev%d", i%4+1), Result: ResultQueued, Message: fmt.Sprintf("task %d", i)}
+		storm = append(storm, r)
+		if i%2 == 0 {
+			r.Result = ResultFailed
+			storm = append(storm, r)
+		}
+	}
+	assert.Nil(t, DetectLoop(storm, "manager", "dev1", "task x", now))
+}
+
+func TestDetectLoop_OtherSessionAgents(t *testing.T) {
+	now := time.Now()
+	records := exchange(now, LoopPairLimit, "backend:manager", "dev1")
+
+	alert := DetectLoop(records, "backend:manager", "dev1", "ok", now)
+	require.NotNil(t, alert)
+	assert.Equal(t, LoopKindPingPong, alert.Kind)
+	assert.Equal(t, []string{"backend:manager", "dev1"}, alert.Agents)
+
+	// The same agent name of this session is a different agent
+	assert.Nil(t, DetectLoop(records, "manager", "dev1", "ok", now))
+}
+
+func TestSend_BlockedByLoopDetectionAcrossSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "loop-test-session-67890"
+
+	for _, record := range exchange(time.Now(), LoopPairLimit, "backend:manager", "dev1") {
+		record.Session = session
+		require.NoError(t, AppendHistory(record))
+	}
+
+	sender := &MessageSender{SessionName: session, From: "backend:manager", Agent: "dev1", Message: "ok", Out: io.Discard}
+	err := sender.checkLoop()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLoopDetected))
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// Session manifest lookup (written by start-agents at launch)
+
+// ErrMultipleSessions is returned when several team sessions are running and none was specified
+var ErrMultipleSessions = errors.New("multiple AI agent sessions found")
+
+// LoadSessionManifest loads the manifest of a session, preferring the path
+// recorded in the session's tmux options over the default location
+func LoadSessionManifest(sessionName string) (*manifest.Manifest, error) {
+	path := GetSessionOption(sessionName, manifest.OptionManifest)
+	if path == "" {
+		path = manifest.Path(manifest.ConfigDir(), sessionName)
+	}
+
+	m, err := manifest.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if m.SessionName != sessionName {
+		return nil, fmt.Errorf("manifest %s belongs to session '%s'", path, m.SessionName)
+	}
+	return m, nil
+}
+
+// IsTeamSession reports whether the session was launched by start-agents
+func IsTeamSession(sessionName string) bool {
+	if GetSessionOption(sessionName, manifest.OptionManifest) != "" {
+		return true
+	}
+	_, err := os.Stat(manifest.Path(manifest.ConfigDir(), sessionName))
+	return err == nil && HasSession(sessionName)
+}
+
+// ResolveAgentPane returns the stable pane ID of an agent in a team session.
+// The manifest is consulted first, then the @cca_agent pane options.
+func ResolveAgentPane(sessionName, agent string) (string, error) {
+	paneAgents, err := GetPaneAgents(sessionName)
+	if err != nil {
+		return "", err
+	}
+
+	if m, err := LoadSessionManifest(sessionName); err == nil {
+		if entry := m.Agent(agent); entry != nil {
+			if _, ok := paneAgents[entry.PaneID]; ok {
+				return entry.PaneID, nil
+			}
+		}
+	}
+
+	for paneID, name := range paneAgents {
+		if name == agent {
+			return paneID, nil
+		}
+	}
+
+	return "", fmt.Errorf("agent '%s' not found in session '%s'", agent, sessionName)
+}
+
+// findTeamSessions returns the names of running sessions launched by start-agents
+func findTeamSessions(sessions []Session) []string {
+	var teamSessions []string
+	for _, session := range sessions {
+		if IsTeamSession(session.Name) {
+			teamSessions = append(teamSessions, session.Name)
+		}
+	}
+	sort.Strings(teamSessions)
+	return teamSessions
+}
+
+// detectTeamSession picks the team session to use when none was specified.
+// The session of the calling pane wins; otherwise exactly one team session must be running.
+func detectTeamSession(sessions []Session) (string, bool, error) {
+	if current := CurrentSession(); current != "" && IsTeamSession(current) {
+		return current, true, nil
+	}
+
+	teamSessions := findTeamSessions(sessions)
+	switch len(teamSessions) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return teamSessions[0], true, nil
+	default:
+		return "", true, fmt.Errorf("%w (%s), please specify --session", ErrMultipleSessions, strings.Join(teamSessions, ", "))
+	}
+}
+
+// detectDedicatedSession finds a team running on its own tmux server
+func detectDedicatedSession() (string, bool, error) {
+	var teamSessions []string
+	for _, socket := range tmuxsocket.Dedicated() {
+		if sessionName, ok := tmuxsocket.SessionOf(socket); ok {
+			teamSessions = append(teamSessions, sessionName)
+		}
+	}
+
+	switch len(teamSessions) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return teamSessions[0], true, nil
+	default:
+		return "", true, fmt.Errorf("%w (%s), please specify --session", ErrMultipleSessions, strings.Join(teamSessions, ", "))
+	}
+}
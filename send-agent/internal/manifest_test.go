@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+)
+
+func writeTestManifest(t *testing.T, sessionName string) *manifest.Manifest {
+	t.Helper()
+	m := &manifest.Manifest{
+		SessionName: sessionName,
+		DevCount:    1,
+		Agents: []manifest.Agent{
+			{Name: "po", Role: "po", Title: "PO", PaneID: "%91"},
+			{Name: "manager", Role: "manager", Title: "Manager", PaneID: "%92"},
+			{Name: "dev1", Role: "dev", Title: "Dev1", PaneID: "%93"},
+		},
+	}
+	_, err := m.Save(manifest.DefaultConfigDir())
+	require.NoError(t, err)
+	return m
+}
+
+func TestLoadSessionManifest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sessionName := "manifest-test-session-12345"
+
+	t.Run("Missing manifest", func(t *testing.T) {
+		_, err := LoadSessionManifest(sessionName)
+		assert.Error(t, err)
+	})
+
+	t.Run("Default location", func(t *testing.T) {
+		expected := writeTestManifest(t, sessionName)
+
+		m, err := LoadSessionManifest(sessionName)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Agents, m.Agents)
+	})
+
+	t.Run("Manifest of another session", func(t *testing.T) {
+		path := manifest.Path(manifest.DefaultConfigDir(), "other-session-12345")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		data, err := os.ReadFile(manifest.Path(manifest.DefaultConfigDir(), sessionName))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0600))
+
+		_, err = LoadSessionManifest("other-session-12345")
+		assert.ErrorContains(t, err, "belongs to session")
+	})
+}
+
+func TestIsTeamSession_NotRunning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sessionName := "manifest-test-session-12345"
+	writeTestManifest(t, sessionName)
+
+	// A leftover manifest without a running session is not a team session
+	assert.False(t, IsTeamSession(sessionName))
+}
+
+func TestFindTeamSessions_NoManifests(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	sessions := []Session{{Name: "non-existing-session-12345"}, {Name: "another-session-12345"}}
+	assert.Empty(t, findTeamSessions(sessions))
+}
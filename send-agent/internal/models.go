@@ -1,20 +1,40 @@
 package internal
 
+import (
+	"io"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
 // Constant definitions
 const (
-	IntegratedSessionPaneCount = 6
-
-	ClearDelay           = 400
-	AdditionalClearDelay = 200
-	MessageDelay         = 300
-	ExecuteDelay         = 500
-
-	AgentPO      = "po"
-	AgentManager = "manager"
-	AgentDev1    = "dev1"
-	AgentDev2    = "dev2"
-	AgentDev3    = "dev3"
-	AgentDev4    = "dev4"
+	// IntegratedSessionPaneCount is the pane count of a default team (PO, Manager and 4 developers)
+	IntegratedSessionPaneCount = topology.LeaderCount + topology.DefaultDevCount
+
+	// Delivery timing (milliseconds)
+	PollInterval       = 50
+	StableWindow       = 200
+	ClearSettleTimeout = 1000
+	PasteSettleTimeout = 5000
+	ResetStableWindow  = 2000
+	ResetSettleTimeout = 30000
+
+	// Delivery acknowledgement (milliseconds for AckTimeout)
+	AckTimeout       = 3000
+	AckRetries       = 3
+	AckBackoffFactor = 2
+
+	// ExitDeliveryUnconfirmed is the exit code used when delivery could not be confirmed
+	ExitDeliveryUnconfirmed = 3
+	// ExitRouteDenied is the exit code used when the routing policy rejects a message
+	ExitRouteDenied = 4
+	// ExitLoopDetected is the exit code used when loop detection blocks a message
+	ExitLoopDetected = 5
+	// ExitAskTimeout is the exit code used when "send-agent ask" receives no reply in time
+	ExitAskTimeout = 6
+
+	AgentPO      = topology.AgentPO
+	AgentManager = topology.AgentManager
 )
 
 type Agent struct {
@@ -26,6 +46,8 @@ type Session struct {
 	Name  string
 	Type  string
 	Panes int
+	// Socket is the dedicated tmux server the session runs on ("" = the current server)
+	Socket string
 }
 
 type SessionManager struct {
@@ -33,22 +55,36 @@ type SessionManager struct {
 }
 
 type MessageSender struct {
+	ID           string // message ID recorded in the message log (generated when empty)
+	From         string // sending agent, or SenderUser
+	ReplyTo      string // agent replies should go to (From when empty)
+	InReplyTo    string // ID of the message this one answers
 	SessionName  string
 	Agent        string
 	Message      string
 	ResetContext bool
-}
+	NoVerify     bool
+	Interrupt    bool
+	Force        bool                   // send even if loop detection would block the message
+	Meta         map[string]string      // front-matter metadata (priority, task, ...)
+	File         string                 // shared file holding the full message when Message is a pointer
+	Policy       topology.RoutingPolicy // routing policy (loaded from the session manifest when nil)
+	Socket       string                 // tmux server of the session (found through SessionSocket when empty)
 
-var AvailableAgents = []Agent{
-	{AgentPO, "Product Owner (Product Manager)"},
-	{AgentManager, "Project Manager (Flexible team management)"},
-	{AgentDev1, "Execution Agent 1 (Flexible role assignment)"},
-	{AgentDev2, "Execution Agent 2 (Flexible role assignment)"},
-	{AgentDev3, "Execution Agent 3 (Flexible role assignment)"},
-	{AgentDev4, "Execution Agent 4 (Flexible role assignment)"},
+	// Out receives progress messages (os.Stdout when nil)
+	Out io.Writer
+	// Result is the outcome recorded in the message log by the last Send (ResultDelivered, ResultQueued, ...)
+	Result string
 }
 
-var ValidAgentNames = map[string]bool{
-	AgentPO: true, AgentManager: true, AgentDev1: true,
-	AgentDev2: true, AgentDev3: true, AgentDev4: true,
+// AvailableAgents lists the agents of a default team
+var AvailableAgents = TeamAgents(topology.New(topology.DefaultDevCount))
+
+// TeamAgents converts the agents of a team topology into Agent entries
+func TeamAgents(team topology.Team) []Agent {
+	agents := make([]Agent, 0, team.PaneCount())
+	for _, agent := range team.Agents() {
+		agents = append(agents, Agent{agent.Name, agent.Description})
+	}
+	return agents
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 func TestAgentStruct(t *testing.T) {
@@ -101,10 +103,13 @@ func TestIsValidAgentFunction(t *testing.T) {
 	}{
 		{"ValidPO", AgentPO, true},
 		{"ValidManager", AgentManager, true},
-		{"ValidDev1", AgentDev1, true},
-		{"ValidDev2", AgentDev2, true},
-		{"ValidDev3", AgentDev3, true},
-		{"ValidDev4", AgentDev4, true},
+		{"ValidDev1", "dev1", true},
+		{"ValidDev2", "dev2", true},
+		{"ValidDev3", "dev3", true},
+		{"ValidDev4", "dev4", true},
+		{"ValidDev5", "dev5", true},
+		{"ValidDev12", "dev12", true},
+		{"InvalidDev0", "dev0", false},
 		{"InvalidAgent", "invalid", false},
 		{"EmptyString", "", false},
 		{"CaseSensitive", "PO", false},
@@ -140,23 +145,28 @@ func TestFindAgentByNameFunction(t *testing.T) {
 		},
 		{
 			"FindDev1",
-			AgentDev1,
-			&Agent{AgentDev1, "Execution Agent 1 (Flexible role assignment)"},
+			"dev1",
+			&Agent{"dev1", "Execution Agent 1 (Flexible role assignment)"},
 		},
 		{
 			"FindDev2",
-			AgentDev2,
-			&Agent{AgentDev2, "Execution Agent 2 (Flexible role assignment)"},
+			"dev2",
+			&Agent{"dev2", "Execution Agent 2 (Flexible role assignment)"},
 		},
 		{
 			"FindDev3",
-			AgentDev3,
-			&Agent{AgentDev3, "Execution Agent 3 (Flexible role assignment)"},
+			"dev3",
+			&Agent{"dev3", "Execution Agent 3 (Flexible role assignment)"},
 		},
 		{
 			"FindDev4",
-			AgentDev4,
-			&Agent{AgentDev4, "Execution Agent 4 (Flexible role assignment)"},
+			"dev4",
+			&Agent{"dev4", "Execution Agent 4 (Flexible role assignment)"},
+		},
+		{
+			"FindDev8",
+			"dev8",
+			&Agent{"dev8", "Execution Agent 8 (Flexible role assignment)"},
 		},
 		{
 			"NotFoundInvalid",
@@ -195,20 +205,15 @@ func TestConstants(t *testing.T) {
 		assert.Equal(t, 6, IntegratedSessionPaneCount)
 	})
 
-	t.Run("DelayConstants", func(t *testing.T) {
-		assert.Equal(t, 400, ClearDelay)
-		assert.Equal(t, 200, AdditionalClearDelay)
-		assert.Equal(t, 300, MessageDelay)
-		assert.Equal(t, 500, ExecuteDelay)
+	t.Run("DeliveryTimingConstants", func(t *testing.T) {
+		assert.Less(t, PollInterval, StableWindow)
+		assert.Less(t, StableWindow, PasteSettleTimeout)
+		assert.Less(t, ResetStableWindow, ResetSettleTimeout)
 	})
 
 	t.Run("AgentConstants", func(t *testing.T) {
 		assert.Equal(t, "po", AgentPO)
 		assert.Equal(t, "manager", AgentManager)
-		assert.Equal(t, "dev1", AgentDev1)
-		assert.Equal(t, "dev2", AgentDev2)
-		assert.Equal(t, "dev3", AgentDev3)
-		assert.Equal(t, "dev4", AgentDev4)
 	})
 }
 
@@ -221,10 +226,10 @@ func TestAvailableAgents(t *testing.T) {
 		expectedAgents := []Agent{
 			{AgentPO, "Product Owner (Product Manager)"},
 			{AgentManager, "Project Manager (Flexible team management)"},
-			{AgentDev1, "Execution Agent 1 (Flexible role assignment)"},
-			{AgentDev2, "Execution Agent 2 (Flexible role assignment)"},
-			{AgentDev3, "Execution Agent 3 (Flexible role assignment)"},
-			{AgentDev4, "Execution Agent 4 (Flexible role assignment)"},
+			{"dev1", "Execution Agent 1 (Flexible role assignment)"},
+			{"dev2", "Execution Agent 2 (Flexible role assignment)"},
+			{"dev3", "Execution Agent 3 (Flexible role assignment)"},
+			{"dev4", "Execution Agent 4 (Flexible role assignment)"},
 		}
 
 		assert.Equal(t, expectedAgents, AvailableAgents)
@@ -257,37 +262,19 @@ func TestAvailableAgents(t *testing.T) {
 	})
 }
 
-func TestValidAgentNames(t *testing.T) {
-	t.Run("ValidAgentNamesLength", func(t *testing.T) {
-		assert.Len(t, ValidAgentNames, 6)
-	})
-
-	t.Run("ValidAgentNamesContent", func(t *testing.T) {
-		expectedMap := map[string]bool{
-			AgentPO: true, AgentManager: true, AgentDev1: true,
-			AgentDev2: true, AgentDev3: true, AgentDev4: true,
-		}
-
-		assert.Equal(t, expectedMap, ValidAgentNames)
+func TestTeamAgents(t *testing.T) {
+	t.Run("LargerTeam", func(t *testing.T) {
+		agents := TeamAgents(topology.New(6))
+		assert.Len(t, agents, 8)
+		assert.Equal(t, Agent{"dev6", "Execution Agent 6 (Flexible role assignment)"}, agents[7])
 	})
 
-	t.Run("ValidAgentNamesConsistency", func(t *testing.T) {
-		// AvailableAgentsとValidAgentNamesの整合性チェック
-		for _, agent := range AvailableAgents {
-			assert.True(t, ValidAgentNames[agent.Name], "Agent %s should be in ValidAgentNames", agent.Name)
-		}
-
-		// ValidAgentNamesの各キーがAvailableAgentsに存在するかチェック
-		for name := range ValidAgentNames {
-			found := false
-			for _, agent := range AvailableAgents {
-				if agent.Name == name {
-					found = true
-					break
-				}
-			}
-			assert.True(t, found, "ValidAgentNames key %s should exist in AvailableAgents", name)
-		}
+	t.Run("LeadersOnly", func(t *testing.T) {
+		agents := TeamAgents(topology.New(0))
+		assert.Equal(t, []Agent{
+			{AgentPO, "Product Owner (Product Manager)"},
+			{AgentManager, "Project Manager (Flexible team management)"},
+		}, agents)
 	})
 }
 
@@ -295,7 +282,7 @@ func TestAgentNameConsistency(t *testing.T) {
 	t.Run("AgentConstantsConsistency", func(t *testing.T) {
 		// 定数で定義されたエージェント名がAvailableAgentsに存在するかチェック
 		agentConstants := []string{
-			AgentPO, AgentManager, AgentDev1, AgentDev2, AgentDev3, AgentDev4,
+			AgentPO, AgentManager, "dev1", "dev2", "dev3", "dev4",
 		}
 
 		for _, constant := range agentConstants {
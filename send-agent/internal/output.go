@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Machine-readable output (--output json|yaml|table)
+//
+// The JSON and YAML schemas are the exported structs with their json/yaml
+// tags; fields are only ever added so wrapper scripts keep working.
+
+// Output formats
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// OutputFormats lists the accepted --output values
+var OutputFormats = []string{OutputTable, OutputJSON, OutputYAML}
+
+// TableWriter is implemented by results that have a human readable form
+type TableWriter interface {
+	WriteTable(w io.Writer) error
+}
+
+// ValidateOutputFormat checks an --output value
+func ValidateOutputFormat(format string) error {
+	for _, f := range OutputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format '%s' (%s)", format, strings.Join(OutputFormats, "|"))
+}
+
+// WriteOutput writes the value in the requested format; table output requires a TableWriter
+func WriteOutput(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case OutputJSON:
+		return writeJSON(w, v)
+	case OutputYAML:
+		return writeYAML(w, v)
+	case OutputTable:
+		table, ok := v.(TableWriter)
+		if !ok {
+			return fmt.Errorf("table output is not supported for %T", v)
+		}
+		return table.WriteTable(w)
+	default:
+		return ValidateOutputFormat(format)
+	}
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeYAML(w io.Writer, v interface{}) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, format := range OutputFormats {
+		assert.NoError(t, ValidateOutputFormat(format))
+	}
+	assert.Error(t, ValidateOutputFormat("xml"))
+	assert.Error(t, ValidateOutputFormat(""))
+}
+
+func TestWriteOutput_SessionList(t *testing.T) {
+	list := &SessionList{Sessions: []SessionSummary{
+		{Name: "myproject", Type: SessionTypeTeam, Panes: 6, Agents: []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}},
+		{Name: "solo", Type: SessionTypeIndividual, Panes: 1, Agents: []string{"manager"}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteOutput(&buf, OutputJSON, list))
+	var decoded SessionList
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *list, decoded)
+
+	buf.Reset()
+	require.NoError(t, WriteOutput(&buf, OutputYAML, list))
+	assert.Contains(t, buf.String(), "sessions:\n  - name: myproject\n    type: team\n")
+
+	assert.Error(t, WriteOutput(&buf, OutputTable, list), "session list has no table form")
+	assert.Error(t, WriteOutput(&buf, "xml", list))
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+)
+
+// Disk-backed message queue
+//
+// Messages for busy agents are stored as one JSON file per message under
+// ~/.claude/claude-code-agents/sessions/<session>/queue and delivered in order
+// by the session's delivery daemon once the agent is idle.
+
+// QueueDirName is the name of the queue directory inside the session directory
+const QueueDirName = "queue"
+
+// QueuedMessage is a message waiting for delivery
+type QueuedMessage struct {
+	ID           string            `json:"id"`
+	SessionName  string            `json:"session"`
+	From         string            `json:"from,omitempty"`
+	ReplyTo      string            `json:"reply_to,omitempty"`
+	InReplyTo    string            `json:"in_reply_to,omitempty"`
+	Agent        string            `json:"agent"`
+	Target       string            `json:"target"`
+	Message      string            `json:"message"`
+	ResetContext bool              `json:"reset_context,omitempty"`
+	Meta         map[string]string `json:"meta,omitempty"`
+	File         string            `json:"file,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Attempts     int               `json:"attempts,omitempty"`
+
+	path string
+}
+
+// MessageQueue is the queue of one session
+type MessageQueue struct {
+	SessionName string
+	Dir         string
+}
+
+// SessionDir returns the per-session state directory (next to the session manifest)
+func SessionDir(sessionName string) string {
+	if path := GetSessionOption(sessionName, manifest.OptionManifest); path != "" {
+		return filepath.Dir(path)
+	}
+	return manifest.SessionDir(manifest.ConfigDir(), sessionName)
+}
+
+// NewMessageQueue returns the queue of the session
+func NewMessageQueue(sessionName string) *MessageQueue {
+	return &MessageQueue{
+		SessionName: sessionName,
+		Dir:         filepath.Join(SessionDir(sessionName), QueueDirName),
+	}
+}
+
+// Enqueue stores a message for later delivery
+func (q *MessageQueue) Enqueue(msg *QueuedMessage) error {
+	if err := os.MkdirAll(q.Dir, 0750); err != nil {
+		return fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	if msg.ID == "" {
+		msg.ID = newMessageID(msg.Agent)
+	}
+	msg.SessionName = q.SessionName
+	msg.path = filepath.Join(q.Dir, msg.ID+".json")
+
+	return q.write(msg)
+}
+
+// Update rewrites a queued message (e.g. after a failed attempt)
+func (q *MessageQueue) Update(msg *QueuedMessage) error {
+	if msg.path == "" {
+		return fmt.Errorf("message %s was not loaded from the queue", msg.ID)
+	}
+	return q.write(msg)
+}
+
+// Pending returns all queued messages, highest priority first and oldest first within a priority
+func (q *MessageQueue) Pending() ([]*QueuedMessage, error) {
+	entries, err := os.ReadDir(q.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	var messages []*QueuedMessage
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		path := filepath.Join(q.Dir, entry.Name())
+		data, err := os.ReadFile(path) // #nosec G304
+		if err != nil {
+			continue
+		}
+
+		var msg QueuedMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		msg.path = path
+		messages = append(messages, &msg)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		pi, pj := PriorityRank(messages[i].Meta[MetaPriority]), PriorityRank(messages[j].Meta[MetaPriority])
+		if pi != pj {
+			return pi > pj
+		}
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+// Remove deletes a delivered message from the queue
+func (q *MessageQueue) Remove(msg *QueuedMessage) error {
+	if err := os.Remove(msg.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove queued message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// write stores the message atomically
+func (q *MessageQueue) write(msg *QueuedMessage) error {
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queued message: %w", err)
+	}
+
+	tmp := msg.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write queued message: %w", err)
+	}
+	if err := os.Rename(tmp, msg.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write queued message: %w", err)
+	}
+	return nil
+}
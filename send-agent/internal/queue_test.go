@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageQueue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	queue := NewMessageQueue("queue-test-session-12345")
+
+	t.Run("Empty queue", func(t *testing.T) {
+		pending, err := queue.Pending()
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	second := &QueuedMessage{Agent: "dev1", Target: "%3", Message: "second", CreatedAt: base.Add(time.Second)}
+	first := &QueuedMessage{Agent: "dev1", Target: "%3", Message: "first\nwith newline", CreatedAt: base}
+	require.NoError(t, queue.Enqueue(second))
+	require.NoError(t, queue.Enqueue(first))
+
+	t.Run("Pending is ordered oldest first", func(t *testing.T) {
+		pending, err := queue.Pending()
+		require.NoError(t, err)
+		require.Len(t, pending, 2)
+		assert.Equal(t, "first\nwith newline", pending[0].Message)
+		assert.Equal(t, "second", pending[1].Message)
+		assert.Equal(t, "queue-test-session-12345", pending[0].SessionName)
+		assert.NotEmpty(t, pending[0].ID)
+	})
+
+	t.Run("Update and remove", func(t *testing.T) {
+		pending, err := queue.Pending()
+		require.NoError(t, err)
+
+		pending[0].Attempts = 2
+		require.NoError(t, queue.Update(pending[0]))
+		require.NoError(t, queue.Remove(pending[1]))
+
+		pending, err = queue.Pending()
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, 2, pending[0].Attempts)
+		assert.NoError(t, queue.Remove(pending[0]))
+		assert.NoError(t, queue.Remove(pending[0]), "removing a delivered message twice is not an error")
+	})
+
+	t.Run("Unreadable files are skipped", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(queue.Dir, "broken.json"), []byte("{"), 0600))
+		pending, err := queue.Pending()
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+}
+
+func TestMessageQueue_PriorityOrder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	queue := NewMessageQueue("queue-test-priority-12345")
+
+	base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, queue.Enqueue(&QueuedMessage{Agent: "dev1", Message: "normal", CreatedAt: base}))
+	require.NoError(t, queue.Enqueue(&QueuedMessage{Agent: "dev1", Message: "low", CreatedAt: base.Add(-time.Minute), Meta: map[string]string{MetaPriority: "low"}}))
+	require.NoError(t, queue.Enqueue(&QueuedMessage{Agent: "dev1", Message: "urgent", CreatedAt: base.Add(time.Minute), Meta: map[string]string{MetaPriority: "urgent"}}))
+
+	pending, err := queue.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	assert.Equal(t, []string{"urgent", "normal", "low"}, []string{pending[0].Message, pending[1].Message, pending[2].Message})
+}
+
+func TestQueuedMessage_UpdateWithoutLoad(t *testing.T) {
+	queue := NewMessageQueue("queue-test-session-12345")
+	assert.Error(t, queue.Update(&QueuedMessage{ID: "x"}))
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DaemonLockFile)
+
+	lock, err := tryLock(path)
+	require.NoError(t, err)
+
+	_, err = tryLock(path)
+	assert.Error(t, err, "second lock must fail while the first is held")
+
+	lock.release()
+	lock, err = tryLock(path)
+	require.NoError(t, err)
+	lock.release()
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Sender header and replies
+//
+// Every delivered message starts with a header line identifying its origin:
+//
+//	[from: dev2 | msg: 42 | reply-to: manager | re: 41]
+//
+// so the receiving agent knows whom to answer. `send-agent reply` looks up
+// the last message delivered to the calling agent and answers its reply-to
+// address (the sender unless --reply-to was given); "reply --to <id>" answers
+// a specific message instead.
+
+// FormatSenderHeader renders the header line of a message; empty fields are omitted
+func FormatSenderHeader(from, id, replyTo, inReplyTo string) string {
+	fields := []string{"from: " + from}
+	if id != "" {
+		fields = append(fields, "msg: "+id)
+	}
+	if replyTo != "" && replyTo != from {
+		fields = append(fields, "reply-to: "+replyTo)
+	}
+	if inReplyTo != "" {
+		fields = append(fields, "re: "+inReplyTo)
+	}
+	return "[" + strings.Join(fields, " | ") + "]"
+}
+
+// ReplyAddress returns the agent a reply to the record goes to
+func (r HistoryRecord) ReplyAddress() string {
+	if r.ReplyTo != "" {
+		return r.ReplyTo
+	}
+	return r.From
+}
+
+// LastReceived returns the last message delivered (or queued) to the agent
+func LastReceived(sessionName, agent string) (*HistoryRecord, error) {
+	records, err := LoadHistory(sessionName, HistoryFilter{Agent: agent})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].To == agent && records[i].Result != ResultFailed {
+			return &records[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no message to %s found in session '%s'", agent, sessionName)
+}
+
+// FindMessage returns the logged message with the given ID
+func FindMessage(sessionName, id string) (*HistoryRecord, error) {
+	records, err := LoadHistory(sessionName, HistoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].ID == id && records[i].Result != ResultFailed {
+			return &records[i], nil
+		}
+	}
+	return nil, fmt.Errorf("message %s not found in session '%s'", id, sessionName)
+}
+
+// ReplyAgent returns the agent a reply to the record is sent to, or an error when the sender cannot receive replies
+func (r HistoryRecord) ReplyAgent() (string, error) {
+	to := r.ReplyAddress()
+	if to == SenderUser || !topology.IsAddress(to) {
+		return "", fmt.Errorf("message %s to %s came from %s, who cannot receive replies", r.ID, r.To, to)
+	}
+	return to, nil
+}
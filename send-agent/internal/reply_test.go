@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSenderHeader(t *testing.T) {
+	assert.Equal(t, "[from: dev2 | msg: 42 | reply-to: manager]", FormatSenderHeader("dev2", "42", "manager", ""))
+	assert.Equal(t, "[from: manager | msg: 43 | re: 42]", FormatSenderHeader("manager", "43", "", "42"))
+	assert.Equal(t, "[from: dev1 | msg: 7]", FormatSenderHeader("dev1", "7", "dev1", ""))
+	assert.Equal(t, "[from: user]", FormatSenderHeader("user", "", "", ""))
+}
+
+func TestMessageSender_Text(t *testing.T) {
+	ms := &MessageSender{ID: "5", Message: "hello"}
+	assert.Equal(t, "[from: user | msg: 5]\nhello", ms.text())
+
+	ms = &MessageSender{ID: "6", From: "dev2", ReplyTo: "manager", InReplyTo: "5", Message: "done"}
+	assert.Equal(t, "[from: dev2 | msg: 6 | reply-to: manager | re: 5]\ndone", ms.text())
+}
+
+func TestLastReceived(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "reply-test-session-12345"
+
+	base := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	records := []HistoryRecord{
+		{Time: base, ID: "1", Session: session, From: "po", To: "manager", Result: ResultDelivered},
+		{Time: base.Add(time.Minute), ID: "2", Session: session, From: "manager", To: "dev1", Result: ResultDelivered, ReplyTo: "po"},
+		{Time: base.Add(2 * time.Minute), ID: "3", Session: session, From: "dev3", To: "dev1", Result: ResultFailed},
+		{Time: base.Add(3 * time.Minute), ID: "4", Session: session, From: "user", To: "dev2", Result: ResultDelivered},
+	}
+	for _, record := range records {
+		require.NoError(t, AppendHistory(record))
+	}
+
+	last, err := LastReceived(session, "manager")
+	require.NoError(t, err)
+	assert.Equal(t, "1", last.ID)
+	to, err := last.ReplyAgent()
+	require.NoError(t, err)
+	assert.Equal(t, "po", to)
+
+	// Failed deliveries are skipped and reply-to overrides the sender
+	last, err = LastReceived(session, "dev1")
+	require.NoError(t, err)
+	assert.Equal(t, "2", last.ID)
+	to, err = last.ReplyAgent()
+	require.NoError(t, err)
+	assert.Equal(t, "po", to)
+
+	last, err = LastReceived(session, "dev2")
+	require.NoError(t, err)
+	_, err = last.ReplyAgent()
+	assert.Error(t, err, "the user cannot receive replies")
+
+	_, err = LastReceived(session, "dev4")
+	assert.Error(t, err)
+}
+
+func TestNextMessageNumber(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "sequence-test-session-12345"
+
+	first, err := NextMessageNumber(session)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first)
+
+	// Concurrent allocation (broadcast) must hand out unique numbers
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := map[int]bool{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := NextMessageNumber(session)
+			assert.NoError(t, err)
+			mu.Lock()
+			seen[n] = true
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, 10)
+	for n := 2; n <= 11; n++ {
+		assert.True(t, seen[n], "number %d allocated", n)
+	}
+}
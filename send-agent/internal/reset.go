@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+)
+
+// Context reset
+//
+// --reset clears the agent's conversation with Claude's /clear command, waits
+// until the agent is idle again and re-sends its role instruction file, so the
+// message that follows is handled by an agent in a known, clean role. The
+// instruction file is the one start-agents resolved at launch (recorded in the
+// session manifest); without it the reset is refused before anything is cleared.
+
+// ClearCommand is the Claude slash command that discards the conversation
+const ClearCommand = "/clear"
+
+// Reset timing (milliseconds)
+const (
+	// IdlePollInterval is how often the agent state is checked while waiting for idle
+	IdlePollInterval = 500
+	// ResetInstructionTimeout is how long the agent may take to read its role instructions
+	ResetInstructionTimeout = 120000
+)
+
+// RoleInstructionFile returns the role instruction file of the agent recorded in the session manifest
+func RoleInstructionFile(sessionName, agent string) (string, error) {
+	m, err := LoadSessionManifest(sessionName)
+	if err != nil {
+		return "", err
+	}
+	entry := m.Agent(agent)
+	if entry == nil || entry.Instruction == "" {
+		return "", fmt.Errorf("no role instruction file recorded for %s in session '%s'", agent, sessionName)
+	}
+	return entry.Instruction, nil
+}
+
+// roleInstructionMessage returns the text sent to restore the role: the file contents, or a pointer
+// to the file when it is larger than the inline limit
+func roleInstructionMessage(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read role instructions: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("role instruction file %s is empty", path)
+	}
+
+	if len(data) > DefaultMaxInlineSize {
+		return fmt.Sprintf("Your role instructions (%d bytes) are in %s. Please read that file and follow its instructions.", len(data), path), nil
+	}
+	return string(data), nil
+}
+
+// waitForIdle polls the agent state until Claude is idle at its prompt
+func waitForIdle(socket, target string, timeoutMs int) error {
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	state := agentstate.StateUnknown
+	for {
+		if snapshot, err := agentstate.InspectOn(socket, target); err == nil {
+			state = snapshot.State
+			switch state {
+			case agentstate.StateIdle:
+				return nil
+			case agentstate.StateExited:
+				return fmt.Errorf("pane %s is not running Claude CLI (runs %s)", target, snapshot.Command)
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("agent did not become idle within %s (state: %s)", time.Duration(timeoutMs)*time.Millisecond, state)
+		}
+		time.Sleep(time.Duration(IdlePollInterval) * time.Millisecond)
+	}
+}
+
+// resetAgentContext clears the conversation with /clear and restores the agent's role instructions
+func (ms *MessageSender) resetAgentContext(target string) error {
+	ms.printf("🔄 Starting context reset...\n")
+
+	// Resolve the role before clearing: an agent without its context and without its role is worse
+	// off than before the reset
+	path, err := RoleInstructionFile(ms.SessionName, ms.Agent)
+	if err != nil {
+		return fmt.Errorf("cannot restore the role after %s: %w", ClearCommand, err)
+	}
+	instructions, err := roleInstructionMessage(path)
+	if err != nil {
+		return fmt.Errorf("cannot restore the role after %s: %w", ClearCommand, err)
+	}
+
+	socket := ms.socket()
+	before, err := TmuxCapturePane(socket, target)
+	if err != nil {
+		return fmt.Errorf("failed to read pane: %v", err)
+	}
+	if err := ms.typeSlashCommand(target, ClearCommand); err != nil {
+		return err
+	}
+
+	ms.printf("⏳ Waiting for %s to clear its context...\n", ms.Agent)
+	waitForPaneSettled(socket, target, before, true, StableWindow, ResetSettleTimeout)
+	if err := waitForIdle(socket, target, ResetSettleTimeout); err != nil {
+		return fmt.Errorf("%s failed: %w", ClearCommand, err)
+	}
+
+	ms.printf("📋 Re-sending role instructions (%s)...\n", path)
+	before, err = TmuxCapturePane(socket, target)
+	if err != nil {
+		return fmt.Errorf("failed to read pane: %v", err)
+	}
+	if err := DeliverMessage(socket, target, instructions); err != nil {
+		return fmt.Errorf("role instruction sending failed: %v", err)
+	}
+
+	// Wait until the agent has finished reading its instructions
+	waitForPaneSettled(socket, target, before, true, ResetStableWindow, ResetSettleTimeout)
+	if err := waitForIdle(socket, target, ResetInstructionTimeout); err != nil {
+		return fmt.Errorf("role instructions not processed: %w", err)
+	}
+
+	ms.printf("✅ Context reset completed\n")
+	return nil
+}
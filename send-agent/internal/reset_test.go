@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+)
+
+func TestRoleInstructionFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	sessionName := "reset-test-session-12345"
+
+	_, err := RoleInstructionFile(sessionName, "dev1")
+	assert.Error(t, err, "missing manifest")
+
+	m := writeTestManifest(t, sessionName)
+	_, err = RoleInstructionFile(sessionName, "dev1")
+	assert.ErrorContains(t, err, "no role instruction file")
+
+	m.Agents[2].Instruction = "/instructions/developer.md"
+	_, err = m.Save(manifest.DefaultConfigDir())
+	require.NoError(t, err)
+
+	path, err := RoleInstructionFile(sessionName, "dev1")
+	require.NoError(t, err)
+	assert.Equal(t, "/instructions/developer.md", path)
+
+	_, err = RoleInstructionFile(sessionName, "dev9")
+	assert.Error(t, err)
+}
+
+func TestRoleInstructionMessage(t *testing.T) {
+	dir := t.TempDir()
+
+	small := filepath.Join(dir, "developer.md")
+	require.NoError(t, os.WriteFile(small, []byte("# Developer\nImplement tasks.\n"), 0600))
+	text, err := roleInstructionMessage(small)
+	require.NoError(t, err)
+	assert.Equal(t, "# Developer\nImplement tasks.\n", text)
+
+	// Large files are referenced instead of pasted
+	large := filepath.Join(dir, "manager.md")
+	require.NoError(t, os.WriteFile(large, []byte(strings.Repeat("x", DefaultMaxInlineSize+1)), 0600))
+	text, err = roleInstructionMessage(large)
+	require.NoError(t, err)
+	assert.Contains(t, text, large)
+	assert.Less(t, len(text), DefaultMaxInlineSize)
+
+	empty := filepath.Join(dir, "po.md")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0600))
+	_, err = roleInstructionMessage(empty)
+	assert.Error(t, err)
+
+	_, err = roleInstructionMessage(filepath.Join(dir, "missing.md"))
+	assert.Error(t, err)
+}
+
+func TestResetAgentContext_RefusedWithoutRole(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Without a role instruction file nothing is sent to the pane
+	ms := &MessageSender{SessionName: "reset-test-session-67890", Agent: "dev1", Out: io.Discard}
+	err := ms.resetAgentContext("%999999")
+	assert.ErrorContains(t, err, "cannot restore the role")
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Routing policy enforcement
+//
+// The ROUTE_<ROLE> entries of agents.conf are recorded in the session manifest
+// at launch. send-agent refuses messages the policy does not allow and logs
+// them as rejected in the message log.
+
+// ErrRouteDenied is returned when the routing policy forbids a message
+var ErrRouteDenied = errors.New("message not allowed by routing policy")
+
+// LoadRoutingPolicy returns the routing policy of the session (empty when none is configured)
+func LoadRoutingPolicy(sessionName string) topology.RoutingPolicy {
+	m, err := LoadSessionManifest(sessionName)
+	if err != nil {
+		return nil
+	}
+	return topology.RoutingPolicy(m.Routes)
+}
+
+// checkRoute verifies that the sender may message the recipient
+func (ms *MessageSender) checkRoute() error {
+	policy := ms.Policy
+	if policy == nil {
+		policy = LoadRoutingPolicy(ms.SessionName)
+	}
+
+	if policy.Allows(ms.sender(), ms.Agent) {
+		return nil
+	}
+
+	role, _ := topology.RoleOf(ms.sender())
+	return fmt.Errorf("%w: %s (%s) may not message %s (allowed: %v)", ErrRouteDenied, ms.sender(), role, ms.Agent, policy[role])
+}
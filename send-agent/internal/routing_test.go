@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+func TestMessageSender_CheckRoute(t *testing.T) {
+	policy := topology.RoutingPolicy{
+		topology.RoleDev: {topology.RoleManager},
+		topology.RolePO:  {topology.RoleManager},
+	}
+
+	allowed := &MessageSender{From: "dev1", Agent: "manager", Policy: policy}
+	assert.NoError(t, allowed.checkRoute())
+
+	fromUser := &MessageSender{Agent: "dev2", Policy: policy}
+	assert.NoError(t, fromUser.checkRoute())
+
+	denied := &MessageSender{From: "dev1", Agent: "po", Policy: policy}
+	err := denied.checkRoute()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRouteDenied))
+	assert.Contains(t, err.Error(), "dev1 (dev) may not message po")
+}
+
+func TestSend_RejectedByRoutingPolicy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "routing-test-session-12345"
+
+	sender := &MessageSender{
+		SessionName: session,
+		From:        "dev2",
+		Agent:       "dev3",
+		Message:     "ping",
+		Policy:      topology.RoutingPolicy{topology.RoleDev: {topology.RoleManager}},
+		Out:         io.Discard,
+	}
+	err := sender.Send()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRouteDenied))
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, ResultRejected, records[0].Result)
+	assert.Equal(t, "dev2", records[0].From)
+	assert.Equal(t, "dev3", records[0].To)
+}
@@ -0,0 +1,424 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Scheduled and recurring messages
+//
+// "send-agent --at 14:00", "--in 30m" and "--every 1h" store the message in
+// the session's schedule file instead of sending it. A per-session scheduler
+// process (launched by start-agents with the team, or on demand when a
+// message is scheduled) sends due messages and exits when the session is gone.
+// Missed runs of recurring messages (e.g. while the machine slept) are
+// skipped, so a message is sent at most once per due check.
+
+// Schedule files inside the session directory
+const (
+	ScheduleFile      = "schedule.json"
+	ScheduleLockFile  = "schedule.lock"
+	SchedulerLockFile = "scheduler.lock"
+	SchedulerLogFile  = "scheduler.log"
+
+	// SchedulerCommand is the hidden send-agent subcommand that runs the scheduler
+	SchedulerCommand = "scheduler-daemon"
+)
+
+// Scheduler timing
+const (
+	// SchedulerPollInterval is how often the schedule is checked (milliseconds)
+	SchedulerPollInterval = 1000
+	// MinScheduleInterval is the shortest --every interval
+	MinScheduleInterval = time.Minute
+)
+
+// scheduleLockTimeout is how long to wait for the schedule lock (milliseconds)
+const scheduleLockTimeout = 2000
+
+// ScheduledMessage is a message waiting for its send time
+type ScheduledMessage struct {
+	ID           string            `json:"id" yaml:"id"`
+	From         string            `json:"from,omitempty" yaml:"from,omitempty"`
+	ReplyTo      string            `json:"reply_to,omitempty" yaml:"reply_to,omitempty"`
+	To           string            `json:"to" yaml:"to"` // agent, agent list or group
+	Message      string            `json:"message" yaml:"message"`
+	Meta         map[string]string `json:"meta,omitempty" yaml:"meta,omitempty"`
+	File         string            `json:"file,omitempty" yaml:"file,omitempty"`
+	ResetContext bool              `json:"reset_context,omitempty" yaml:"reset_context,omitempty"`
+	NoVerify     bool              `json:"no_verify,omitempty" yaml:"no_verify,omitempty"`
+	Interrupt    bool              `json:"interrupt,omitempty" yaml:"interrupt,omitempty"`
+	Next         time.Time         `json:"next" yaml:"next"`
+	Every        string            `json:"every,omitempty" yaml:"every,omitempty"` // repeat interval (Go duration), empty for one-shot messages
+	Runs         int               `json:"runs,omitempty" yaml:"runs,omitempty"`
+	CreatedAt    time.Time         `json:"created_at" yaml:"created_at"`
+}
+
+// ScheduleList is the schedule of a session as shown by "send-agent schedule list"
+type ScheduleList struct {
+	Session  string              `json:"session" yaml:"session"`
+	Messages []*ScheduledMessage `json:"messages" yaml:"messages"`
+}
+
+// scheduleFile is the on-disk format of the schedule
+type scheduleFile struct {
+	Messages []*ScheduledMessage `json:"messages"`
+}
+
+// ParseScheduleAt parses an --at value: HH:MM[:SS] (today, or tomorrow when already past),
+// YYYY-MM-DD HH:MM[:SS] or RFC3339. The time must be in the future.
+func ParseScheduleAt(value string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+			if !at.After(now) {
+				at = at.AddDate(0, 0, 1)
+			}
+			return at, nil
+		}
+	}
+
+	var at time.Time
+	var err error
+	if at, err = time.Parse(time.RFC3339, value); err != nil {
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+			if at, err = time.ParseInLocation(layout, value, now.Location()); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s' (use HH:MM, YYYY-MM-DD HH:MM or RFC3339)", value)
+	}
+	if !at.After(now) {
+		return time.Time{}, fmt.Errorf("time '%s' is in the past", value)
+	}
+	return at, nil
+}
+
+// ParseScheduleEvery parses an --every interval
+func ParseScheduleEvery(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval '%s' (use a duration like 30m or 1h)", value)
+	}
+	if d < MinScheduleInterval {
+		return 0, fmt.Errorf("interval '%s' is too short (minimum %s)", value, MinScheduleInterval)
+	}
+	return d, nil
+}
+
+// FirstRun returns the first send time for the --at, --in and --every values (at most one of at/in).
+// Without --at and --in, recurring messages are first sent one interval from now.
+func FirstRun(at, in, every string, now time.Time) (time.Time, error) {
+	if at != "" && in != "" {
+		return time.Time{}, errors.New("--at and --in cannot be combined")
+	}
+
+	var interval time.Duration
+	if every != "" {
+		d, err := ParseScheduleEvery(every)
+		if err != nil {
+			return time.Time{}, err
+		}
+		interval = d
+	}
+
+	switch {
+	case at != "":
+		return ParseScheduleAt(at, now)
+	case in != "":
+		d, err := time.ParseDuration(in)
+		if err != nil || d <= 0 {
+			return time.Time{}, fmt.Errorf("invalid delay '%s' (use a duration like 30m or 2h)", in)
+		}
+		return now.Add(d), nil
+	case interval > 0:
+		return now.Add(interval), nil
+	}
+	return time.Time{}, errors.New("one of --at, --in or --every is required")
+}
+
+// Advance moves a recurring message to its next run after now, skipping missed runs.
+// It returns false for one-shot messages, which are removed after sending.
+func (m *ScheduledMessage) Advance(now time.Time) bool {
+	interval, err := time.ParseDuration(m.Every)
+	if m.Every == "" || err != nil || interval <= 0 {
+		return false
+	}
+	for !m.Next.After(now) {
+		m.Next = m.Next.Add(interval)
+	}
+	return true
+}
+
+// sender builds the message sender used when the message is due
+func (m *ScheduledMessage) sender(sessionName string, out io.Writer) MessageSender {
+	return MessageSender{
+		From:         m.From,
+		ReplyTo:      m.ReplyTo,
+		SessionName:  sessionName,
+		Agent:        m.To,
+		Message:      m.Message,
+		Meta:         m.Meta,
+		File:         m.File,
+		ResetContext: m.ResetContext,
+		NoVerify:     m.NoVerify,
+		Interrupt:    m.Interrupt,
+		Out:          out,
+	}
+}
+
+// SchedulePath returns the path of the session's schedule file
+func SchedulePath(sessionName string) string {
+	return filepath.Join(SessionDir(sessionName), ScheduleFile)
+}
+
+// LoadSchedule returns the scheduled messages of the session, next run first
+func LoadSchedule(sessionName string) ([]*ScheduledMessage, error) {
+	return readSchedule(SchedulePath(sessionName))
+}
+
+// AddScheduled stores a message in the session's schedule and assigns its ID
+func AddScheduled(sessionName string, msg *ScheduledMessage) error {
+	return updateSchedule(sessionName, func(messages []*ScheduledMessage) ([]*ScheduledMessage, error) {
+		last := 0
+		for _, m := range messages {
+			if n, err := strconv.Atoi(m.ID); err == nil && n > last {
+				last = n
+			}
+		}
+		msg.ID = strconv.Itoa(last + 1)
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = time.Now()
+		}
+		return append(messages, msg), nil
+	})
+}
+
+// CancelScheduled removes a message from the session's schedule
+func CancelScheduled(sessionName, id string) error {
+	return updateSchedule(sessionName, func(messages []*ScheduledMessage) ([]*ScheduledMessage, error) {
+		for i, m := range messages {
+			if m.ID == id {
+				return append(messages[:i], messages[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("no scheduled message '%s' in session '%s'", id, sessionName)
+	})
+}
+
+// TakeDue removes the messages due at now from the schedule and returns them.
+// Recurring messages stay scheduled with their next run.
+func TakeDue(sessionName string, now time.Time) ([]*ScheduledMessage, error) {
+	var due []*ScheduledMessage
+	err := updateSchedule(sessionName, func(messages []*ScheduledMessage) ([]*ScheduledMessage, error) {
+		remaining := messages[:0]
+		for _, m := range messages {
+			if m.Next.After(now) {
+				remaining = append(remaining, m)
+				continue
+			}
+
+			run := *m
+			due = append(due, &run)
+			m.Runs++
+			if m.Advance(now) {
+				remaining = append(remaining, m)
+			}
+		}
+		return remaining, nil
+	})
+	return due, err
+}
+
+// updateSchedule applies fn to the schedule under the schedule lock
+func updateSchedule(sessionName string, fn func([]*ScheduledMessage) ([]*ScheduledMessage, error)) error {
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	lock, err := lockWait(filepath.Join(dir, ScheduleLockFile), scheduleLockTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to lock schedule: %w", err)
+	}
+	defer lock.release()
+
+	path := filepath.Join(dir, ScheduleFile)
+	messages, err := readSchedule(path)
+	if err != nil {
+		return err
+	}
+
+	messages, err = fn(messages)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(scheduleFile{Messages: messages}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial schedule
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write schedule: %w", err)
+	}
+	return nil
+}
+
+func readSchedule(path string) ([]*ScheduledMessage, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedule: %w", err)
+	}
+
+	var schedule scheduleFile
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule %s: %w", path, err)
+	}
+
+	sort.SliceStable(schedule.Messages, func(i, j int) bool {
+		return schedule.Messages[i].Next.Before(schedule.Messages[j].Next)
+	})
+	return schedule.Messages, nil
+}
+
+// WriteTable writes the schedule in a human readable form
+func (l *ScheduleList) WriteTable(w io.Writer) error {
+	if len(l.Messages) == 0 {
+		fmt.Fprintf(w, "📭 No scheduled messages (Session: %s)\n", l.Session)
+		return nil
+	}
+
+	fmt.Fprintf(w, "⏰ Scheduled messages (Session: %s)\n", l.Session)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tFROM\tTO\tNEXT\tEVERY\tMESSAGE")
+	for _, m := range l.Messages {
+		every := m.Every
+		if every == "" {
+			every = "-"
+		}
+		from := m.From
+		if from == "" {
+			from = SenderUser
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", m.ID, from, m.To, m.Next.Local().Format("2006-01-02 15:04:05"), every, summarizeMessage(m.Message, 40))
+	}
+	return tw.Flush()
+}
+
+// summarizeMessage returns the first line of the message, shortened to max runes
+func summarizeMessage(message string, max int) string {
+	line := strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+	runes := []rune(line)
+	if len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return line
+}
+
+// EnsureScheduler starts the scheduler of the session in the background unless it is running
+func EnsureScheduler(sessionName string) error {
+	if err := startSessionProcess(sessionName, SchedulerCommand, SchedulerLockFile, SchedulerLogFile); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+	return nil
+}
+
+// RunScheduler sends due messages of the session until the session is gone
+func RunScheduler(sessionName string) error {
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	lock, err := tryLock(filepath.Join(dir, SchedulerLockFile))
+	if err != nil {
+		return fmt.Errorf("scheduler for session '%s' is already running", sessionName)
+	}
+	defer lock.release()
+
+	fmt.Printf("[%s] ⏰ Scheduler started (session: %s, pid: %d)\n", timestamp(), sessionName, os.Getpid())
+
+	for {
+		if !HasSession(sessionName) {
+			fmt.Printf("[%s] 🛑 Session '%s' is gone, stopping\n", timestamp(), sessionName)
+			return nil
+		}
+
+		due, err := TakeDue(sessionName, time.Now())
+		if err != nil {
+			fmt.Printf("[%s] ❌ %v\n", timestamp(), err)
+		}
+		for _, msg := range due {
+			sendScheduled(sessionName, msg)
+		}
+
+		time.Sleep(time.Duration(SchedulerPollInterval) * time.Millisecond)
+	}
+}
+
+// sendScheduled sends a due message and logs the outcome. The message has already left the
+// schedule, so failures are recorded in the message log where "send-agent history" shows them.
+func sendScheduled(sessionName string, msg *ScheduledMessage) {
+	fmt.Printf("[%s] 📤 Sending scheduled message %s to %s\n", timestamp(), msg.ID, msg.To)
+
+	template := msg.sender(sessionName, os.Stdout)
+	if !topology.IsMultiRecipient(msg.To) {
+		if err := template.Send(); err != nil {
+			fmt.Printf("[%s] ❌ Scheduled message %s: %v\n", timestamp(), msg.ID, err)
+			recordUnlogged(template, err)
+		}
+		return
+	}
+
+	recipients, err := ResolveRecipients(sessionName, msg.To)
+	if err != nil {
+		fmt.Printf("[%s] ❌ Scheduled message %s: %v\n", timestamp(), msg.ID, err)
+		recordUnlogged(template, err)
+		return
+	}
+	results := Broadcast(template, recipients)
+	for _, r := range results {
+		if r.Err != nil {
+			sender := template
+			sender.Agent, sender.Result = r.Agent, r.Result
+			recordUnlogged(sender, r.Err)
+		}
+	}
+	if err := WriteBroadcastReport(os.Stdout, results); err != nil {
+		fmt.Printf("[%s] ❌ Scheduled message %s: %v\n", timestamp(), msg.ID, err)
+	}
+}
+
+// recordUnlogged logs a failed send that ended before a result was logged (e.g. the agent
+// is not running or its pane is gone)
+func recordUnlogged(sender MessageSender, err error) {
+	if sender.Result != "" {
+		return
+	}
+	if sender.ID == "" {
+		sender.ID = sender.allocateID()
+	}
+	sender.recordHistory(ResultFailed, err)
+}
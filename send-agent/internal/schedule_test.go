@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleAt(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	at, err := ParseScheduleAt("14:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 0, 0, 0, time.Local), at)
+
+	// Times already past today mean tomorrow
+	at, err = ParseScheduleAt("09:30", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 11, 9, 30, 0, 0, time.Local), at)
+
+	at, err = ParseScheduleAt("2025-03-12 08:15", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 12, 8, 15, 0, 0, time.Local), at)
+
+	_, err = ParseScheduleAt("2025-03-09 08:15", now)
+	assert.Error(t, err)
+	_, err = ParseScheduleAt("tomorrow", now)
+	assert.Error(t, err)
+}
+
+func TestFirstRun(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	next, err := FirstRun("", "30m", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(30*time.Minute), next)
+
+	next, err = FirstRun("", "", "1h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), next)
+
+	next, err = FirstRun("13:00", "", "1h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), next)
+
+	for _, tc := range []struct{ at, in, every string }{
+		{"13:00", "30m", ""},
+		{"", "-5m", ""},
+		{"", "", "10s"},
+		{"", "", ""},
+	} {
+		_, err := FirstRun(tc.at, tc.in, tc.every, now)
+		assert.Error(t, err, "%+v", tc)
+	}
+}
+
+func TestScheduledMessage_Advance(t *testing.T) {
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+
+	once := &ScheduledMessage{Next: start}
+	assert.False(t, once.Advance(start))
+
+	// Missed runs are skipped: the next run is the first one after now
+	recurring := &ScheduledMessage{Next: start, Every: "1h"}
+	assert.True(t, recurring.Advance(start.Add(3*time.Hour+10*time.Minute)))
+	assert.Equal(t, start.Add(4*time.Hour), recurring.Next)
+}
+
+func TestSchedule_AddTakeCancel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "schedule-test-session-12345"
+	now := time.Now()
+
+	once := &ScheduledMessage{To: "dev1", Message: "check-in", Next: now.Add(-time.Second)}
+	recurring := &ScheduledMessage{To: "devs", Message: "Status report please", Next: now.Add(-time.Second), Every: "1h"}
+	later := &ScheduledMessage{To: "manager", Message: "wrap up", Next: now.Add(time.Hour)}
+	for _, msg := range []*ScheduledMessage{once, recurring, later} {
+		require.NoError(t, AddScheduled(session, msg))
+	}
+	assert.Equal(t, "1", once.ID)
+	assert.Equal(t, "3", later.ID)
+
+	due, err := TakeDue(session, now)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "check-in", due[0].Message)
+	assert.Equal(t, "Status report please", due[1].Message)
+
+	messages, err := LoadSchedule(session)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "2", messages[0].ID)
+	assert.Equal(t, 1, messages[0].Runs)
+	assert.True(t, messages[0].Next.After(now))
+
+	due, err = TakeDue(session, now)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	require.NoError(t, CancelScheduled(session, "2"))
+	assert.Error(t, CancelScheduled(session, "2"))
+
+	messages, err = LoadSchedule(session)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, (&ScheduleList{Session: session, Messages: messages}).WriteTable(&buf))
+	assert.Contains(t, buf.String(), "wrap up")
+}
+
+func TestSendScheduled_RecordsFailure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "schedule-test-session-67890"
+
+	// The session does not exist, so the message cannot reach its pane
+	sendScheduled(session, &ScheduledMessage{ID: "1", To: "dev1", Message: "check-in"})
+
+	records, err := LoadHistory(session, HistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, ResultFailed, records[0].Result)
+	assert.Equal(t, "dev1", records[0].To)
+	assert.Equal(t, "check-in", records[0].Message)
+	assert.NotEmpty(t, records[0].Error)
+	assert.NotEmpty(t, records[0].ID)
+}
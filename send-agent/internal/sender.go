@@ -1,34 +1,70 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // Helper functions
 
 func IsValidAgent(agent string) bool {
-	return ValidAgentNames[agent]
+	return topology.IsAgentName(agent)
 }
 
 func FindAgentByName(name string) *Agent {
-	for _, agent := range AvailableAgents {
-		if agent.Name == name {
-			return &agent
-		}
+	agent, ok := topology.Lookup(name)
+	if !ok {
+		return nil
 	}
-	return nil
+	return &Agent{agent.Name, agent.Description}
 }
 
 // Message sending related methods
 
+// out returns the writer progress messages are written to
+func (ms *MessageSender) out() io.Writer {
+	if ms.Out == nil {
+		return os.Stdout
+	}
+	return ms.Out
+}
+
+func (ms *MessageSender) printf(format string, args ...interface{}) {
+	fmt.Fprintf(ms.out(), format, args...)
+}
+
 func (ms *MessageSender) Send() error {
+	if err := ms.admit(); err != nil {
+		return err
+	}
+
 	target, err := ms.determineTarget()
 	if err != nil {
 		return err
 	}
 
-	if err := ms.sendEnhancedMessage(target); err != nil {
+	if ms.ID == "" {
+		ms.ID = ms.allocateID()
+	}
+
+	if !ms.Interrupt {
+		queued, err := ms.queueIfBusy(target)
+		if err != nil {
+			return err
+		}
+		if queued {
+			return nil
+		}
+	}
+
+	if err := ms.deliver(target); err != nil {
 		return err
 	}
 
@@ -36,46 +72,216 @@ func (ms *MessageSender) Send() error {
 	return nil
 }
 
+// admit applies the routing policy and, unless forced, the loop guard; refused messages are logged
+func (ms *MessageSender) admit() error {
+	if err := ms.checkRoute(); err != nil {
+		ms.printf("🚫 %v\n", err)
+		ms.recordHistory(ResultRejected, err)
+		return err
+	}
+
+	if !ms.Force {
+		if err := ms.checkLoop(); err != nil {
+			ms.printf("🔁 %v\n", err)
+			ms.recordHistory(ResultBlocked, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateID returns the next message number of the session, or a time-based ID if the counter is unavailable
+func (ms *MessageSender) allocateID() string {
+	n, err := NextMessageNumber(ms.SessionName)
+	if err != nil {
+		ms.printf("⚠️ %v\n", err)
+		return newMessageID(ms.Agent)
+	}
+	return strconv.Itoa(n)
+}
+
+// socket returns the tmux server of the recipient's session
+func (ms *MessageSender) socket() string {
+	if ms.Socket != "" {
+		return ms.Socket
+	}
+	return SessionSocket(ms.SessionName)
+}
+
+// sender returns the sending agent, SenderUser when unknown
+func (ms *MessageSender) sender() string {
+	if ms.From == "" {
+		return SenderUser
+	}
+	return ms.From
+}
+
+// text returns the message as pasted into the pane: the sender header followed by the message
+func (ms *MessageSender) text() string {
+	return FormatSenderHeader(ms.sender(), ms.ID, ms.ReplyTo, ms.InReplyTo) + "\n" + ms.Message
+}
+
+// deliver sends the message to the target and records the result in the message log
+func (ms *MessageSender) deliver(target string) error {
+	err := ms.sendEnhancedMessage(target)
+
+	result := ResultDelivered
+	switch {
+	case errors.Is(err, ErrDeliveryUnconfirmed):
+		result = ResultUnconfirmed
+	case err != nil:
+		result = ResultFailed
+	case ms.NoVerify:
+		result = ResultUnverified
+	}
+	ms.recordHistory(result, err)
+
+	return err
+}
+
+// recordHistory appends the message to the session's message log; failures only produce a warning
+func (ms *MessageSender) recordHistory(result string, deliveryErr error) {
+	record := HistoryRecord{
+		Time:      time.Now(),
+		ID:        ms.ID,
+		Session:   ms.SessionName,
+		From:      ms.sender(),
+		To:        ms.Agent,
+		Result:    result,
+		ReplyTo:   ms.ReplyTo,
+		InReplyTo: ms.InReplyTo,
+		Meta:      ms.Meta,
+		File:      ms.File,
+		Message:   ms.Message,
+	}
+	if deliveryErr != nil {
+		record.Error = deliveryErr.Error()
+	}
+	ms.Result = result
+
+	if err := AppendHistory(record); err != nil {
+		ms.printf("⚠️ Failed to record message history: %v\n", err)
+	}
+	if err := mirrorHistory(record); err != nil {
+		ms.printf("⚠️ Failed to record message history: %v\n", err)
+	}
+}
+
+// queueIfBusy queues the message when the agent cannot take it now (or earlier messages are still queued)
+// and makes sure the session's delivery daemon is running
+func (ms *MessageSender) queueIfBusy(target string) (bool, error) {
+	snapshot, err := agentstate.InspectOn(ms.socket(), target)
+	if err != nil {
+		// State unknown (e.g. pane information unavailable): deliver directly as before
+		return false, nil
+	}
+
+	queue := NewMessageQueue(ms.SessionName)
+	pending, err := queue.Pending()
+	if err != nil {
+		return false, err
+	}
+	waiting := 0
+	for _, msg := range pending {
+		if msg.Agent == ms.Agent {
+			waiting++
+		}
+	}
+
+	switch snapshot.State {
+	case agentstate.StateExited:
+		return false, fmt.Errorf("agent '%s' is not running Claude CLI (pane %s runs %s); use --interrupt to send anyway", ms.Agent, snapshot.PaneID, snapshot.Command)
+	case agentstate.StateBusy, agentstate.StateAwaitingInput, agentstate.StateRateLimited:
+	default:
+		if waiting == 0 {
+			return false, nil
+		}
+	}
+
+	msg := &QueuedMessage{
+		ID:           ms.ID,
+		From:         ms.From,
+		ReplyTo:      ms.ReplyTo,
+		InReplyTo:    ms.InReplyTo,
+		Agent:        ms.Agent,
+		Target:       target,
+		Message:      ms.Message,
+		ResetContext: ms.ResetContext,
+		Meta:         ms.Meta,
+		File:         ms.File,
+	}
+	if err := queue.Enqueue(msg); err != nil {
+		return false, err
+	}
+	ms.recordHistory(ResultQueued, nil)
+	if err := EnsureDeliveryDaemon(ms.SessionName); err != nil {
+		return false, err
+	}
+
+	ms.printf("📥 %s is %s: message queued (id: %s, %d already waiting)\n", ms.Agent, snapshot.State, msg.ID, waiting)
+	ms.printf("   It will be delivered as soon as %s is idle. Use --interrupt to deliver immediately.\n", ms.Agent)
+	return true, nil
+}
+
 func (ms *MessageSender) determineTarget() (string, error) {
 	if HasSession(ms.SessionName) {
+		if IsTeamSession(ms.SessionName) {
+			return ms.determineManifestTarget()
+		}
 		return ms.determineIntegratedTarget()
 	}
 	return ms.determineIndividualTarget()
 }
 
+func (ms *MessageSender) determineManifestTarget() (string, error) {
+	paneID, err := ResolveAgentPane(ms.SessionName, ms.Agent)
+	if err != nil {
+		return "", err
+	}
+
+	ms.printf("🎯 Using team session (%s) to send message\n", ms.SessionName)
+	ms.printf("📍 Sending message to %s pane (pane %s)\n", ms.Agent, paneID)
+	return paneID, nil
+}
+
 func (ms *MessageSender) determineIntegratedTarget() (string, error) {
 	paneCount, err := GetPaneCount(ms.SessionName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get information for session '%s': %v", ms.SessionName, err)
 	}
 
-	if paneCount != IntegratedSessionPaneCount {
+	if paneCount <= topology.LeaderCount {
 		return "", fmt.Errorf("session '%s' is not in integrated monitoring screen format", ms.SessionName)
 	}
 
-	fmt.Printf("🎯 Using integrated monitoring screen (%s) to send message\n", ms.SessionName)
+	// Without a manifest the team size is inferred from the pane count
+	team := topology.New(paneCount - topology.LeaderCount)
+	agent, ok := team.Find(ms.Agent)
+	if !ok {
+		return "", fmt.Errorf("agent '%s' not found in session '%s' (%d panes)", ms.Agent, ms.SessionName, paneCount)
+	}
 
-	paneIndex := ms.getAgentPaneIndex()
+	ms.printf("🎯 Using integrated monitoring screen (%s) to send message\n", ms.SessionName)
+
+	paneIndex := agent.Index
 	panes, err := GetPanes(ms.SessionName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get pane information: %v", err)
 	}
 
-	if paneIndex < len(panes) {
-		target := fmt.Sprintf("%s.%s", ms.SessionName, panes[paneIndex])
-		fmt.Printf("📍 Sending message to %s pane (pane %s)\n", ms.Agent, panes[paneIndex])
-		return target, nil
+	if paneIndex >= len(panes) {
+		return "", fmt.Errorf("agent '%s' has no pane in session '%s' (%d panes)", ms.Agent, ms.SessionName, len(panes))
 	}
 
-	target := fmt.Sprintf("%s.%d", ms.SessionName, paneIndex)
-	fmt.Printf("📍 Sending message to %s pane (pane %d - fallback)\n", ms.Agent, paneIndex)
+	target := panes[paneIndex]
+	ms.printf("📍 Sending message to %s pane (pane %s)\n", ms.Agent, target)
 	return target, nil
 }
 
 func (ms *MessageSender) determineIndividualTarget() (string, error) {
-	fmt.Printf("🔄 Using individual session mode (%s) to send message\n", ms.SessionName)
+	ms.printf("🔄 Using individual session mode (%s) to send message\n", ms.SessionName)
 
-	fullSession := ms.SessionName + "-" + ms.Agent
+	fullSession := topology.AgentSessionName(ms.SessionName, ms.Agent)
 	if !HasSession(fullSession) {
 		return "", fmt.Errorf("session '%s' not found", fullSession)
 	}
@@ -83,17 +289,18 @@ func (ms *MessageSender) determineIndividualTarget() (string, error) {
 	return fullSession, nil
 }
 
-func (ms *MessageSender) getAgentPaneIndex() int {
-	agentPaneMap := map[string]int{
-		AgentPO: 0, AgentManager: 1, AgentDev1: 2,
-		AgentDev2: 3, AgentDev3: 4, AgentDev4: 5,
-	}
-	return agentPaneMap[ms.Agent]
-}
-
 func (ms *MessageSender) sendEnhancedMessage(target string) error {
-	fmt.Printf("📤 Sending: sending message to %s...\n", ms.Agent)
-	fmt.Printf("🎯 Target: %s\n", target)
+	ms.printf("📤 Sending: sending message to %s...\n", ms.Agent)
+	ms.printf("🎯 Target: %s\n", target)
+	socket := ms.socket()
+
+	// Interrupt the running turn only when explicitly requested
+	if ms.Interrupt {
+		ms.printf("⛔ Interrupting current work (Ctrl+C)...\n")
+		if err := SendControlKey(socket, target, "C-c"); err != nil {
+			return fmt.Errorf("prompt clear failed: %v", err)
+		}
+	}
 
 	// If context reset is needed
 	if ms.ResetContext {
@@ -103,66 +310,40 @@ func (ms *MessageSender) sendEnhancedMessage(target string) error {
 	}
 
 	// Clear prompt
-	fmt.Printf("🧹 Clearing prompt (Ctrl+C)...\n")
-	if err := TmuxSendKeys(target, "C-c"); err != nil {
-		return fmt.Errorf("prompt clear failed: %v", err)
-	}
-	time.Sleep(time.Duration(ClearDelay) * time.Millisecond)
-
-	// Additional clear
-	fmt.Printf("🧹 Additional clear (Ctrl+U)...\n")
-	if err := TmuxSendKeys(target, "C-u"); err != nil {
+	ms.printf("🧹 Clearing prompt (Ctrl+U)...\n")
+	if err := SendControlKey(socket, target, "C-u"); err != nil {
 		return fmt.Errorf("additional clear failed: %v", err)
 	}
-	time.Sleep(time.Duration(AdditionalClearDelay) * time.Millisecond)
 
-	// Send message
-	fmt.Printf("💬 Message sending: \"%s\"\n", ms.Message)
-	if err := TmuxSendKeys(target, ms.Message); err != nil {
+	// Paste message and submit
+	text := ms.text()
+	ms.printf("💬 Message sending: \"%s\"\n", text)
+	if err := DeliverMessage(socket, target, text); err != nil {
 		return fmt.Errorf("message sending failed: %v", err)
 	}
-	time.Sleep(time.Duration(MessageDelay) * time.Millisecond)
 
-	// Press Enter
-	fmt.Printf("⏎ Sending Enter (C-m)...\n")
-	if err := TmuxSendKeys(target, "C-m"); err != nil {
-		return fmt.Errorf("Enter sending failed: %v", err)
+	if ms.NoVerify {
+		ms.printf("✅ Sending completed: auto-executed to %s (not verified)\n", ms.Agent)
+		return nil
 	}
-	time.Sleep(time.Duration(ExecuteDelay) * time.Millisecond)
-
-	fmt.Printf("✅ Sending completed: auto-executed to %s\n", ms.Agent)
-	return nil
-}
 
-func (ms *MessageSender) resetAgentContext(target string) error {
-	fmt.Printf("🔄 Starting context reset...\n")
-
-	resetMessage := "Please forget the previous role definitions and context, and wait for new instructions."
-
-	// Send reset message
-	fmt.Printf("💭 Sending reset message: \"%s\"\n", resetMessage)
-	if err := TmuxSendKeys(target, resetMessage); err != nil {
-		return fmt.Errorf("reset message sending failed: %v", err)
-	}
-	time.Sleep(time.Duration(MessageDelay) * time.Millisecond)
-
-	// Press Enter
-	if err := TmuxSendKeys(target, "C-m"); err != nil {
-		return fmt.Errorf("Enter sending failed: %v", err)
+	// Confirm that the agent received the message
+	ms.printf("🔎 Verifying delivery...\n")
+	if err := ConfirmDelivery(socket, target, text, ms.out()); err != nil {
+		return err
 	}
-	time.Sleep(time.Duration(ExecuteDelay*3) * time.Millisecond) // Wait longer
 
-	fmt.Printf("✅ Context reset completed\n")
+	ms.printf("✅ Sending completed: %s received the message and started working\n", ms.Agent)
 	return nil
 }
 
 func (ms *MessageSender) displaySummary(target string) {
-	fmt.Println()
-	fmt.Println("🎯 Message details:")
-	fmt.Printf("   Session: %s\n", ms.SessionName)
-	fmt.Printf("   Destination: %s (%s)\n", ms.Agent, target)
-	fmt.Printf("   Content: \"%s\"\n", ms.Message)
+	ms.printf("\n")
+	ms.printf("🎯 Message details:\n")
+	ms.printf("   Session: %s\n", ms.SessionName)
+	ms.printf("   Destination: %s (%s)\n", ms.Agent, target)
+	ms.printf("   Content: \"%s\"\n", ms.Message)
 	if ms.ResetContext {
-		fmt.Printf("   Context reset: executed\n")
+		ms.printf("   Context reset: executed\n")
 	}
 }
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Per-session message numbers
+//
+// Messages are numbered 1, 2, 3, ... per session so agents can refer to them
+// ("msg: 42"). The counter lives in the session directory and is updated
+// under a lock, because broadcasts allocate numbers concurrently.
+
+// Sequence files inside the session directory
+const (
+	SequenceFile     = "sequence"
+	SequenceLockFile = "sequence.lock"
+)
+
+// sequenceLockTimeout is how long to wait for the sequence lock (milliseconds)
+const sequenceLockTimeout = 2000
+
+// NextMessageNumber allocates the next message number of the session
+func NextMessageNumber(sessionName string) (int, error) {
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return 0, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	lock, err := lockWait(filepath.Join(dir, SequenceLockFile), sequenceLockTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock message sequence: %w", err)
+	}
+	defer lock.release()
+
+	path := filepath.Join(dir, SequenceFile)
+	current := 0
+	if data, err := os.ReadFile(path); err == nil { // #nosec G304
+		current, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+
+	next := current + 1
+	if err := os.WriteFile(path, []byte(strconv.Itoa(next)+"\n"), 0600); err != nil {
+		return 0, fmt.Errorf("failed to update message sequence: %w", err)
+	}
+	return next, nil
+}
+
+// lockWait takes an exclusive lock, retrying until the timeout expires
+func lockWait(path string, timeoutMs int) (*fileLock, error) {
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		lock, err := tryLock(path)
+		if err == nil {
+			return lock, nil
+		}
+		if !time.Now().Before(deadline) {
+			return nil, err
+		}
+		time.Sleep(time.Duration(PollInterval) * time.Millisecond)
+	}
+}
@@ -2,27 +2,90 @@ package internal
 
 import (
 	"fmt"
-	"regexp"
 	"sort"
+
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
+// SessionSummary describes one AI agent session in list-sessions output
+type SessionSummary struct {
+	Name   string   `json:"name" yaml:"name"`
+	Type   string   `json:"type" yaml:"type"`
+	Panes  int      `json:"panes" yaml:"panes"`
+	Agents []string `json:"agents" yaml:"agents"`
+	// Socket is the dedicated tmux server of the team (tmux -L), if any
+	Socket string `json:"socket,omitempty" yaml:"socket,omitempty"`
+}
+
+// SessionList is the machine-readable result of list-sessions
+type SessionList struct {
+	Sessions []SessionSummary `json:"sessions" yaml:"sessions"`
+}
+
 // Session management methods
 
+// CollectSessions returns all AI agent sessions (team, integrated and individual groups)
+func (sm *SessionManager) CollectSessions() (*SessionList, error) {
+	sessions, err := GetTmuxSessions()
+	dedicatedSessions := sm.dedicatedSessions()
+	if err != nil && len(dedicatedSessions) == 0 {
+		return nil, fmt.Errorf("failed to get tmux sessions: %v", err)
+	}
+
+	list := &SessionList{Sessions: []SessionSummary{}}
+	integratedSessions, individualSessions := sm.categorizeSession(sessions)
+	integratedSessions = append(integratedSessions, dedicatedSessions...)
+
+	for _, session := range integratedSessions {
+		summary := SessionSummary{Name: session.Name, Type: SessionTypeIntegrated, Panes: session.Panes, Socket: session.Socket}
+		if m, err := LoadSessionManifest(session.Name); err == nil {
+			summary.Type = SessionTypeTeam
+			for _, agent := range m.Agents {
+				summary.Agents = append(summary.Agents, agent.Name)
+			}
+		} else if session.Panes > topology.LeaderCount {
+			summary.Agents = topology.New(session.Panes - topology.LeaderCount).Names()
+		}
+		list.Sessions = append(list.Sessions, summary)
+	}
+
+	var baseNames []string
+	for baseName := range individualSessions {
+		baseNames = append(baseNames, baseName)
+	}
+	sort.Strings(baseNames)
+	for _, baseName := range baseNames {
+		summary := SessionSummary{Name: baseName, Type: SessionTypeIndividual}
+		if _, targets, err := resolveIndividualTargets(baseName); err == nil {
+			for _, t := range targets {
+				summary.Agents = append(summary.Agents, t.name)
+			}
+			summary.Panes = len(targets)
+		}
+		list.Sessions = append(list.Sessions, summary)
+	}
+
+	return list, nil
+}
+
 func (sm *SessionManager) ListAllSessions() error {
 	fmt.Println("📋 Available AI Agent Sessions:")
 	fmt.Println("==================================")
 
 	sessions, err := GetTmuxSessions()
-	if err != nil {
+	dedicatedSessions := sm.dedicatedSessions()
+	if err != nil && len(dedicatedSessions) == 0 {
 		return fmt.Errorf("failed to get tmux sessions: %v", err)
 	}
 
-	if len(sessions) == 0 {
+	if len(sessions) == 0 && len(dedicatedSessions) == 0 {
 		fmt.Println("❌ No running tmux sessions")
 		return nil
 	}
 
 	integratedSessions, individualSessions := sm.categorizeSession(sessions)
+	integratedSessions = append(integratedSessions, dedicatedSessions...)
 
 	sm.displayIntegratedSessions(integratedSessions)
 	sm.displayIndividualSessions(individualSessions)
@@ -46,7 +109,7 @@ func (sm *SessionManager) categorizeSession(sessions []Session) ([]Session, map[
 			continue
 		}
 
-		if paneCount == IntegratedSessionPaneCount {
+		if IsTeamSession(session.Name) || paneCount == IntegratedSessionPaneCount {
 			integratedSessions = append(integratedSessions, Session{
 				Name:  session.Name,
 				Type:  "integrated",
@@ -61,14 +124,32 @@ func (sm *SessionManager) categorizeSession(sessions []Session) ([]Session, map[
 	return integratedSessions, individualSessions
 }
 
+// dedicatedSessions returns the team sessions running on their own tmux server
+func (sm *SessionManager) dedicatedSessions() []Session {
+	var sessions []Session
+	for _, socket := range tmuxsocket.Dedicated() {
+		sessionName, _ := tmuxsocket.SessionOf(socket)
+		if socket == tmuxsocket.Selected() {
+			// Already listed as a session of the current server
+			continue
+		}
+		if paneCount, err := GetPaneCount(sessionName); err == nil {
+			sessions = append(sessions, Session{Name: sessionName, Type: "integrated", Panes: paneCount, Socket: socket})
+		}
+	}
+	return sessions
+}
+
 func (sm *SessionManager) isIndividualSession(sessionName string) bool {
-	re := regexp.MustCompile(`-(po|manager|dev[1-4])$`)
-	return re.MatchString(sessionName)
+	_, _, ok := topology.SplitAgentSession(sessionName)
+	return ok
 }
 
 func (sm *SessionManager) extractBaseName(sessionName string) string {
-	re := regexp.MustCompile(`-(po|manager|dev[1-4])$`)
-	return re.ReplaceAllString(sessionName, "")
+	if baseName, _, ok := topology.SplitAgentSession(sessionName); ok {
+		return baseName
+	}
+	return sessionName
 }
 
 func (sm *SessionManager) displayIntegratedSessions(sessions []Session) {
@@ -76,7 +157,11 @@ func (sm *SessionManager) displayIntegratedSessions(sessions []Session) {
 		fmt.Println()
 		fmt.Println("📺 Integrated monitoring screen sessions:")
 		for _, session := range sessions {
-			fmt.Printf("  🎯 %s (6-pane integrated screen)\n", session.Name)
+			if session.Socket != "" {
+				fmt.Printf("  🎯 %s (%d-pane integrated screen, tmux server %s)\n", session.Name, session.Panes, session.Socket)
+			} else {
+				fmt.Printf("  🎯 %s (%d-pane integrated screen)\n", session.Name, session.Panes)
+			}
 			fmt.Printf("    Usage: send-agent --session %s po \"message\"\n", session.Name)
 		}
 	}
@@ -110,14 +195,24 @@ func (sm *SessionManager) ShowAgentsForSession(sessionName string) error {
 }
 
 func (sm *SessionManager) showIntegratedSessionAgents(sessionName string) error {
+	if m, err := LoadSessionManifest(sessionName); err == nil {
+		fmt.Printf("🎯 Using team session (%s, launched %s):\n", sessionName, m.LaunchedAt.Format("2006-01-02 15:04:05"))
+		for _, agent := range m.Agents {
+			fmt.Printf("  %s → pane %s (%s)\n", agent.Name, agent.PaneID, agent.Title)
+		}
+		fmt.Println()
+		fmt.Println("Current pane status:")
+		return ShowPanes(sessionName)
+	}
+
 	paneCount, err := GetPaneCount(sessionName)
 	if err != nil {
 		return fmt.Errorf("failed to get information for session '%s': %v", sessionName, err)
 	}
 
-	if paneCount == IntegratedSessionPaneCount {
+	if paneCount > topology.LeaderCount {
 		fmt.Printf("🎯 Using integrated monitoring screen (%s):\n", sessionName)
-		sm.displayAgentPaneMapping()
+		sm.displayAgentPaneMapping(topology.New(paneCount - topology.LeaderCount))
 		fmt.Println()
 		fmt.Println("Current pane status:")
 		return ShowPanes(sessionName)
@@ -127,13 +222,22 @@ func (sm *SessionManager) showIntegratedSessionAgents(sessionName string) error
 }
 
 func (sm *SessionManager) showIndividualSessionAgents(sessionName string) error {
+	sessions, err := GetTmuxSessions()
+	if err != nil {
+		return fmt.Errorf("failed to get tmux sessions: %v", err)
+	}
+
 	foundSessions := []string{}
-	for _, agent := range AvailableAgents {
-		fullSession := sessionName + "-" + agent.Name
-		if HasSession(fullSession) {
-			foundSessions = append(foundSessions, agent.Name)
+	for _, session := range sessions {
+		if baseName, agent, ok := topology.SplitAgentSession(session.Name); ok && baseName == sessionName {
+			foundSessions = append(foundSessions, agent)
 		}
 	}
+	sort.Slice(foundSessions, func(i, j int) bool {
+		a, _ := topology.Lookup(foundSessions[i])
+		b, _ := topology.Lookup(foundSessions[j])
+		return a.Index < b.Index
+	})
 
 	if len(foundSessions) > 0 {
 		fmt.Printf("🔄 Individual session mode (%s):\n", sessionName)
@@ -150,14 +254,8 @@ func (sm *SessionManager) showIndividualSessionAgents(sessionName string) error
 	return fmt.Errorf("no AI agent sessions related to session '%s' found\n💡 Available sessions: send-agent list-sessions", sessionName)
 }
 
-func (sm *SessionManager) displayAgentPaneMapping() {
-	agentPaneMap := map[string]int{
-		AgentPO: 0, AgentManager: 1, AgentDev1: 2,
-		AgentDev2: 3, AgentDev3: 4, AgentDev4: 5,
-	}
-
-	for _, agent := range AvailableAgents {
-		paneIndex := agentPaneMap[agent.Name]
-		fmt.Printf("  %s → pane %d (%s)\n", agent.Name, paneIndex, agent.Description)
+func (sm *SessionManager) displayAgentPaneMapping(team topology.Team) {
+	for _, agent := range team.Agents() {
+		fmt.Printf("  %s → pane %d (%s)\n", agent.Name, agent.Index, agent.Description)
 	}
 }
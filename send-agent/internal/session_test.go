@@ -56,9 +56,9 @@ func TestSessionManager_isIndividualSession(t *testing.T) {
 			expected:    false,
 		},
 		{
-			name:        "Invalid agent name",
+			name:        "Dev5 session",
 			sessionName: "project-dev5",
-			expected:    false,
+			expected:    true,
 		},
 		{
 			name:        "Partial match",
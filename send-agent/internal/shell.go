@@ -0,0 +1,477 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Interactive operator shell
+//
+// "send-agent shell" reads lines such as "@dev1 please rebase" and sends them
+// as the user. "@dev1" alone selects dev1 for the following plain lines. A
+// live feed prints messages between agents as send-agent records them in the
+// session's message log, and slash commands show status and pane output.
+
+// Shell files inside the session directory
+const ShellHistoryFile = "shell_history"
+
+// Shell settings
+const (
+	// ShellHistoryLimit is the number of input lines kept in the shell history
+	ShellHistoryLimit = 500
+	// ShellFeedInterval is how often the message log is checked for new messages (milliseconds)
+	ShellFeedInterval = 500
+	// ShellTailLines is the default number of pane lines shown by /tail
+	ShellTailLines = 20
+	// ShellHistoryLines is the number of log records shown by /history
+	ShellHistoryLines = 20
+)
+
+// Shell input kinds
+const (
+	ShellEmpty   = "empty"
+	ShellCommand = "command"
+	ShellSend    = "send"
+	ShellSelect  = "select"
+	ShellText    = "text"
+)
+
+// ShellCommands lists the slash commands of the shell
+var ShellCommands = []string{"/broadcast", "/feed", "/help", "/history", "/quit", "/status", "/tail"}
+
+// ShellInput is a parsed line of shell input
+type ShellInput struct {
+	Kind string
+	Name string // command name (without "/") or recipient spec
+	Args string // command arguments or message
+}
+
+// ParseShellInput parses one line: "/command args", "@recipients message", "@recipients" or plain text
+func ParseShellInput(line string) ShellInput {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return ShellInput{Kind: ShellEmpty}
+	}
+
+	head, rest := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		head, rest = line[:i], strings.TrimSpace(line[i+1:])
+	}
+
+	switch {
+	case strings.HasPrefix(head, "/") && len(head) > 1:
+		return ShellInput{Kind: ShellCommand, Name: strings.ToLower(head[1:]), Args: rest}
+	case strings.HasPrefix(head, "@") && len(head) > 1:
+		if rest == "" {
+			return ShellInput{Kind: ShellSelect, Name: head[1:]}
+		}
+		return ShellInput{Kind: ShellSend, Name: head[1:], Args: rest}
+	}
+	return ShellInput{Kind: ShellText, Args: line}
+}
+
+// Shell is an interactive session with a team
+type Shell struct {
+	Session string
+
+	editor     *LineEditor
+	recipients []string // agents and groups offered by completion
+	current    string   // recipient of plain lines
+	feed       atomic.Bool
+	done       chan struct{}
+}
+
+// NewShell prepares a shell for the session
+func NewShell(sessionName string, editor *LineEditor) *Shell {
+	s := &Shell{Session: sessionName, editor: editor, done: make(chan struct{})}
+	s.feed.Store(true)
+	s.recipients = shellRecipients(sessionName)
+	editor.Complete = s.complete
+	return s
+}
+
+// shellRecipients returns the agents of the session followed by the recipient groups
+func shellRecipients(sessionName string) []string {
+	var recipients []string
+	if _, targets, err := resolveAgentTargets(sessionName); err == nil {
+		for _, t := range targets {
+			recipients = append(recipients, t.name)
+		}
+	}
+
+	groups := []string{topology.GroupAll, topology.GroupDevs}
+	if m, err := LoadSessionManifest(sessionName); err == nil {
+		for name := range m.Groups {
+			groups = append(groups, name)
+		}
+	}
+	sort.Strings(groups[2:])
+	return append(recipients, groups...)
+}
+
+// Run reads and executes lines until /quit or end of input
+func (s *Shell) Run() error {
+	s.editor.SetHistory(loadShellHistory(s.Session))
+	defer func() {
+		close(s.done)
+		saveShellHistory(s.Session, s.editor.History())
+	}()
+
+	go s.watchFeed(HistoryPath(s.Session))
+
+	s.editor.Printf("💬 send-agent shell (Session: %s) — /help for commands, Ctrl+D to quit\n", s.Session)
+	for {
+		line, err := s.editor.ReadLine(s.prompt())
+		if errors.Is(err, ErrInterrupted) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if quit := s.Execute(line); quit {
+			return nil
+		}
+	}
+}
+
+func (s *Shell) prompt() string {
+	if s.current != "" {
+		return fmt.Sprintf("%s:%s> ", s.Session, s.current)
+	}
+	return s.Session + "> "
+}
+
+// Execute runs one line of input; it returns true when the shell should exit
+func (s *Shell) Execute(line string) bool {
+	input := ParseShellInput(line)
+	switch input.Kind {
+	case ShellSend:
+		s.send(input.Name, input.Args)
+	case ShellSelect:
+		if err := s.validateRecipient(input.Name); err != nil {
+			s.editor.Printf("❌ %v\n", err)
+			break
+		}
+		s.current = input.Name
+	case ShellText:
+		if s.current == "" {
+			s.editor.Printf("💡 Address an agent with @agent message, or select one with @agent\n")
+			break
+		}
+		s.send(s.current, input.Args)
+	case ShellCommand:
+		return s.command(input.Name, input.Args)
+	}
+	return false
+}
+
+func (s *Shell) command(name, args string) bool {
+	switch name {
+	case "quit", "exit", "q":
+		return true
+	case "help", "h", "?":
+		s.help()
+	case "status":
+		status, err := CollectSessionStatus(s.Session)
+		if err != nil {
+			s.editor.Printf("❌ %v\n", err)
+			break
+		}
+		_ = status.WriteTable(s.editor)
+	case "tail":
+		s.tail(args)
+	case "broadcast":
+		if args == "" {
+			s.editor.Printf("💡 Usage: /broadcast message\n")
+			break
+		}
+		s.send(topology.GroupAll, args)
+	case "history":
+		s.history(args)
+	case "feed":
+		switch args {
+		case "on":
+			s.feed.Store(true)
+		case "off":
+			s.feed.Store(false)
+		default:
+			s.feed.Store(!s.feed.Load())
+		}
+		state := "off"
+		if s.feed.Load() {
+			state = "on"
+		}
+		s.editor.Printf("📡 Live feed %s\n", state)
+	default:
+		s.editor.Printf("❌ Unknown command '/%s' (/help lists commands)\n", name)
+	}
+	return false
+}
+
+func (s *Shell) help() {
+	s.editor.Printf(`Commands:
+  @agent message      send a message (agent, dev1,dev3, devs, all or a group)
+  @agent              send the following plain lines to agent
+  /status             show the state of each agent
+  /tail agent [N]     show the last N lines of the agent's pane (default %d)
+  /broadcast message  send a message to every agent
+  /history [agent]    show the last %d logged messages
+  /feed [on|off]      toggle the live feed of messages between agents
+  /quit               leave the shell (or Ctrl+D)
+`, ShellTailLines, ShellHistoryLines)
+}
+
+func (s *Shell) validateRecipient(spec string) error {
+	if topology.IsMultiRecipient(spec) {
+		_, err := ResolveRecipients(s.Session, spec)
+		return err
+	}
+	if !IsValidAgent(spec) {
+		return fmt.Errorf("invalid agent name '%s'", spec)
+	}
+	return nil
+}
+
+// send sends the message as the user and prints a one-line result per recipient
+func (s *Shell) send(spec, text string) {
+	if err := s.validateRecipient(spec); err != nil {
+		s.editor.Printf("❌ %v\n", err)
+		return
+	}
+
+	message, err := PrepareMessage(s.Session, SenderUser, text, DefaultMaxInlineSize)
+	if err != nil {
+		s.editor.Printf("❌ %v\n", err)
+		return
+	}
+
+	var log bytes.Buffer
+	template := MessageSender{
+		From:        SenderUser,
+		SessionName: s.Session,
+		Agent:       spec,
+		Message:     message.Text,
+		Meta:        message.Meta,
+		File:        message.File,
+		Out:         &log,
+	}
+
+	if topology.IsMultiRecipient(spec) {
+		recipients, err := ResolveRecipients(s.Session, spec)
+		if err != nil {
+			s.editor.Printf("❌ %v\n", err)
+			return
+		}
+		_ = WriteBroadcastReport(s.editor, Broadcast(template, recipients))
+		return
+	}
+
+	if err := template.Send(); err != nil {
+		s.editor.Printf("❌ %s: %v\n", spec, err)
+		return
+	}
+	if template.Result == ResultQueued {
+		s.editor.Printf("📥 %s: queued (agent busy), msg %s\n", spec, template.ID)
+		return
+	}
+	s.editor.Printf("✅ %s: %s, msg %s\n", spec, template.Result, template.ID)
+}
+
+// tail prints the last lines of an agent's pane
+func (s *Shell) tail(args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		s.editor.Printf("💡 Usage: /tail agent [lines]\n")
+		return
+	}
+
+	lines := ShellTailLines
+	if len(fields) > 1 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 {
+			s.editor.Printf("❌ Invalid line count '%s'\n", fields[1])
+			return
+		}
+		lines = n
+	}
+
+	target, err := AgentTarget(s.Session, fields[0])
+	if err != nil {
+		s.editor.Printf("❌ %v\n", err)
+		return
+	}
+
+	output, err := ReadPaneLines(SessionSocket(s.Session), target, lines)
+	if err != nil {
+		s.editor.Printf("❌ %v\n", err)
+		return
+	}
+	s.editor.Printf("── %s (%s) ──\n%s\n", fields[0], target, strings.Join(LastLines(output, lines), "\n"))
+}
+
+// history prints the last logged messages, optionally of one agent
+func (s *Shell) history(agent string) {
+	records, err := LoadHistory(s.Session, HistoryFilter{Agent: strings.TrimSpace(agent)})
+	if err != nil {
+		s.editor.Printf("❌ %v\n", err)
+		return
+	}
+	if len(records) > ShellHistoryLines {
+		records = records[len(records)-ShellHistoryLines:]
+	}
+	if len(records) == 0 {
+		s.editor.Printf("📭 No messages found\n")
+		return
+	}
+	WriteHistory(s.editor, records)
+}
+
+// complete offers slash commands at the start of the line, recipients after "@" or ","
+// and agents as the argument of /tail and /history
+func (s *Shell) complete(line []rune, pos int) (int, []string) {
+	start := pos
+	for start > 0 && !strings.ContainsRune(" @,", line[start-1]) {
+		start--
+	}
+	word := string(line[start:pos])
+	before := strings.TrimSpace(string(line[:start]))
+
+	var options []string
+	switch {
+	case start == 0 && strings.HasPrefix(word, "/"):
+		options = ShellCommands
+	case start > 0 && (line[start-1] == '@' || line[start-1] == ','):
+		options = s.recipients
+	case before == "/tail" || before == "/history":
+		options = s.recipients
+	}
+
+	var candidates []string
+	for _, option := range options {
+		if strings.HasPrefix(option, word) {
+			candidates = append(candidates, option)
+		}
+	}
+	return start, candidates
+}
+
+// watchFeed prints messages appended to the message log by agents until the shell exits
+func (s *Shell) watchFeed(path string) {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(time.Duration(ShellFeedInterval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		records, next := readHistoryFrom(path, offset)
+		offset = next
+		if !s.feed.Load() {
+			continue
+		}
+		for _, record := range records {
+			if record.From == SenderUser {
+				continue
+			}
+			s.editor.Printf("📨 %s\n", FormatFeedRecord(record))
+		}
+	}
+}
+
+// readHistoryFrom returns the complete records written after offset and the offset after them
+func readHistoryFrom(path string, offset int64) ([]HistoryRecord, int64) {
+	file, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return nil, offset
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		// The log was replaced; start over
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset
+	}
+
+	var records []HistoryRecord
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// Incomplete last line: read it again next time
+			return records, offset
+		}
+		offset += int64(len(line))
+
+		var record HistoryRecord
+		if json.Unmarshal(line, &record) == nil {
+			records = append(records, record)
+		}
+	}
+}
+
+// FormatFeedRecord renders a logged message as one feed line
+func FormatFeedRecord(record HistoryRecord) string {
+	return fmt.Sprintf("%s %s → %s [%s] msg %s: %s", record.Time.Local().Format("15:04:05"), record.From, record.To, record.Result, record.ID, summarizeMessage(record.Message, 80))
+}
+
+func loadShellHistory(sessionName string) []string {
+	file, err := os.Open(filepath.Join(SessionDir(sessionName), ShellHistoryFile)) // #nosec G304
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			history = append(history, line)
+		}
+	}
+	if len(history) > ShellHistoryLimit {
+		history = history[len(history)-ShellHistoryLimit:]
+	}
+	return history
+}
+
+func saveShellHistory(sessionName string, history []string) {
+	if len(history) > ShellHistoryLimit {
+		history = history[len(history)-ShellHistoryLimit:]
+	}
+
+	dir := SessionDir(sessionName)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return
+	}
+	data := strings.Join(history, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	_ = os.WriteFile(filepath.Join(dir, ShellHistoryFile), []byte(data), 0600)
+}
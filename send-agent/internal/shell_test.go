@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShellInput(t *testing.T) {
+	tests := []struct {
+		line string
+		want ShellInput
+	}{
+		{"", ShellInput{Kind: ShellEmpty}},
+		{"@dev1 please rebase", ShellInput{Kind: ShellSend, Name: "dev1", Args: "please rebase"}},
+		{"  @dev1,dev3   review PR #4 ", ShellInput{Kind: ShellSend, Name: "dev1,dev3", Args: "review PR #4"}},
+		{"@manager", ShellInput{Kind: ShellSelect, Name: "manager"}},
+		{"/tail dev2 50", ShellInput{Kind: ShellCommand, Name: "tail", Args: "dev2 50"}},
+		{"/STATUS", ShellInput{Kind: ShellCommand, Name: "status"}},
+		{"just text", ShellInput{Kind: ShellText, Args: "just text"}},
+		{"@ alone", ShellInput{Kind: ShellText, Args: "@ alone"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ParseShellInput(tt.line), tt.line)
+	}
+}
+
+func TestShell_Complete(t *testing.T) {
+	s := &Shell{recipients: []string{"po", "manager", "dev1", "dev2", "all", "devs", "backend"}}
+
+	complete := func(line string) []string {
+		runes := []rune(line)
+		_, candidates := s.complete(runes, len(runes))
+		return candidates
+	}
+
+	assert.Equal(t, []string{"/status"}, complete("/st"))
+	assert.Equal(t, []string{"dev1", "dev2", "devs"}, complete("@dev"))
+	assert.Equal(t, []string{"dev2"}, complete("@dev1,dev2"))
+	assert.Equal(t, []string{"backend"}, complete("/tail ba"))
+	assert.Empty(t, complete("hello wor"))
+	assert.Empty(t, complete("x /st"))
+}
+
+func TestReadHistoryFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), HistoryFileName)
+
+	record := HistoryRecord{Time: time.Now(), ID: "1", From: "manager", To: "dev1", Result: ResultDelivered, Message: "start task"}
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+
+	// A partially written record is not consumed
+	require.NoError(t, os.WriteFile(path, append(append(data, '\n'), data[:10]...), 0600))
+	records, offset := readHistoryFrom(path, 0)
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(len(data)+1), offset)
+
+	require.NoError(t, os.WriteFile(path, append(append(data, '\n'), append(data, '\n')...), 0600))
+	records, offset = readHistoryFrom(path, offset)
+	require.Len(t, records, 1)
+	assert.Equal(t, int64(2*(len(data)+1)), offset)
+
+	assert.Contains(t, FormatFeedRecord(records[0]), "manager → dev1 [delivered] msg 1: start task")
+}
+
+func TestShellHistoryFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := "shell-test-session-12345"
+
+	assert.Empty(t, loadShellHistory(session))
+
+	saveShellHistory(session, []string{"@dev1 hi", "/status"})
+	assert.Equal(t, []string{"@dev1 hi", "/status"}, loadShellHistory(session))
+}
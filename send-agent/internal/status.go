@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Agent status reporting (send-agent status)
+
+// Session types reported by status
+const (
+	SessionTypeTeam       = "team"
+	SessionTypeIntegrated = "integrated"
+	SessionTypeIndividual = "individual"
+)
+
+// AgentStatus is the observed state of one agent
+type AgentStatus struct {
+	Name    string           `json:"name" yaml:"name"`
+	Role    string           `json:"role" yaml:"role"`
+	Title   string           `json:"title" yaml:"title"`
+	Target  string           `json:"target" yaml:"target"`
+	PaneID  string           `json:"pane_id" yaml:"pane_id"`
+	PID     int              `json:"pid" yaml:"pid"`
+	Command string           `json:"command" yaml:"command"`
+	State   agentstate.State `json:"state" yaml:"state"`
+	Error   string           `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// SessionStatus is the observed state of all agents in a session
+type SessionStatus struct {
+	Session string        `json:"session" yaml:"session"`
+	Type    string        `json:"type" yaml:"type"`
+	Agents  []AgentStatus `json:"agents" yaml:"agents"`
+}
+
+// agentTarget is an agent and the tmux target of its pane
+type agentTarget struct {
+	name   string
+	target string
+	title  string
+}
+
+// CollectSessionStatus inspects every agent pane of the session
+func CollectSessionStatus(sessionName string) (*SessionStatus, error) {
+	sessionType, targets, err := resolveAgentTargets(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	socket := SessionSocket(sessionName)
+	status := &SessionStatus{Session: sessionName, Type: sessionType}
+	for _, t := range targets {
+		agent := AgentStatus{Name: t.name, Target: t.target, Title: t.title}
+		if known, ok := topology.Lookup(t.name); ok {
+			agent.Role = known.Role
+			if agent.Title == "" {
+				agent.Title = known.Title
+			}
+		}
+
+		snapshot, err := agentstate.InspectOn(socket, t.target)
+		if err != nil {
+			agent.State = agentstate.StateUnknown
+			agent.Error = err.Error()
+		} else {
+			agent.PaneID = snapshot.PaneID
+			agent.PID = snapshot.PID
+			agent.Command = snapshot.Command
+			agent.State = snapshot.State
+		}
+		status.Agents = append(status.Agents, agent)
+	}
+
+	return status, nil
+}
+
+// resolveAgentTargets returns the session type and the pane target of every agent in pane order
+func resolveAgentTargets(sessionName string) (string, []agentTarget, error) {
+	if !HasSession(sessionName) {
+		return resolveIndividualTargets(sessionName)
+	}
+
+	if IsTeamSession(sessionName) {
+		if m, err := LoadSessionManifest(sessionName); err == nil {
+			targets := make([]agentTarget, 0, len(m.Agents))
+			for _, agent := range m.Agents {
+				targets = append(targets, agentTarget{agent.Name, agent.PaneID, agent.Title})
+			}
+			return SessionTypeTeam, targets, nil
+		}
+
+		paneAgents, err := GetPaneAgents(sessionName)
+		if err != nil {
+			return "", nil, err
+		}
+		var targets []agentTarget
+		for paneID, name := range paneAgents {
+			if name != "" {
+				targets = append(targets, agentTarget{name: name, target: paneID})
+			}
+		}
+		sortTargets(targets)
+		return SessionTypeTeam, targets, nil
+	}
+
+	panes, err := GetPanes(sessionName)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(panes) <= topology.LeaderCount {
+		return "", nil, fmt.Errorf("session '%s' is not in integrated monitoring screen format", sessionName)
+	}
+
+	team := topology.New(len(panes) - topology.LeaderCount)
+	targets := make([]agentTarget, 0, len(panes))
+	for _, agent := range team.Agents() {
+		targets = append(targets, agentTarget{agent.Name, panes[agent.Index], agent.Title})
+	}
+	return SessionTypeIntegrated, targets, nil
+}
+
+// resolveIndividualTargets finds the <session>-<agent> sessions of individual session mode
+func resolveIndividualTargets(sessionName string) (string, []agentTarget, error) {
+	sessions, err := GetTmuxSessions()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get tmux sessions: %v", err)
+	}
+
+	var targets []agentTarget
+	for _, session := range sessions {
+		if baseName, agent, ok := topology.SplitAgentSession(session.Name); ok && baseName == sessionName {
+			targets = append(targets, agentTarget{name: agent, target: session.Name})
+		}
+	}
+	if len(targets) == 0 {
+		return "", nil, fmt.Errorf("no AI agent sessions related to session '%s' found", sessionName)
+	}
+
+	sortTargets(targets)
+	return SessionTypeIndividual, targets, nil
+}
+
+// sortTargets orders targets by the agents' pane order
+func sortTargets(targets []agentTarget) {
+	sort.Slice(targets, func(i, j int) bool {
+		a, _ := topology.Lookup(targets[i].name)
+		b, _ := topology.Lookup(targets[j].name)
+		return a.Index < b.Index
+	})
+}
+
+// WriteTable writes the status as an aligned table
+func (s *SessionStatus) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "📊 Agent status (Session: %s, %s)\n", s.Session, s.Type)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "AGENT\tPANE\tSTATE\tPID\tCOMMAND")
+	for _, agent := range s.Agents {
+		pane := agent.PaneID
+		if pane == "" {
+			pane = agent.Target
+		}
+		state := string(agent.State)
+		if agent.Error != "" {
+			state += " (" + agent.Error + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", agent.Name, pane, state, agent.PID, agent.Command)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the status as indented JSON
+func (s *SessionStatus) WriteJSON(w io.Writer) error {
+	return writeJSON(w, s)
+}
@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+)
+
+func sampleStatus() *SessionStatus {
+	return &SessionStatus{
+		Session: "myproject",
+		Type:    SessionTypeTeam,
+		Agents: []AgentStatus{
+			{Name: "po", Role: "po", Title: "PO", Target: "%1", PaneID: "%1", PID: 100, Command: "node", State: agentstate.StateIdle},
+			{Name: "dev1", Role: "dev", Target: "%3", State: agentstate.StateUnknown, Error: "pane not found"},
+		},
+	}
+}
+
+func TestSessionStatus_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleStatus().WriteJSON(&buf))
+
+	var decoded SessionStatus
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *sampleStatus(), decoded)
+	assert.Contains(t, buf.String(), `"state": "idle"`)
+}
+
+func TestSessionStatus_WriteYAML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteOutput(&buf, OutputYAML, sampleStatus()))
+
+	var decoded SessionStatus
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *sampleStatus(), decoded)
+	assert.Contains(t, buf.String(), "pane_id: '%1'")
+	assert.Contains(t, buf.String(), "title: PO")
+}
+
+func TestSessionStatus_WriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleStatus().WriteTable(&buf))
+
+	output := buf.String()
+	assert.Contains(t, output, "AGENT")
+	assert.Contains(t, output, "idle")
+	assert.Contains(t, output, "unknown (pane not found)")
+}
+
+func TestSortTargets(t *testing.T) {
+	targets := []agentTarget{{name: "dev10", target: "a"}, {name: "dev2", target: "b"}, {name: "po", target: "c"}, {name: "manager", target: "d"}}
+	sortTargets(targets)
+	assert.Equal(t, []agentTarget{{name: "po", target: "c"}, {name: "manager", target: "d"}, {name: "dev2", target: "b"}, {name: "dev10", target: "a"}}, targets)
+}
+
+func TestCollectSessionStatus_NoSession(t *testing.T) {
+	_, err := CollectSessionStatus("non-existing-session-12345")
+	assert.Error(t, err)
+}
@@ -3,16 +3,21 @@ package internal
 import (
 	"bufio"
 	"fmt"
+	"os"
 	"os/exec"
-	"regexp"
 	"sort"
 	"strings"
+	"sync"
+
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // Tmux related utility functions
 
 func GetTmuxSessions() ([]Session, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
+	cmd := tmuxsocket.Command("list-sessions", "-F", "#{session_name}")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tmux session list: %v", err)
@@ -30,13 +35,16 @@ func GetTmuxSessions() ([]Session, error) {
 	return sessions, nil
 }
 
+// HasSession reports whether a session with exactly this name exists
+// ("=" disables tmux's prefix matching, so "proj" does not match "proj-po")
 func HasSession(sessionName string) bool {
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	cmd := sessionCommand(sessionName, "has-session", "-t", "="+sessionName)
 	return cmd.Run() == nil
 }
 
+// GetPaneCount returns the number of panes in all windows of the session
 func GetPaneCount(sessionName string) (int, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName)
+	cmd := sessionCommand(sessionName, "list-panes", "-s", "-t", sessionName)
 	output, err := cmd.Output()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get pane count: %v", err)
@@ -44,8 +52,10 @@ func GetPaneCount(sessionName string) (int, error) {
 	return len(strings.Split(strings.TrimSpace(string(output)), "\n")), nil
 }
 
+// GetPanes returns the pane IDs (%N) of all windows of the session in pane order; unlike
+// pane indexes they do not depend on the base-index and pane-base-index options
 func GetPanes(sessionName string) ([]string, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_index}")
+	cmd := sessionCommand(sessionName, "list-panes", "-s", "-t", sessionName, "-F", "#{pane_id}")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pane list: %v", err)
@@ -62,8 +72,9 @@ func GetPanes(sessionName string) ([]string, error) {
 	return panes, nil
 }
 
+// ShowPanes prints the panes of all windows of the session as "Pane <window>.<pane>: <title>"
 func ShowPanes(sessionName string) error {
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "  Pane #{pane_index}: #{pane_title}")
+	cmd := sessionCommand(sessionName, "list-panes", "-s", "-t", sessionName, "-F", "  Pane #{window_index}.#{pane_index}: #{pane_title}")
 	output, err := cmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to display pane status: %v", err)
@@ -72,17 +83,77 @@ func ShowPanes(sessionName string) error {
 	return nil
 }
 
-func TmuxSendKeys(target, keys string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", target, keys)
+// TmuxSendKeys sends keys to the target pane of the socket's server
+func TmuxSendKeys(socket, target, keys string) error {
+	cmd := tmuxsocket.CommandOn(socket, "send-keys", "-t", target, keys)
+	return cmd.Run()
+}
+
+// TmuxCapturePane returns the visible content of a pane of the socket's server
+func TmuxCapturePane(socket, target string) (string, error) {
+	cmd := tmuxsocket.CommandOn(socket, "capture-pane", "-p", "-J", "-t", target)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture pane: %v", err)
+	}
+	return string(output), nil
+}
+
+// TmuxCapturePaneHistory returns the pane content from the start line (e.g. "-100", or "-" for the
+// whole scrollback) to the end of the visible screen
+func TmuxCapturePaneHistory(socket, target, start string) (string, error) {
+	cmd := tmuxsocket.CommandOn(socket, "capture-pane", "-p", "-J", "-S", start, "-t", target)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to capture pane: %v", err)
+	}
+	return string(output), nil
+}
+
+// TmuxLoadBuffer loads data into a named paste buffer through stdin (no size or escaping limits)
+func TmuxLoadBuffer(socket, buffer, data string) error {
+	cmd := tmuxsocket.CommandOn(socket, "load-buffer", "-b", buffer, "-")
+	cmd.Stdin = strings.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load paste buffer: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// TmuxPasteBuffer pastes a named buffer into the target with bracketed paste, keeping newlines as-is,
+// and deletes the buffer afterwards
+func TmuxPasteBuffer(socket, buffer, target string) error {
+	cmd := tmuxsocket.CommandOn(socket, "paste-buffer", "-b", buffer, "-d", "-p", "-r", "-t", target)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to paste buffer: %v (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// TmuxDeleteBuffer deletes a named paste buffer
+func TmuxDeleteBuffer(socket, buffer string) error {
+	cmd := tmuxsocket.CommandOn(socket, "delete-buffer", "-b", buffer)
 	return cmd.Run()
 }
 
 func DetectDefaultSession() (string, error) {
 	sessions, err := GetTmuxSessions()
 	if err != nil || len(sessions) == 0 {
+		if sessionName, found, err := detectDedicatedSession(); found {
+			return sessionName, err
+		}
 		return "", fmt.Errorf("no tmux sessions found")
 	}
 
+	// Prefer sessions launched by start-agents (identified by their manifest), then
+	// teams running on their own tmux server
+	if sessionName, found, err := detectTeamSession(sessions); found {
+		return sessionName, err
+	}
+	if sessionName, found, err := detectDedicatedSession(); found {
+		return sessionName, err
+	}
+
 	// Prioritize integrated monitoring screen sessions (6 panes)
 	for _, session := range sessions {
 		paneCount, err := GetPaneCount(session.Name)
@@ -111,10 +182,8 @@ func DetectDefaultSession() (string, error) {
 
 	// Find base names for individual session mode
 	individualSessions := map[string]bool{}
-	re := regexp.MustCompile(`-(po|manager|dev[1-4])$`)
 	for _, session := range sessions {
-		if re.MatchString(session.Name) {
-			baseName := re.ReplaceAllString(session.Name, "")
+		if baseName, _, ok := topology.SplitAgentSession(session.Name); ok {
 			individualSessions[baseName] = true
 		}
 	}
@@ -130,3 +199,93 @@ func DetectDefaultSession() (string, error) {
 
 	return "", fmt.Errorf("no AI agent related sessions found")
 }
+
+func GetSessionOption(sessionName, option string) string {
+	cmd := sessionCommand(sessionName, "show-options", "-q", "-v", "-t", sessionName, option)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GetPaneAgents returns pane ID → agent name for all panes in the session,
+// using the @cca_agent pane option set by start-agents (empty for unlabeled panes)
+func GetPaneAgents(sessionName string) (map[string]string, error) {
+	format := "#{pane_id}\t#{" + manifest.OptionAgent + "}"
+	cmd := sessionCommand(sessionName, "list-panes", "-s", "-t", sessionName, "-F", format)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pane list: %v", err)
+	}
+
+	paneAgents := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "\t", 2)
+		paneID := strings.TrimSpace(fields[0])
+		if paneID == "" {
+			continue
+		}
+		agentName := ""
+		if len(fields) == 2 {
+			agentName = strings.TrimSpace(fields[1])
+		}
+		paneAgents[paneID] = agentName
+	}
+	return paneAgents, nil
+}
+
+// sessionSockets caches the tmux server found for each running session
+var (
+	sessionSocketsMu sync.Mutex
+	sessionSockets   = map[string]string{}
+)
+
+// SessionSocket returns the socket of the tmux server running the session ("" for the default
+// server): the session's dedicated server (start-agents DEDICATED_SOCKET), the server send-agent
+// was started for (CCA_TMUX_SOCKET) or the default server. Sessions that are not running resolve
+// to the server send-agent was started for.
+func SessionSocket(sessionName string) string {
+	if sessionName == "" {
+		return tmuxsocket.Selected()
+	}
+
+	sessionSocketsMu.Lock()
+	defer sessionSocketsMu.Unlock()
+	if socket, ok := sessionSockets[sessionName]; ok {
+		return socket
+	}
+
+	tried := map[string]bool{}
+	for _, socket := range []string{tmuxsocket.Name(sessionName), tmuxsocket.Selected(), ""} {
+		if tried[socket] {
+			continue
+		}
+		tried[socket] = true
+		if tmuxsocket.CommandOn(socket, "has-session", "-t", "="+sessionName).Run() == nil {
+			sessionSockets[sessionName] = socket
+			return socket
+		}
+	}
+	return tmuxsocket.Selected()
+}
+
+// sessionCommand returns a tmux command run against the server of the session
+func sessionCommand(sessionName string, args ...string) *exec.Cmd {
+	return tmuxsocket.CommandOn(SessionSocket(sessionName), args...)
+}
+
+// CurrentSession returns the session of the pane send-agent is running in, if any
+func CurrentSession() string {
+	pane := os.Getenv("TMUX_PANE")
+	if pane == "" {
+		return ""
+	}
+	cmd := tmuxsocket.CallerCommand("display-message", "-p", "-t", pane, "#{session_name}")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
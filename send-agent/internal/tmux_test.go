@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+func TestSessionSocket(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux is not installed")
+	}
+
+	// Without the dedicated prefix, so other send-agent processes never pick the test server up
+	socket := fmt.Sprintf("session-socket-test-%d-%d", os.Getpid(), time.Now().UnixNano())
+	sessionName := "session-socket-test"
+	require.NoError(t, tmuxsocket.CommandOn(socket, "new-session", "-d", "-s", sessionName).Run())
+	t.Cleanup(func() {
+		_ = tmuxsocket.CommandOn(socket, "kill-server").Run()
+		_ = os.Remove(filepath.Join(tmuxsocket.Dir(), socket))
+	})
+
+	// Sessions that are not running resolve to the server send-agent was started for
+	t.Setenv(tmuxsocket.EnvSocket, "")
+	assert.Equal(t, "", SessionSocket(sessionName))
+
+	t.Setenv(tmuxsocket.EnvSocket, socket)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, socket, SessionSocket(sessionName))
+		}()
+	}
+	wg.Wait()
+
+	// The server found for a session is kept, whatever server is selected afterwards
+	t.Setenv(tmuxsocket.EnvSocket, "")
+	assert.Equal(t, socket, SessionSocket(sessionName))
+	assert.True(t, HasSession(sessionName))
+	count, err := GetPaneCount(sessionName)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/shivase/cloud-code-agents/send-agent/internal"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // Command definition
@@ -18,21 +27,72 @@ var (
 
 A tool for sending messages to AI agents running on tmux sessions.
 Supports both integrated monitoring screen and individual session modes.
+Messages to a busy agent are queued and delivered as soon as it is idle.
 
 Available agents:
   po      - Product Owner (Product Manager)
   manager - Project Manager (Flexible team management)
   dev1    - Execution Agent 1 (Flexible role assignment)
-  dev2    - Execution Agent 2 (Flexible role assignment)
-  dev3    - Execution Agent 3 (Flexible role assignment)
-  dev4    - Execution Agent 4 (Flexible role assignment)`,
+  ...
+  devN    - Execution Agent N (N = DEV_COUNT of the team, default 4)
+
+The message can be given as argument, read from a file (--file) or from stdin ("-").
+It may start with a front-matter block carrying metadata such as priority and task:
+  ---
+  priority: high
+  task: AUTH-12
+  ---
+Each message starts with a header such as [from: dev2 | msg: 42 | reply-to: manager]
+identifying the sender (detected from $TMUX_PANE). "send-agent reply" answers the
+sender of the last message received by the calling agent.
+"send-agent ask" sends a question and blocks until the agent answers it with
+"send-agent reply --to <id>"; the reply is printed to stdout (exit code 6 on timeout).
+Messages not allowed by the routing policy (ROUTE_<ROLE> entries in agents.conf)
+are rejected and logged; send-agent then exits with code 4.
+Messages continuing a loop between agents (ping-pong, repeated content, message
+storms) are blocked with exit code 5 and the PO is notified; use --force to override.
+
+Messages can be scheduled with --at 14:00, --in 30m or repeated with --every 1h.
+They are stored per session and sent by a scheduler process that start-agents
+launches with the team ("send-agent schedule list" shows pending messages).
+
+Messages larger than --max-inline bytes are saved to the session's shared directory
+and the agent receives a pointer to the file instead.
+
+Several agents can be addressed at once; the message is delivered concurrently:
+  dev1,dev3 - the listed agents
+  devs      - all developers
+  all       - every agent of the session
+  <group>   - a group defined as GROUP_<NAME>=dev1,dev2 in agents.conf
+
+Agents of another team session are addressed as <session>:<agent> (e.g. team-b:manager,
+or team-b:devs). The session must have been launched by start-agents; the message is
+logged in both sessions and "send-agent reply" answers across sessions as well.`,
 		Example: `  send-agent --session myproject manager "Please start a new project"
   send-agent --session ai-team dev1 "[As Marketing Lead] Please conduct market research"
   send-agent --reset dev1 "[As Data Analyst] Please create a report"
+  send-agent devs "Sprint 3 starts now, check your tasks"  (all developers)
+  send-agent dev1,dev3 "Please review each other's PRs"
+  send-agent backend:manager "The login form is ready for the API"  (another team's session)
+  send-agent dev2 --file spec.md  (send the content of spec.md)
+  make test 2>&1 | send-agent manager -  (send stdin)
+  send-agent --interrupt dev2 "Stop and fix the build first"  (do not wait while dev2 is busy)
   send-agent manager "message"  (use default session)
   send-agent list myproject      (list agents in myproject session)
-  send-agent list-sessions       (show all sessions)`,
-		Args: cobra.ExactArgs(2),
+  send-agent list-sessions       (show all sessions)
+  send-agent reply "Done, PR #12 is ready"  (answer the last message, run from an agent pane)
+  answer=$(send-agent ask dev1 "Which port does the API use?" --timeout 10m)
+  send-agent shell myproject     (interactive prompt: @dev1 message, /status, /tail dev2)
+  send-agent tail dev2 -n 100 --follow  (watch what dev2 is doing)
+  send-agent grep -i "panic|FAIL"  (search the scrollback of every agent)
+  send-agent keys dev2 Escape     (stop a runaway agent)
+  send-agent slash dev2 /compact  (compact dev2's context)
+  send-agent status myproject    (show agent states)
+  send-agent list-sessions -o json  (machine-readable session list)
+  send-agent history --agent dev1 --since 2h  (show recent messages of dev1)
+  send-agent devs --every 1h "Status report please"  (recurring nudge)
+  send-agent manager --at 17:30 "Time-box check: wrap up today's tasks"`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: executeMainCommand,
 	}
 
@@ -48,59 +108,833 @@ Available agents:
 		Short: "Display list of all available sessions",
 		RunE:  executeListSessionsCommand,
 	}
+
+	deliveryDaemonCmd = &cobra.Command{
+		Use:    internal.DaemonCommand + " [session-name]",
+		Short:  "Deliver queued messages of a session (started automatically)",
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		RunE:   executeDeliveryDaemonCommand,
+	}
+
+	replyCmd = &cobra.Command{
+		Use:   "reply [message]",
+		Short: "Answer the agent that sent the last message to the calling agent",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeReplyCommand,
+	}
+
+	scheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage scheduled and recurring messages",
+	}
+
+	scheduleListCmd = &cobra.Command{
+		Use:   "list [session-name]",
+		Short: "Display the scheduled messages of a session",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeScheduleListCommand,
+	}
+
+	scheduleCancelCmd = &cobra.Command{
+		Use:   "cancel [id]",
+		Short: "Remove a scheduled message",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeScheduleCancelCommand,
+	}
+
+	scheduleStartCmd = &cobra.Command{
+		Use:   "start [session-name]",
+		Short: "Start the scheduler of a session (done by start-agents at launch)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeScheduleStartCommand,
+	}
+
+	schedulerDaemonCmd = &cobra.Command{
+		Use:    internal.SchedulerCommand + " [session-name]",
+		Short:  "Send scheduled messages of a session (started automatically)",
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		RunE:   executeSchedulerDaemonCommand,
+	}
+
+	askCmd = &cobra.Command{
+		Use:   "ask [agent] [message]",
+		Short: "Send a question and wait for the agent's reply (printed to stdout)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  executeAskCommand,
+	}
+
+	shellCmd = &cobra.Command{
+		Use:   "shell [session-name]",
+		Short: "Talk to the team interactively (@agent message, /status, /tail, /broadcast)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeShellCommand,
+	}
+
+	tailCmd = &cobra.Command{
+		Use:   "tail [agent]",
+		Short: "Display the last lines of an agent's pane (--follow to stream new output)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeTailCommand,
+	}
+
+	captureCmd = &cobra.Command{
+		Use:   "capture [agent]",
+		Short: "Display the whole pane content of an agent, scrollback included",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeCaptureCommand,
+	}
+
+	grepCmd = &cobra.Command{
+		Use:   "grep [pattern]",
+		Short: "Search the scrollback of all agents of a session (regular expression)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  executeGrepCommand,
+	}
+
+	keysCmd = &cobra.Command{
+		Use:   "keys [agent] [key...]",
+		Short: "Send raw tmux keys to an agent (e.g. Escape Escape, C-c, Enter)",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  executeKeysCommand,
+	}
+
+	slashCmd = &cobra.Command{
+		Use:   "slash [agent] [command]",
+		Short: "Run a Claude slash command in an agent (e.g. /compact, /clear, /cost)",
+		Args:  cobra.MinimumNArgs(2),
+		RunE:  executeSlashCommand,
+	}
+
+	historyCmd = &cobra.Command{
+		Use:   "history [session-name]",
+		Short: "Display the message log of a session",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeHistoryCommand,
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status [session-name]",
+		Short: "Display the state of each agent (idle, busy, awaiting_input, rate_limited, exited)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  executeStatusCommand,
+	}
 )
 
 func init() {
-	rootCmd.Flags().StringP("session", "s", "", "Use specified session name")
-	rootCmd.Flags().BoolP("reset", "r", false, "Clear previous role definition and send new instruction")
+	addSendFlags(rootCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(listSessionsCmd)
+
+	outputUsage := "Output format (" + strings.Join(internal.OutputFormats, "|") + ")"
+	listCmd.Flags().StringP("output", "o", internal.OutputTable, outputUsage)
+	listSessionsCmd.Flags().StringP("output", "o", internal.OutputTable, outputUsage)
+	statusCmd.Flags().StringP("output", "o", internal.OutputTable, outputUsage)
+	rootCmd.AddCommand(statusCmd)
+
+	historyCmd.Flags().StringP("agent", "a", "", "Only messages sent by or to the agent")
+	historyCmd.Flags().String("since", "", "Only messages after the time (RFC3339, YYYY-MM-DD [HH:MM] or duration like 2h)")
+	historyCmd.Flags().String("until", "", "Only messages before the time (same formats as --since)")
+	historyCmd.Flags().StringP("grep", "g", "", "Only messages containing the text (case-insensitive)")
+	historyCmd.Flags().IntP("limit", "n", 0, "Show only the last N messages (0 = all)")
+	historyCmd.Flags().Bool("json", false, "Output raw JSON lines")
+	rootCmd.AddCommand(historyCmd)
+
+	rootCmd.Flags().String("at", "", "Send at the given time instead of now (HH:MM, YYYY-MM-DD HH:MM or RFC3339)")
+	rootCmd.Flags().String("in", "", "Send after the given delay (e.g. 30m, 2h)")
+	rootCmd.Flags().String("every", "", "Send repeatedly at this interval (e.g. 1h), starting at --at/--in or one interval from now")
+
+	scheduleListCmd.Flags().StringP("output", "o", internal.OutputTable, outputUsage)
+	scheduleCancelCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	scheduleCmd.AddCommand(scheduleListCmd, scheduleCancelCmd, scheduleStartCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(schedulerDaemonCmd)
+
+	addSendFlags(replyCmd)
+	replyCmd.Flags().String("to", "", "ID of the message to answer (default: the last message received)")
+	rootCmd.AddCommand(replyCmd)
+
+	rootCmd.AddCommand(shellCmd)
+
+	tailCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	tailCmd.Flags().IntP("lines", "n", internal.DefaultTailLines, "Number of lines to show")
+	tailCmd.Flags().BoolP("follow", "f", false, "Keep printing new output until interrupted")
+	captureCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	captureCmd.Flags().Bool("visible", false, "Only the visible screen, without scrollback")
+	grepCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	grepCmd.Flags().StringSliceP("agent", "a", nil, "Only search these agents (repeatable or comma separated)")
+	grepCmd.Flags().BoolP("ignore-case", "i", false, "Case-insensitive match")
+	grepCmd.Flags().StringP("output", "o", internal.OutputTable, outputUsage)
+	rootCmd.AddCommand(tailCmd, captureCmd, grepCmd)
+
+	keysCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	keysCmd.Flags().BoolP("literal", "l", false, "Type the arguments as text instead of key names")
+	slashCmd.Flags().StringP("session", "s", "", "Use specified session name")
+	slashCmd.Flags().BoolP("interrupt", "i", false, "Stop the agent's current work ("+internal.InterruptKey+") before running the command")
+	rootCmd.AddCommand(keysCmd, slashCmd)
+
+	addSendFlags(askCmd)
+	askCmd.Flags().Duration("timeout", internal.DefaultAskTimeout, "How long to wait for the reply (0 = no limit)")
+	rootCmd.AddCommand(askCmd)
+	rootCmd.AddCommand(deliveryDaemonCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Printf("❌ Error: %v\n", err)
+		if errors.Is(err, internal.ErrRouteDenied) {
+			os.Exit(internal.ExitRouteDenied)
+		}
+		if errors.Is(err, internal.ErrLoopDetected) {
+			os.Exit(internal.ExitLoopDetected)
+		}
+		if errors.Is(err, internal.ErrAskTimeout) {
+			os.Exit(internal.ExitAskTimeout)
+		}
+		if errors.Is(err, internal.ErrDeliveryUnconfirmed) {
+			os.Exit(internal.ExitDeliveryUnconfirmed)
+		}
 		os.Exit(1)
 	}
 }
 
 // Command execution functions
 func executeMainCommand(cmd *cobra.Command, args []string) error {
-	agent := args[0]
-	message := args[1]
-	sessionName, _ := cmd.Flags().GetString("session")
-	resetContext, _ := cmd.Flags().GetBool("reset")
+	_, agent := topology.SplitAddress(args[0])
 
-	if !internal.IsValidAgent(agent) {
+	multi := topology.IsMultiRecipient(agent)
+	if !multi && !internal.IsValidAgent(agent) {
 		return fmt.Errorf("invalid agent name '%s'", agent)
 	}
 
-	if sessionName == "" {
-		detectedSession, err := internal.DetectDefaultSession()
-		if err != nil {
-			return fmt.Errorf("no available AI agent sessions found\n💡 List sessions: %s list-sessions\n💡 Create new session: start-ai-agent [session-name]", cmd.Root().Name())
+	sessionName, agent, err := resolveAddress(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	sender, err := newMessageSender(cmd, sessionName, agent, args[1:])
+	if err != nil {
+		return err
+	}
+
+	if isScheduled(cmd) {
+		return scheduleMessage(cmd, sender)
+	}
+	if multi {
+		return executeBroadcast(sender, agent)
+	}
+	return sender.Send()
+}
+
+// isScheduled reports whether any of the scheduling flags was given
+func isScheduled(cmd *cobra.Command) bool {
+	for _, name := range []string{"at", "in", "every"} {
+		if value, _ := cmd.Flags().GetString(name); value != "" {
+			return true
 		}
-		sessionName = detectedSession
-		fmt.Printf("🔍 Using default session '%s'\n", sessionName)
 	}
+	return false
+}
 
-	sender := &internal.MessageSender{
+// scheduleMessage stores the message in the session's schedule and makes sure the scheduler runs
+func scheduleMessage(cmd *cobra.Command, sender *internal.MessageSender) error {
+	at, _ := cmd.Flags().GetString("at")
+	in, _ := cmd.Flags().GetString("in")
+	every, _ := cmd.Flags().GetString("every")
+
+	next, err := internal.FirstRun(at, in, every, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if topology.IsMultiRecipient(sender.Agent) {
+		if _, err := internal.ResolveRecipients(sender.SessionName, sender.Agent); err != nil {
+			return err
+		}
+	}
+
+	msg := &internal.ScheduledMessage{
+		From:         sender.From,
+		ReplyTo:      sender.ReplyTo,
+		To:           sender.Agent,
+		Message:      sender.Message,
+		Meta:         sender.Meta,
+		File:         sender.File,
+		ResetContext: sender.ResetContext,
+		NoVerify:     sender.NoVerify,
+		Interrupt:    sender.Interrupt,
+		Next:         next,
+		Every:        every,
+	}
+	if err := internal.AddScheduled(sender.SessionName, msg); err != nil {
+		return err
+	}
+
+	fmt.Printf("⏰ Scheduled message %s to %s at %s", msg.ID, msg.To, next.Format("2006-01-02 15:04:05"))
+	if every != "" {
+		fmt.Printf(", then every %s", every)
+	}
+	fmt.Printf(" (Session: %s)\n", sender.SessionName)
+
+	if err := internal.EnsureScheduler(sender.SessionName); err != nil {
+		fmt.Printf("⚠️ %v; the message is sent once the scheduler runs (send-agent schedule start %s)\n", err, sender.SessionName)
+	}
+	return nil
+}
+
+func executeReplyCommand(cmd *cobra.Command, args []string) error {
+	sessionName, err := resolveSession(cmd)
+	if err != nil {
+		return err
+	}
+
+	id, _ := cmd.Flags().GetString("to")
+	from := internal.DetectSender()
+	if from == internal.SenderUser && id == "" {
+		return fmt.Errorf("reply must be run from an agent pane (the sender is detected from $TMUX_PANE); use --to <id> to answer a specific message")
+	}
+
+	var last *internal.HistoryRecord
+	if id != "" {
+		last, err = internal.FindMessage(sessionName, id)
+	} else {
+		last, err = internal.LastReceived(sessionName, from)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Questions from "send-agent ask" are answered to the waiting asker, not pasted into a pane
+	if internal.IsAwaitingReply(sessionName, last.ID) {
+		return answerAsk(cmd, sessionName, from, last, args)
+	}
+
+	to, err := last.ReplyAgent()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("↩️ Replying to %s (msg %s)\n", to, last.ID)
+
+	// Messages from another team are answered in the sender's session
+	sessionName, to, err = internal.ResolveAddress(to, sessionName)
+	if err != nil {
+		return err
+	}
+
+	sender, err := newMessageSender(cmd, sessionName, to, args)
+	if err != nil {
+		return err
+	}
+	sender.InReplyTo = last.ID
+	return sender.Send()
+}
+
+// answerAsk hands the reply to the asker blocked in "send-agent ask"
+func answerAsk(cmd *cobra.Command, sessionName, from string, question *internal.HistoryRecord, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	raw, err := internal.ReadMessageInput(args, file, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	force, _ := cmd.Flags().GetBool("force")
+	reply, err := internal.AnswerAsk(sessionName, from, question, strings.TrimSpace(raw), force)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("↩️ Answered msg %s of %s (msg %s)\n", question.ID, question.ReplyAddress(), reply.ID)
+	return nil
+}
+
+func executeAskCommand(cmd *cobra.Command, args []string) error {
+	// stdout carries only the reply so scripts can capture it
+	cmd.SetOut(os.Stderr)
+
+	if _, agent := topology.SplitAddress(args[0]); !internal.IsValidAgent(agent) {
+		return fmt.Errorf("invalid agent name '%s' (ask addresses a single agent)", agent)
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	sessionName, agent, err := resolveAddress(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	sender, err := newMessageSender(cmd, sessionName, agent, args[1:])
+	if err != nil {
+		return err
+	}
+	sender.Out = os.Stderr
+
+	reply, err := sender.Ask(timeout)
+	if err != nil {
+		return err
+	}
+	fmt.Println(reply.Message)
+	return nil
+}
+
+// addSendFlags registers the flags shared by all commands that send a message
+func addSendFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("session", "s", "", "Use specified session name")
+	cmd.Flags().BoolP("reset", "r", false, "Run /clear and re-send the agent's role instructions before the message")
+	cmd.Flags().BoolP("interrupt", "i", false, "Interrupt the agent's current work (Ctrl+C) instead of queueing while it is busy")
+	cmd.Flags().Bool("no-verify", false, "Skip delivery verification (exit code 3 is used when delivery cannot be confirmed)")
+	cmd.Flags().StringP("file", "f", "", "Read the message from a file ('-' for stdin)")
+	cmd.Flags().Int("max-inline", internal.DefaultMaxInlineSize, "Largest message in bytes pasted directly; larger ones are sent as a file pointer (0 = no limit)")
+	cmd.Flags().String("reply-to", "", "Agent that replies should be sent to (default: the sender)")
+	cmd.Flags().Bool("force", false, "Send even if a message loop or storm is detected")
+}
+
+// resolveSession returns the --session flag or the detected default session
+func resolveSession(cmd *cobra.Command) (string, error) {
+	sessionName, _ := cmd.Flags().GetString("session")
+	if sessionName != "" {
+		return sessionName, nil
+	}
+
+	detectedSession, err := internal.DetectDefaultSession()
+	if errors.Is(err, internal.ErrMultipleSessions) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("no available AI agent sessions found\n💡 List sessions: %s list-sessions\n💡 Create new session: start-ai-agent [session-name]", cmd.Root().Name())
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "🔍 Using default session '%s'\n", detectedSession)
+	return detectedSession, nil
+}
+
+// resolveAddress returns the session and recipient of an address: "<session>:<agent>" names
+// another team session, otherwise --session or the detected default session is used
+func resolveAddress(cmd *cobra.Command, address string) (string, string, error) {
+	if internal.IsQualifiedAddress(address) {
+		return internal.ResolveAddress(address, "")
+	}
+	sessionName, err := resolveSession(cmd)
+	if err != nil {
+		return "", "", err
+	}
+	return sessionName, address, nil
+}
+
+// resolveAgentAddress validates an address naming a single agent and returns its session and agent
+func resolveAgentAddress(cmd *cobra.Command, address string) (string, string, error) {
+	if _, agent := topology.SplitAddress(address); !internal.IsValidAgent(agent) {
+		return "", "", fmt.Errorf("invalid agent name '%s'", agent)
+	}
+	return resolveAddress(cmd, address)
+}
+
+// newMessageSender reads the message (argument, --file or stdin) and builds the sender from the send flags
+func newMessageSender(cmd *cobra.Command, sessionName, agent string, messageArgs []string) (*internal.MessageSender, error) {
+	resetContext, _ := cmd.Flags().GetBool("reset")
+	noVerify, _ := cmd.Flags().GetBool("no-verify")
+	interrupt, _ := cmd.Flags().GetBool("interrupt")
+	file, _ := cmd.Flags().GetString("file")
+	maxInline, _ := cmd.Flags().GetInt("max-inline")
+	replyTo, _ := cmd.Flags().GetString("reply-to")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if replyTo != "" && !topology.IsAddress(replyTo) {
+		return nil, fmt.Errorf("invalid reply-to agent '%s'", replyTo)
+	}
+
+	raw, err := internal.ReadMessageInput(messageArgs, file, os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	from := internal.SenderAddress(internal.DetectSender(), sessionName)
+	message, err := internal.PrepareMessage(sessionName, from, raw, maxInline)
+	if err != nil {
+		return nil, err
+	}
+	if message.File != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "📄 Message is too long to paste, saved to %s\n", message.File)
+	}
+
+	return &internal.MessageSender{
+		From:         from,
+		ReplyTo:      replyTo,
 		SessionName:  sessionName,
+		Socket:       internal.SessionSocket(sessionName),
 		Agent:        agent,
-		Message:      message,
+		Message:      message.Text,
+		Meta:         message.Meta,
+		File:         message.File,
 		ResetContext: resetContext,
+		NoVerify:     noVerify,
+		Interrupt:    interrupt,
+		Force:        force,
+	}, nil
+}
+
+func executeBroadcast(template *internal.MessageSender, spec string) error {
+	recipients, err := internal.ResolveRecipients(template.SessionName, spec)
+	if err != nil {
+		return err
 	}
 
-	return sender.Send()
+	fmt.Printf("📣 Sending to %s (Session: %s)...\n", strings.Join(recipients, ", "), template.SessionName)
+	results := internal.Broadcast(*template, recipients)
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("\n── %s ──\n%s", r.Agent, r.Log)
+		}
+	}
+	fmt.Println()
+	return internal.WriteBroadcastReport(os.Stdout, results)
 }
 
 func executeListCommand(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := internal.ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
 	sessionName := args[0]
-	manager := &internal.SessionManager{}
-	return manager.ShowAgentsForSession(sessionName)
+	if output == internal.OutputTable {
+		manager := &internal.SessionManager{}
+		return manager.ShowAgentsForSession(sessionName)
+	}
+
+	status, err := internal.CollectSessionStatus(sessionName)
+	if err != nil {
+		return err
+	}
+	return internal.WriteOutput(os.Stdout, output, status)
 }
 
 func executeListSessionsCommand(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := internal.ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
 	manager := &internal.SessionManager{}
-	return manager.ListAllSessions()
+	if output == internal.OutputTable {
+		return manager.ListAllSessions()
+	}
+
+	list, err := manager.CollectSessions()
+	if err != nil {
+		return err
+	}
+	return internal.WriteOutput(os.Stdout, output, list)
+}
+
+func executeStatusCommand(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := internal.ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
+	var sessionName string
+	if len(args) > 0 {
+		sessionName = args[0]
+	} else {
+		detectedSession, err := internal.DetectDefaultSession()
+		if err != nil {
+			return err
+		}
+		sessionName = detectedSession
+	}
+
+	status, err := internal.CollectSessionStatus(sessionName)
+	if err != nil {
+		return err
+	}
+
+	return internal.WriteOutput(os.Stdout, output, status)
+}
+
+func executeHistoryCommand(cmd *cobra.Command, args []string) error {
+	agent, _ := cmd.Flags().GetString("agent")
+	since, _ := cmd.Flags().GetString("since")
+	until, _ := cmd.Flags().GetString("until")
+	text, _ := cmd.Flags().GetString("grep")
+	limit, _ := cmd.Flags().GetInt("limit")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	if agent != "" && !internal.IsValidAgent(agent) && agent != internal.SenderUser {
+		return fmt.Errorf("invalid agent name '%s'", agent)
+	}
+
+	filter := internal.HistoryFilter{Agent: agent, Text: text}
+	now := time.Now()
+	var err error
+	if since != "" {
+		if filter.Since, err = internal.ParseHistoryTime(since, now); err != nil {
+			return err
+		}
+	}
+	if until != "" {
+		if filter.Until, err = internal.ParseHistoryTime(until, now); err != nil {
+			return err
+		}
+	}
+
+	var sessionName string
+	if len(args) > 0 {
+		sessionName = args[0]
+	} else {
+		detectedSession, err := internal.DetectDefaultSession()
+		if err != nil {
+			return err
+		}
+		sessionName = detectedSession
+	}
+
+	records, err := internal.LoadHistory(sessionName, filter)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("📭 No messages found (log: %s)\n", internal.HistoryPath(sessionName))
+		return nil
+	}
+	internal.WriteHistory(os.Stdout, records)
+	return nil
+}
+
+func executeDeliveryDaemonCommand(cmd *cobra.Command, args []string) error {
+	return internal.RunDeliveryDaemon(args[0])
+}
+
+// sessionFromArgs returns the session given as argument or the detected default session
+func sessionFromArgs(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	return internal.DetectDefaultSession()
+}
+
+func executeScheduleListCommand(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+	if err := internal.ValidateOutputFormat(output); err != nil {
+		return err
+	}
+
+	sessionName, err := sessionFromArgs(args)
+	if err != nil {
+		return err
+	}
+
+	messages, err := internal.LoadSchedule(sessionName)
+	if err != nil {
+		return err
+	}
+	if messages == nil {
+		messages = []*internal.ScheduledMessage{}
+	}
+	return internal.WriteOutput(os.Stdout, output, &internal.ScheduleList{Session: sessionName, Messages: messages})
+}
+
+func executeScheduleCancelCommand(cmd *cobra.Command, args []string) error {
+	sessionName, err := resolveSession(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := internal.CancelScheduled(sessionName, args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️ Scheduled message %s cancelled (Session: %s)\n", args[0], sessionName)
+	return nil
+}
+
+func executeScheduleStartCommand(cmd *cobra.Command, args []string) error {
+	sessionName, err := sessionFromArgs(args)
+	if err != nil {
+		return err
+	}
+	if !internal.HasSession(sessionName) {
+		return fmt.Errorf("session '%s' not found", sessionName)
+	}
+
+	if err := internal.EnsureScheduler(sessionName); err != nil {
+		return err
+	}
+	fmt.Printf("⏰ Scheduler running for session '%s' (log: %s)\n", sessionName, filepath.Join(internal.SessionDir(sessionName), internal.SchedulerLogFile))
+	return nil
+}
+
+func executeShellCommand(cmd *cobra.Command, args []string) error {
+	sessionName, err := sessionFromArgs(args)
+	if err != nil {
+		return err
+	}
+	if !internal.HasSession(sessionName) {
+		return fmt.Errorf("session '%s' not found", sessionName)
+	}
+
+	editor := internal.NewLineEditor(os.Stdin, os.Stdout)
+	if err := editor.EnableRawMode(os.Stdin); err == nil {
+		defer editor.Close()
+	}
+	return internal.NewShell(sessionName, editor).Run()
+}
+
+// agentTargetFromArgs validates the agent argument and returns the tmux server and target of its pane
+func agentTargetFromArgs(cmd *cobra.Command, address string) (string, string, error) {
+	sessionName, agent, err := resolveAgentAddress(cmd, address)
+	if err != nil {
+		return "", "", err
+	}
+	target, err := internal.AgentTarget(sessionName, agent)
+	if err != nil {
+		return "", "", err
+	}
+	return internal.SessionSocket(sessionName), target, nil
+}
+
+func executeTailCommand(cmd *cobra.Command, args []string) error {
+	lines, _ := cmd.Flags().GetInt("lines")
+	follow, _ := cmd.Flags().GetBool("follow")
+
+	// Keep stdout for pane content only
+	cmd.SetOut(os.Stderr)
+	socket, target, err := agentTargetFromArgs(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !follow {
+		content, err := internal.ReadPaneLines(socket, target, lines)
+		if err != nil {
+			return err
+		}
+		for _, line := range internal.LastLines(content, lines) {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+	return internal.FollowPane(os.Stdout, socket, target, lines, stop)
+}
+
+func executeCaptureCommand(cmd *cobra.Command, args []string) error {
+	visible, _ := cmd.Flags().GetBool("visible")
+
+	cmd.SetOut(os.Stderr)
+	socket, target, err := agentTargetFromArgs(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	scrollback := internal.ScrollbackAll
+	if visible {
+		scrollback = 0
+	}
+	content, err := internal.ReadPaneLines(socket, target, scrollback)
+	if err != nil {
+		return err
+	}
+	for _, line := range content {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func executeGrepCommand(cmd *cobra.Command, args []string) error {
+	agents, _ := cmd.Flags().GetStringSlice("agent")
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
+	output, _ := cmd.Flags().GetString("output")
+	if err := internal.ValidateOutputFormat(output); err != nil {
+		return err
+	}
+	for _, agent := range agents {
+		if !internal.IsValidAgent(agent) {
+			return fmt.Errorf("invalid agent name '%s'", agent)
+		}
+	}
+
+	expr := args[0]
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", args[0], err)
+	}
+
+	cmd.SetOut(os.Stderr)
+	sessionName, err := resolveSession(cmd)
+	if err != nil {
+		return err
+	}
+
+	matches, err := internal.GrepPanes(sessionName, agents, pattern)
+	if err != nil {
+		return err
+	}
+	if matches == nil {
+		matches = []internal.GrepMatch{}
+	}
+	return internal.WriteOutput(os.Stdout, output, &internal.GrepResult{Session: sessionName, Pattern: args[0], Matches: matches})
+}
+
+func executeKeysCommand(cmd *cobra.Command, args []string) error {
+	literal, _ := cmd.Flags().GetBool("literal")
+
+	sessionName, agent, err := resolveAgentAddress(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	sender := &internal.MessageSender{From: internal.SenderAddress(internal.DetectSender(), sessionName), SessionName: sessionName, Agent: agent}
+	return sender.SendRawKeys(args[1:], literal)
+}
+
+func executeSlashCommand(cmd *cobra.Command, args []string) error {
+	if _, agent := topology.SplitAddress(args[0]); !internal.IsValidAgent(agent) {
+		return fmt.Errorf("invalid agent name '%s'", agent)
+	}
+	interrupt, _ := cmd.Flags().GetBool("interrupt")
+
+	command := strings.Join(args[1:], " ")
+	if err := internal.ValidateSlashCommand(command); err != nil {
+		return err
+	}
+
+	sessionName, agent, err := resolveAddress(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	sender := &internal.MessageSender{
+		From:        internal.SenderAddress(internal.DetectSender(), sessionName),
+		SessionName: sessionName,
+		Agent:       agent,
+		Message:     command,
+		Interrupt:   interrupt,
+	}
+	return sender.SendSlashCommand()
+}
+
+func executeSchedulerDaemonCommand(cmd *cobra.Command, args []string) error {
+	return internal.RunScheduler(args[0])
 }
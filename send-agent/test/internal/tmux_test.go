@@ -65,7 +65,7 @@ func TestShowPanes_Structure(t *testing.T) {
 
 func TestTmuxSendKeys_Structure(t *testing.T) {
 	t.Run("Non-existing session", func(t *testing.T) {
-		err := internal.TmuxSendKeys("non-existing-session-12345:0", "echo hello")
+		err := internal.TmuxSendKeys("", "non-existing-session-12345:0", "echo hello")
 		// Verify that send-keys returns an error even for non-existing sessions
 		assert.Error(t, err)
 	})
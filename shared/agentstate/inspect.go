@@ -0,0 +1,143 @@
+package agentstate
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// Snapshot is the observed state of one pane
+type Snapshot struct {
+	PaneID  string `json:"pane_id"`
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+	State   State  `json:"state"`
+}
+
+// Inspect classifies the agent running in the target pane (pane ID or session:window.pane)
+// of the selected tmux server
+func Inspect(target string) (*Snapshot, error) {
+	return InspectOn(tmuxsocket.Selected(), target)
+}
+
+// InspectOn classifies the agent running in the target pane of the server of the socket
+// ("" for the default server)
+func InspectOn(socket, target string) (*Snapshot, error) {
+	cmd := tmuxsocket.CommandOn(socket, "display-message", "-p", "-t", target, "#{pane_id}\t#{pane_pid}\t#{pane_current_command}\t#{pane_dead}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pane information for %s: %w", target, err)
+	}
+
+	fields := strings.Split(strings.TrimRight(string(output), "\n"), "\t")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("unexpected pane information for %s: %q", target, string(output))
+	}
+
+	snapshot := &Snapshot{PaneID: fields[0], Command: fields[2]}
+	snapshot.PID, _ = strconv.Atoi(fields[1])
+
+	proc := Process{
+		Dead:    fields[3] == "1",
+		Command: fields[2],
+	}
+	if !proc.Dead {
+		proc.ClaudeRunning = isClaudeCommand(proc.Command) || claudeInProcessTree(snapshot.PID)
+	}
+
+	content := ""
+	if !proc.Dead {
+		captured, err := tmuxsocket.CommandOn(socket, "capture-pane", "-p", "-J", "-t", target).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture pane %s: %w", target, err)
+		}
+		content = string(captured)
+	}
+
+	snapshot.State = Classify(proc, content)
+	return snapshot, nil
+}
+
+// isClaudeCommand reports whether a process name or command line belongs to Claude CLI
+func isClaudeCommand(command string) bool {
+	for _, field := range strings.Fields(command) {
+		base := filepath.Base(field)
+		if base == "claude" || strings.HasPrefix(base, "claude-") || strings.Contains(field, "@anthropic-ai/claude-code") {
+			return true
+		}
+	}
+	return false
+}
+
+// claudeInProcessTree reports whether a Claude CLI process runs below the given pid
+func claudeInProcessTree(rootPID int) bool {
+	if rootPID <= 0 {
+		return false
+	}
+
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,args=").Output()
+	if err != nil {
+		return false
+	}
+
+	return treeContains(parseProcessTable(string(output)), rootPID, isClaudeCommand)
+}
+
+// processEntry is one line of the process table
+type processEntry struct {
+	pid  int
+	ppid int
+	args string
+}
+
+// parseProcessTable parses "pid ppid args" lines
+func parseProcessTable(output string) []processEntry {
+	var entries []processEntry
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err1 := strconv.Atoi(fields[0])
+		ppid, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		entries = append(entries, processEntry{pid: pid, ppid: ppid, args: strings.Join(fields[2:], " ")})
+	}
+	return entries
+}
+
+// treeContains reports whether the root process or any descendant matches
+func treeContains(entries []processEntry, rootPID int, match func(string) bool) bool {
+	children := make(map[int][]processEntry)
+	for _, entry := range entries {
+		if entry.pid == rootPID && match(entry.args) {
+			return true
+		}
+		children[entry.ppid] = append(children[entry.ppid], entry)
+	}
+
+	queue := []int{rootPID}
+	visited := map[int]bool{}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if visited[pid] {
+			continue
+		}
+		visited[pid] = true
+
+		for _, child := range children[pid] {
+			if match(child.args) {
+				return true
+			}
+			queue = append(queue, child.pid)
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package agentstate
+
+import (
+	"strings"
+)
+
+// State is the observed state of a Claude CLI agent in a tmux pane
+type State string
+
+// Agent states
+const (
+	// StateIdle means Claude is waiting at an empty or editable prompt
+	StateIdle State = "idle"
+	// StateBusy means Claude is working on a turn
+	StateBusy State = "busy"
+	// StateAwaitingInput means Claude is blocked on a confirmation dialog
+	StateAwaitingInput State = "awaiting_input"
+	// StateRateLimited means Claude reported a usage or rate limit
+	StateRateLimited State = "rate_limited"
+	// StateExited means Claude is not running and the pane fell back to a shell (or is dead)
+	StateExited State = "exited"
+	// StateUnknown means Claude is running but the screen could not be classified (e.g. still starting)
+	StateUnknown State = "unknown"
+)
+
+// Process describes what runs in a pane
+type Process struct {
+	// Dead is true when the pane's process has exited (remain-on-exit)
+	Dead bool
+	// Command is tmux's pane_current_command (the foreground process name)
+	Command string
+	// ClaudeRunning is true when a Claude CLI process was found in the pane's process tree
+	ClaudeRunning bool
+}
+
+// Screen markers used by Claude CLI
+var (
+	busyMarkers = []string{
+		"esc to interrupt",
+		"ctrl+c to interrupt",
+	}
+	rateLimitMarkers = []string{
+		"usage limit reached",
+		"rate limit reached",
+		"rate_limit_error",
+		"limit will reset",
+		"api error: 429",
+	}
+	confirmationMarkers = []string{
+		"do you want to proceed?",
+		"do you want to make this edit",
+		"do you want to create",
+		"do you want to allow",
+		"do you trust the files in this folder?",
+		"yes, and don't ask again",
+		"❯ 1. yes",
+	}
+)
+
+// Classify determines the agent state from the pane's process information and screen content
+func Classify(proc Process, content string) State {
+	if proc.Dead || !proc.ClaudeRunning {
+		return StateExited
+	}
+
+	// Only the bottom of the screen reflects the current state; older output may contain the same phrases
+	lower := strings.ToLower(tail(content, 20))
+
+	switch {
+	case containsAny(lower, confirmationMarkers):
+		return StateAwaitingInput
+	case containsAny(lower, busyMarkers):
+		return StateBusy
+	case containsAny(lower, rateLimitMarkers):
+		return StateRateLimited
+	}
+
+	if _, found := PromptText(content); found {
+		return StateIdle
+	}
+	return StateUnknown
+}
+
+// IsBusy reports whether the screen shows Claude's busy indicator
+func IsBusy(content string) bool {
+	return containsAny(strings.ToLower(content), busyMarkers)
+}
+
+// PromptText extracts the text of Claude's input box (the last "> " prompt) from pane content
+func PromptText(content string) (string, bool) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := stripBoxBorder(lines[i])
+		if strings.HasPrefix(line, ">") || strings.HasPrefix(line, "❯") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+
+	var parts []string
+	for i := start; i < len(lines); i++ {
+		if i > start && isBoxBorder(lines[i]) {
+			break
+		}
+		line := stripBoxBorder(lines[i])
+		if i == start {
+			line = strings.TrimSpace(strings.TrimLeft(line, ">❯"))
+		}
+		if line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " "), true
+}
+
+// stripBoxBorder removes the vertical borders of Claude's input box
+func stripBoxBorder(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "│")
+	line = strings.TrimSuffix(line, "│")
+	return strings.TrimSpace(line)
+}
+
+// isBoxBorder reports whether the line is a horizontal border of the input box
+func isBoxBorder(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	return strings.Trim(line, "─╭╮╰╯") == ""
+}
+
+// tail returns the last n non-empty lines of content
+func tail(content string, n int) string {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	for i := len(lines) - 1; i >= 0 && len(kept) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			kept = append([]string{lines[i]}, kept...)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package agentstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var running = Process{Command: "node", ClaudeRunning: true}
+
+const idleScreen = `
+> previous question
+
+⏺ Previous answer
+
+╭──────────────────────────────────────────────╮
+│ >                                            │
+╰──────────────────────────────────────────────╯
+  ? for shortcuts
+`
+
+const busyScreen = `
+> Please implement the login API
+
+✻ Thinking… (3s · ↑ 120 tokens · esc to interrupt)
+
+╭──────────────────────────────────────────────╮
+│ >                                            │
+╰──────────────────────────────────────────────╯
+`
+
+const confirmationScreen = `
+╭──────────────────────────────────────────────╮
+│ Bash command                                 │
+│   rm -rf build                               │
+│ Do you want to proceed?                      │
+│ ❯ 1. Yes                                     │
+│   2. No, and tell Claude what to do          │
+╰──────────────────────────────────────────────╯
+`
+
+const rateLimitScreen = `
+⏺ Claude usage limit reached. Your limit will reset at 3pm (Asia/Tokyo).
+
+╭──────────────────────────────────────────────╮
+│ >                                            │
+╰──────────────────────────────────────────────╯
+`
+
+const pendingPromptScreen = `
+╭──────────────────────────────────────────────╮
+│ > Please implement the login API and report  │
+│   back when done                             │
+╰──────────────────────────────────────────────╯
+  ? for shortcuts
+`
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		proc     Process
+		content  string
+		expected State
+	}{
+		{"Idle", running, idleScreen, StateIdle},
+		{"Idle with pending prompt text", running, pendingPromptScreen, StateIdle},
+		{"Busy", running, busyScreen, StateBusy},
+		{"Confirmation dialog", running, confirmationScreen, StateAwaitingInput},
+		{"Rate limited", running, rateLimitScreen, StateRateLimited},
+		{"Starting", running, "Welcome to Claude Code!\n", StateUnknown},
+		{"Shell", Process{Command: "zsh"}, "user@host:~$ ", StateExited},
+		{"Dead pane", Process{Dead: true, ClaudeRunning: true}, idleScreen, StateExited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Classify(tt.proc, tt.content))
+		})
+	}
+}
+
+func TestClassify_OldOutputIgnored(t *testing.T) {
+	content := "⏺ Claude usage limit reached.\n"
+	for i := 0; i < 30; i++ {
+		content += "line of later output\n"
+	}
+	content += idleScreen
+	assert.Equal(t, StateIdle, Classify(running, content))
+}
+
+func TestPromptText(t *testing.T) {
+	text, found := PromptText(pendingPromptScreen)
+	assert.True(t, found)
+	assert.Equal(t, "Please implement the login API and report back when done", text)
+
+	text, found = PromptText(busyScreen)
+	assert.True(t, found)
+	assert.Equal(t, "", text)
+
+	text, found = PromptText("> Done\n\n────────────\n> \n────────────\n")
+	assert.True(t, found)
+	assert.Equal(t, "", text)
+
+	_, found = PromptText("user@host:~$ ls\nfile.txt\n")
+	assert.False(t, found)
+}
+
+func TestIsBusy(t *testing.T) {
+	assert.True(t, IsBusy(busyScreen))
+	assert.False(t, IsBusy(idleScreen))
+}
+
+func TestIsClaudeCommand(t *testing.T) {
+	assert.True(t, isClaudeCommand("claude"))
+	assert.True(t, isClaudeCommand("/usr/local/bin/claude --dangerously-skip-permissions"))
+	assert.True(t, isClaudeCommand("node /usr/lib/node_modules/@anthropic-ai/claude-code/cli.js"))
+	assert.False(t, isClaudeCommand("zsh"))
+	assert.False(t, isClaudeCommand("vim claude.md"))
+}
+
+func TestTreeContains(t *testing.T) {
+	entries := parseProcessTable(`
+  100     1 -zsh
+  200   100 node /opt/claude-code/bin/claude
+  300   200 /bin/sh -c git status
+  400     1 /usr/bin/claude
+  bad line
+`)
+	assert.Len(t, entries, 4)
+	assert.True(t, treeContains(entries, 100, isClaudeCommand))
+	assert.True(t, treeContains(entries, 200, isClaudeCommand))
+	assert.False(t, treeContains(entries, 300, isClaudeCommand))
+}
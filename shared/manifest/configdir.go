@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Configuration directory lookup shared by start-agents and send-agent
+//
+// Per-session state (manifest, queue, history, schedule) lives under the directory
+// selected by CONFIG_DIR in agents.conf. send-agent follows the @cca_manifest
+// session option when it is set and resolves the directory the same way otherwise,
+// so both tools agree even for sessions that never published the option.
+
+// TeamConfigPath returns the agents.conf start-agents loads: the one in the configuration
+// directory, in the home directory or in the current directory, in that order
+func TeamConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".claude-code-agents.conf"
+	}
+
+	unifiedPath := filepath.Join(DefaultConfigDir(), "agents.conf")
+	for _, path := range []string{unifiedPath, filepath.Join(homeDir, ".claude-code-agents.conf"), ".claude-code-agents.conf"} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return unifiedPath
+}
+
+// ResolveConfigDir returns the directory selected by a CONFIG_DIR value ("~" is expanded,
+// an empty value selects DefaultConfigDir)
+func ResolveConfigDir(configDir string) string {
+	configDir = strings.TrimSpace(configDir)
+	if configDir == "" {
+		return DefaultConfigDir()
+	}
+	if configDir == "~" || strings.HasPrefix(configDir, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return DefaultConfigDir()
+		}
+		return filepath.Join(homeDir, configDir[1:])
+	}
+	return configDir
+}
+
+// ConfigDir returns the directory selected by CONFIG_DIR in agents.conf
+func ConfigDir() string {
+	return ResolveConfigDir(readConfigValue(TeamConfigPath(), "CONFIG_DIR"))
+}
+
+// readConfigValue returns the value of a KEY=VALUE entry of a configuration file ("" when unset)
+func readConfigValue(path, key string) string {
+	file, err := os.Open(path) // #nosec G304
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = file.Close() }()
+
+	value := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok && strings.TrimSpace(k) == key {
+			value = strings.TrimSpace(v)
+		}
+	}
+	return value
+}
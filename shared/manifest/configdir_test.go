@@ -0,0 +1,35 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveConfigDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	assert.Equal(t, DefaultConfigDir(), ResolveConfigDir(""))
+	assert.Equal(t, filepath.Join(home, "state"), ResolveConfigDir("~/state"))
+	assert.Equal(t, "/var/lib/cca", ResolveConfigDir("/var/lib/cca"))
+}
+
+func TestConfigDirFollowsAgentsConf(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// Without agents.conf the default directory is used
+	assert.Equal(t, DefaultConfigDir(), ConfigDir())
+
+	configPath := filepath.Join(DefaultConfigDir(), "agents.conf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0750))
+	content := "# CONFIG_DIR=/ignored\nDEV_COUNT=4\nCONFIG_DIR=~/team-state\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	assert.Equal(t, configPath, TeamConfigPath())
+	assert.Equal(t, filepath.Join(home, "team-state"), ConfigDir())
+}
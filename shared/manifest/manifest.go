@@ -0,0 +1,172 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Version is the current manifest schema version
+const Version = 1
+
+// File and tmux user option names shared by start-agents and send-agent
+const (
+	FileName = "manifest.json"
+
+	// OptionManifest is the session option holding the manifest file path
+	OptionManifest = "@cca_manifest"
+	// OptionDevCount is the session option holding the developer count
+	OptionDevCount = "@cca_dev_count"
+	// OptionConfigHash is the session option holding the configuration hash
+	OptionConfigHash = "@cca_config_hash"
+	// OptionWorkingDir is the session option holding the working directory
+	OptionWorkingDir = "@cca_working_dir"
+	// OptionLaunchedAt is the session option holding the launch time (RFC3339)
+	OptionLaunchedAt = "@cca_launched_at"
+	// OptionAgent is the pane option holding the agent name
+	OptionAgent = "@cca_agent"
+	// OptionRole is the pane option holding the agent role
+	OptionRole = "@cca_role"
+)
+
+// Agent represents one agent of the team and the pane it runs in
+type Agent struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Title  string `json:"title"`
+	PaneID string `json:"pane_id"`
+	// Instruction is the role instruction file resolved at launch (re-sent after a context reset)
+	Instruction string `json:"instruction,omitempty"`
+}
+
+// Manifest describes a launched team session
+type Manifest struct {
+	Version     int       `json:"version"`
+	SessionName string    `json:"session_name"`
+	Layout      string    `json:"layout"`
+	DevCount    int       `json:"dev_count"`
+	WorkingDir  string    `json:"working_dir"`
+	ConfigPath  string    `json:"config_path,omitempty"`
+	ConfigHash  string    `json:"config_hash,omitempty"`
+	LaunchedAt  time.Time `json:"launched_at"`
+	Agents      []Agent   `json:"agents"`
+	// Groups are the user-defined recipient groups (group name → agent names)
+	Groups map[string][]string `json:"groups,omitempty"`
+	// Routes is the routing policy (sender role → allowed recipient roles or agents)
+	Routes map[string][]string `json:"routes,omitempty"`
+}
+
+// DefaultConfigDir returns the default claude-code-agents configuration directory
+func DefaultConfigDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".claude", "claude-code-agents")
+	}
+	return filepath.Join(homeDir, ".claude", "claude-code-agents")
+}
+
+// SessionDir returns the per-session state directory under configDir
+func SessionDir(configDir, sessionName string) string {
+	return filepath.Join(configDir, "sessions", sessionName)
+}
+
+// Path returns the manifest file path for the session
+func Path(configDir, sessionName string) string {
+	return filepath.Join(SessionDir(configDir, sessionName), FileName)
+}
+
+// Agent returns the agent entry with the given name, or nil if not present
+func (m *Manifest) Agent(name string) *Agent {
+	for i := range m.Agents {
+		if m.Agents[i].Name == name {
+			return &m.Agents[i]
+		}
+	}
+	return nil
+}
+
+// AgentByPaneID returns the agent entry running in the given pane, or nil if not present
+func (m *Manifest) AgentByPaneID(paneID string) *Agent {
+	for i := range m.Agents {
+		if m.Agents[i].PaneID == paneID {
+			return &m.Agents[i]
+		}
+	}
+	return nil
+}
+
+// Save writes the manifest atomically to its path under configDir and returns the path
+func (m *Manifest) Save(configDir string) (string, error) {
+	if m.SessionName == "" {
+		return "", fmt.Errorf("manifest session name is empty")
+	}
+	if m.Version == 0 {
+		m.Version = Version
+	}
+
+	path := Path(configDir, m.SessionName)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to replace manifest: %w", err)
+	}
+
+	return path, nil
+}
+
+// Load reads the manifest of the session from configDir
+func Load(configDir, sessionName string) (*Manifest, error) {
+	return LoadFile(Path(configDir, sessionName))
+}
+
+// LoadFile reads a manifest from the given path
+func LoadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.Version > Version {
+		return nil, fmt.Errorf("unsupported manifest version %d (max %d)", m.Version, Version)
+	}
+
+	return &m, nil
+}
+
+// Remove deletes the manifest of the session; a missing manifest is not an error
+func Remove(configDir, sessionName string) error {
+	if err := os.Remove(Path(configDir, sessionName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+	return nil
+}
+
+// HashFile returns the hex SHA-256 of the file contents, or an empty string if it cannot be read
+func HashFile(path string) string {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleManifest() *Manifest {
+	return &Manifest{
+		SessionName: "myproject",
+		Layout:      "integrated",
+		DevCount:    2,
+		WorkingDir:  "/work",
+		ConfigHash:  "abc",
+		LaunchedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Agents: []Agent{
+			{Name: "po", Role: "po", Title: "PO", PaneID: "%1"},
+			{Name: "manager", Role: "manager", Title: "Manager", PaneID: "%2"},
+			{Name: "dev1", Role: "dev", Title: "Dev1", PaneID: "%3", Instruction: "/instructions/developer.md"},
+			{Name: "dev2", Role: "dev", Title: "Dev2", PaneID: "%4"},
+		},
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	configDir := t.TempDir()
+	m := sampleManifest()
+
+	path, err := m.Save(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(configDir, "sessions", "myproject", FileName), path)
+	assert.Equal(t, Version, m.Version)
+
+	loaded, err := Load(configDir, "myproject")
+	require.NoError(t, err)
+	assert.Equal(t, m, loaded)
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temporary file should not remain")
+}
+
+func TestSave_EmptySessionName(t *testing.T) {
+	m := sampleManifest()
+	m.SessionName = ""
+
+	_, err := m.Save(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	configDir := t.TempDir()
+
+	t.Run("Missing manifest", func(t *testing.T) {
+		_, err := Load(configDir, "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("Malformed manifest", func(t *testing.T) {
+		path := Path(configDir, "broken")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+
+		_, err := Load(configDir, "broken")
+		assert.Error(t, err)
+	})
+
+	t.Run("Future version", func(t *testing.T) {
+		path := Path(configDir, "future")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(`{"version": 99}`), 0600))
+
+		_, err := Load(configDir, "future")
+		assert.ErrorContains(t, err, "unsupported manifest version")
+	})
+}
+
+func TestAgentLookup(t *testing.T) {
+	m := sampleManifest()
+
+	require.NotNil(t, m.Agent("dev1"))
+	assert.Equal(t, "%3", m.Agent("dev1").PaneID)
+	assert.Nil(t, m.Agent("dev9"))
+
+	require.NotNil(t, m.AgentByPaneID("%2"))
+	assert.Equal(t, "manager", m.AgentByPaneID("%2").Name)
+	assert.Nil(t, m.AgentByPaneID("%99"))
+}
+
+func TestRemove(t *testing.T) {
+	configDir := t.TempDir()
+	_, err := sampleManifest().Save(configDir)
+	require.NoError(t, err)
+
+	require.NoError(t, Remove(configDir, "myproject"))
+	_, err = Load(configDir, "myproject")
+	assert.Error(t, err)
+
+	assert.NoError(t, Remove(configDir, "myproject"), "removing a missing manifest is not an error")
+}
+
+func TestHashFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agents.conf")
+	require.NoError(t, os.WriteFile(path, []byte("DEV_COUNT=4\n"), 0600))
+
+	hash := HashFile(path)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashFile(path))
+
+	require.NoError(t, os.WriteFile(path, []byte("DEV_COUNT=6\n"), 0600))
+	assert.NotEqual(t, hash, HashFile(path))
+
+	assert.Equal(t, "", HashFile(filepath.Join(dir, "missing.conf")))
+}
@@ -0,0 +1,147 @@
+package tmuxsocket
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dedicated tmux servers
+//
+// Team sessions run on the user's default tmux server unless DEDICATED_SOCKET is
+// enabled: each team then gets a tmux server of its own on the socket
+// "cca-<session>" (tmux -L), isolated from personal sessions. The selected socket
+// is kept in the environment so that processes started for the team (the
+// send-agent scheduler, the tmux server and the agents in its panes) use the same
+// server.
+
+// Prefix is the prefix of the socket names of dedicated team servers
+const Prefix = "cca-"
+
+// EnvSocket is the environment variable holding the selected socket name
+const EnvSocket = "CCA_TMUX_SOCKET"
+
+// Name returns the socket name of the dedicated server of a team session
+func Name(sessionName string) string {
+	return Prefix + sessionName
+}
+
+// SessionOf returns the team session of a dedicated server's socket name
+func SessionOf(socket string) (string, bool) {
+	if !strings.HasPrefix(socket, Prefix) || len(socket) == len(Prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(socket, Prefix), true
+}
+
+// Use selects the socket used by Command ("" selects the default server)
+func Use(socket string) {
+	if socket == "" {
+		_ = os.Unsetenv(EnvSocket)
+		return
+	}
+	_ = os.Setenv(EnvSocket, socket)
+}
+
+// Selected returns the selected socket name ("" for the default server)
+func Selected() string {
+	return os.Getenv(EnvSocket)
+}
+
+// Args returns the tmux arguments addressing the selected server
+func Args(args ...string) []string {
+	if socket := Selected(); socket != "" {
+		return append([]string{"-L", socket}, args...)
+	}
+	return args
+}
+
+// Command returns a tmux command run against the selected server
+func Command(args ...string) *exec.Cmd {
+	return CommandOn(Selected(), args...)
+}
+
+// CommandOn returns a tmux command run against the server of the socket ("" for the default server)
+func CommandOn(socket string, args ...string) *exec.Cmd {
+	if socket != "" {
+		args = append([]string{"-L", socket}, args...)
+	}
+	return exec.Command("tmux", args...) // #nosec G204
+}
+
+// CallerCommand returns a tmux command run against the server of the calling pane ($TMUX),
+// or the default server outside tmux
+func CallerCommand(args ...string) *exec.Cmd {
+	return exec.Command("tmux", args...) // #nosec G204
+}
+
+// AttachCommand returns the shell command attaching to a session on the selected server
+func AttachCommand(sessionName string) string {
+	if socket := Selected(); socket != "" {
+		return fmt.Sprintf("tmux -L %s attach-session -t %s", socket, sessionName)
+	}
+	return fmt.Sprintf("tmux attach-session -t %s", sessionName)
+}
+
+// CallerSocket returns the socket path of the tmux server the caller runs in ("" outside tmux)
+func CallerSocket() string {
+	value := os.Getenv("TMUX")
+	if value == "" {
+		return ""
+	}
+	path, _, _ := strings.Cut(value, ",")
+	return path
+}
+
+// OnCallerServer reports whether the selected server is the one the caller runs in, so that
+// the caller's client can switch to its sessions
+func OnCallerServer() bool {
+	caller := CallerSocket()
+	if caller == "" {
+		return false
+	}
+	socket := Selected()
+	return socket == "" || filepath.Base(caller) == socket
+}
+
+// Dir returns the directory tmux creates its sockets in
+func Dir() string {
+	tmpDir := os.Getenv("TMUX_TMPDIR")
+	if tmpDir == "" {
+		tmpDir = "/tmp"
+	}
+	return filepath.Join(tmpDir, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
+// Dedicated returns the socket names of the running dedicated team servers
+func Dedicated() []string {
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		return nil
+	}
+
+	var sockets []string
+	for _, entry := range entries {
+		if _, ok := SessionOf(entry.Name()); !ok || entry.Type()&os.ModeSocket == 0 {
+			continue
+		}
+		// Sockets of servers that have exited stay behind; only list live servers
+		if CommandOn(entry.Name(), "list-sessions").Run() == nil {
+			sockets = append(sockets, entry.Name())
+		}
+	}
+	sort.Strings(sockets)
+	return sockets
+}
+
+// Find returns the socket of the dedicated server running the session
+func Find(sessionName string) (string, bool) {
+	socket := Name(sessionName)
+	if CommandOn(socket, "has-session", "-t", "="+sessionName).Run() != nil {
+		return "", false
+	}
+	return socket, true
+}
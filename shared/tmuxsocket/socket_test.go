@@ -0,0 +1,76 @@
+package tmuxsocket
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameAndSessionOf(t *testing.T) {
+	assert.Equal(t, "cca-myproject", Name("myproject"))
+
+	session, ok := SessionOf("cca-myproject")
+	assert.True(t, ok)
+	assert.Equal(t, "myproject", session)
+
+	_, ok = SessionOf("cca-")
+	assert.False(t, ok)
+	_, ok = SessionOf("default")
+	assert.False(t, ok)
+}
+
+func TestArgsFollowSelectedSocket(t *testing.T) {
+	t.Setenv(EnvSocket, "")
+
+	Use("")
+	assert.Equal(t, "", Selected())
+	assert.Equal(t, []string{"list-sessions"}, Args("list-sessions"))
+	assert.Equal(t, "tmux attach-session -t proj", AttachCommand("proj"))
+
+	Use("cca-proj")
+	assert.Equal(t, "cca-proj", Selected())
+	assert.Equal(t, []string{"-L", "cca-proj", "list-sessions"}, Args("list-sessions"))
+	assert.Equal(t, []string{"tmux", "-L", "cca-proj", "has-session"}, Command("has-session").Args)
+	assert.Equal(t, "tmux -L cca-proj attach-session -t proj", AttachCommand("proj"))
+
+	Use("")
+	_, set := os.LookupEnv(EnvSocket)
+	assert.False(t, set)
+}
+
+func TestCommandOn(t *testing.T) {
+	t.Setenv(EnvSocket, "cca-proj")
+
+	// The socket is given explicitly, regardless of the selected one
+	assert.Equal(t, []string{"tmux", "-L", "cca-other", "has-session"}, CommandOn("cca-other", "has-session").Args)
+	assert.Equal(t, []string{"tmux", "has-session"}, CommandOn("", "has-session").Args)
+}
+
+func TestOnCallerServer(t *testing.T) {
+	t.Setenv(EnvSocket, "")
+
+	t.Setenv("TMUX", "")
+	assert.Equal(t, "", CallerSocket())
+	assert.False(t, OnCallerServer())
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	assert.Equal(t, "/tmp/tmux-1000/default", CallerSocket())
+	assert.True(t, OnCallerServer())
+
+	Use("cca-proj")
+	assert.False(t, OnCallerServer())
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/cca-proj,1234,0")
+	assert.True(t, OnCallerServer())
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("TMUX_TMPDIR", "/var/run/user")
+	assert.Equal(t, filepath.Join("/var/run/user", fmt.Sprintf("tmux-%d", os.Getuid())), Dir())
+
+	t.Setenv("TMUX_TMPDIR", "")
+	assert.Equal(t, filepath.Join("/tmp", fmt.Sprintf("tmux-%d", os.Getuid())), Dir())
+}
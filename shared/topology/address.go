@@ -0,0 +1,33 @@
+package topology
+
+import "strings"
+
+// AddressSeparator separates the session from the agent in a cross-session address such as "team-b:manager"
+const AddressSeparator = ":"
+
+// SplitAddress splits "session:agent" into its session and agent; a plain agent name has an empty session
+func SplitAddress(address string) (string, string) {
+	session, agent, found := strings.Cut(address, AddressSeparator)
+	if !found {
+		return "", address
+	}
+	return session, agent
+}
+
+// QualifyAddress returns the address of an agent of another session ("session:agent");
+// with an empty session the agent name is returned unchanged
+func QualifyAddress(session, agent string) string {
+	if session == "" {
+		return agent
+	}
+	return session + AddressSeparator + agent
+}
+
+// IsAddress reports whether the address names a single agent, optionally qualified with its session
+func IsAddress(address string) bool {
+	session, agent := SplitAddress(address)
+	if strings.Contains(address, AddressSeparator) && session == "" {
+		return false
+	}
+	return IsAgentName(agent)
+}
@@ -0,0 +1,40 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAddress(t *testing.T) {
+	session, agent := SplitAddress("team-b:manager")
+	assert.Equal(t, "team-b", session)
+	assert.Equal(t, "manager", agent)
+
+	session, agent = SplitAddress("dev1")
+	assert.Empty(t, session)
+	assert.Equal(t, "dev1", agent)
+
+	assert.Equal(t, "team-b:manager", QualifyAddress("team-b", "manager"))
+	assert.Equal(t, "manager", QualifyAddress("", "manager"))
+}
+
+func TestIsAddress(t *testing.T) {
+	for _, address := range []string{"dev1", "manager", "team-b:manager", "frontend:dev12"} {
+		assert.True(t, IsAddress(address), address)
+	}
+	for _, address := range []string{"", ":manager", "team-b:", "team-b:devs", "team-b:dev1:x", "user"} {
+		assert.False(t, IsAddress(address), address)
+	}
+}
+
+func TestRoleOf_QualifiedAddress(t *testing.T) {
+	role, ok := RoleOf("team-a:manager")
+	assert.True(t, ok)
+	assert.Equal(t, RoleManager, role)
+
+	// Routing judges senders of other sessions by their role
+	policy := RoutingPolicy{RoleDev: {RoleManager}}
+	assert.True(t, policy.Allows("team-a:dev1", "manager"))
+	assert.False(t, policy.Allows("team-a:dev1", "po"))
+}
@@ -0,0 +1,106 @@
+package topology
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Built-in recipient groups
+const (
+	// GroupAll addresses every agent of the team
+	GroupAll = "all"
+	// GroupDevs addresses every developer of the team
+	GroupDevs = "devs"
+)
+
+var groupNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ValidateGroupName checks that a user-defined group name is usable as a recipient
+func ValidateGroupName(name string) error {
+	if !groupNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid group name '%s' (lowercase letters, digits, '-' and '_')", name)
+	}
+	if IsAgentName(name) || name == RoleDev {
+		return fmt.Errorf("group name '%s' conflicts with an agent name", name)
+	}
+	if name == GroupAll || name == GroupDevs {
+		return fmt.Errorf("group name '%s' is reserved", name)
+	}
+	return nil
+}
+
+// SplitRecipients splits a comma or whitespace separated recipient list
+func SplitRecipients(spec string) []string {
+	return strings.FieldsFunc(spec, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// IsMultiRecipient reports whether the recipient spec may address more than one agent
+func IsMultiRecipient(spec string) bool {
+	names := SplitRecipients(spec)
+	return len(names) != 1 || !IsAgentName(names[0])
+}
+
+// ExpandRecipients resolves a recipient spec ("dev1,dev3", "devs", "all" or user-defined
+// group names) against the agents of a session. The result keeps the order of the
+// spec, contains every agent once, and only agents present in the session.
+func ExpandRecipients(spec string, agents []string, groups map[string][]string) ([]string, error) {
+	present := make(map[string]bool, len(agents))
+	for _, name := range agents {
+		present[name] = true
+	}
+
+	var recipients []string
+	seen := map[string]bool{}
+	add := func(name string) error {
+		if !present[name] {
+			return fmt.Errorf("agent '%s' is not part of the session", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			recipients = append(recipients, name)
+		}
+		return nil
+	}
+
+	names := SplitRecipients(spec)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no recipient specified")
+	}
+
+	for _, name := range names {
+		switch {
+		case IsAgentName(name):
+			if err := add(name); err != nil {
+				return nil, err
+			}
+		case name == GroupAll:
+			for _, agent := range agents {
+				_ = add(agent)
+			}
+		case name == GroupDevs:
+			for _, agent := range agents {
+				if role, _ := RoleOf(agent); role == RoleDev {
+					_ = add(agent)
+				}
+			}
+		default:
+			members, ok := groups[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown agent or group '%s'", name)
+			}
+			for _, member := range members {
+				if err := add(member); err != nil {
+					return nil, fmt.Errorf("group '%s': %w", name, err)
+				}
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("'%s' does not address any agent of the session", spec)
+	}
+	return recipients, nil
+}
@@ -0,0 +1,74 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandRecipients(t *testing.T) {
+	agents := New(4).Names()
+	groups := map[string][]string{
+		"frontend": {"dev1", "dev2"},
+		"leads":    {"po", "manager"},
+		"ghost":    {"dev9"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{"Single agent", "dev1", []string{"dev1"}},
+		{"Comma list", "dev1,dev3", []string{"dev1", "dev3"}},
+		{"Spaces around commas", "dev3, dev1", []string{"dev3", "dev1"}},
+		{"Developers", "devs", []string{"dev1", "dev2", "dev3", "dev4"}},
+		{"All", "all", []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}},
+		{"User group", "frontend", []string{"dev1", "dev2"}},
+		{"Duplicates are removed", "dev2,frontend,devs", []string{"dev2", "dev1", "dev3", "dev4"}},
+		{"Groups and agents", "leads,dev4", []string{"po", "manager", "dev4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipients, err := ExpandRecipients(tt.spec, agents, groups)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, recipients)
+		})
+	}
+
+	errorCases := []string{"", ",", "dev5", "unknown", "ghost", "dev1,backend"}
+	for _, spec := range errorCases {
+		_, err := ExpandRecipients(spec, agents, groups)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestExpandRecipients_SparseSession(t *testing.T) {
+	// Individual session mode may only run some of the agents
+	recipients, err := ExpandRecipients("devs", []string{"manager", "dev2"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev2"}, recipients)
+
+	_, err = ExpandRecipients("devs", []string{"po"}, nil)
+	assert.Error(t, err)
+}
+
+func TestIsMultiRecipient(t *testing.T) {
+	assert.False(t, IsMultiRecipient("dev1"))
+	assert.False(t, IsMultiRecipient("manager"))
+	assert.True(t, IsMultiRecipient("dev1,dev2"))
+	assert.True(t, IsMultiRecipient("devs"))
+	assert.True(t, IsMultiRecipient("all"))
+	assert.True(t, IsMultiRecipient("frontend"))
+}
+
+func TestValidateGroupName(t *testing.T) {
+	assert.NoError(t, ValidateGroupName("frontend"))
+	assert.NoError(t, ValidateGroupName("team_a-2"))
+
+	for _, name := range []string{"", "Frontend", "dev1", "po", "dev", "all", "devs", "a,b", "1team"} {
+		assert.Error(t, ValidateGroupName(name), name)
+	}
+}
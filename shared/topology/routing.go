@@ -0,0 +1,85 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RouteAny allows messages to every agent
+const RouteAny = "*"
+
+// RoutingPolicy states which recipients each sending role may message.
+// Keys are sender roles (po, manager, dev); values are recipient roles,
+// agent names or RouteAny. Roles without an entry are unrestricted, so
+// an empty policy allows everything.
+type RoutingPolicy map[string][]string
+
+// ValidateRoute checks one policy entry
+func ValidateRoute(role string, recipients []string) error {
+	if !isRole(role) {
+		return fmt.Errorf("invalid sender role '%s' (po|manager|dev)", role)
+	}
+	for _, recipient := range recipients {
+		if recipient != RouteAny && !isRole(recipient) && !IsAgentName(recipient) {
+			return fmt.Errorf("invalid recipient '%s' for role '%s' (role, agent name or %s)", recipient, role, RouteAny)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the sender agent may message the recipient agent.
+// Senders that are not agents (e.g. the user's own shell) are always allowed.
+func (p RoutingPolicy) Allows(from, to string) bool {
+	role, ok := RoleOf(from)
+	if !ok {
+		return true
+	}
+
+	allowed, restricted := p[role]
+	if !restricted {
+		return true
+	}
+
+	toRole, _ := RoleOf(to)
+	for _, recipient := range allowed {
+		if recipient == RouteAny || recipient == to || recipient == toRole {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe renders the policy as "role → recipients" lines in role order
+func (p RoutingPolicy) Describe() []string {
+	roles := make([]string, 0, len(p))
+	for role := range p {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roleOrder(roles[i]) < roleOrder(roles[j]) })
+
+	lines := make([]string, 0, len(roles))
+	for _, role := range roles {
+		recipients := strings.Join(p[role], ", ")
+		if recipients == "" {
+			recipients = "(nobody)"
+		}
+		lines = append(lines, fmt.Sprintf("%s → %s", role, recipients))
+	}
+	return lines
+}
+
+func isRole(name string) bool {
+	return name == RolePO || name == RoleManager || name == RoleDev
+}
+
+func roleOrder(role string) int {
+	switch role {
+	case RolePO:
+		return 0
+	case RoleManager:
+		return 1
+	default:
+		return 2
+	}
+}
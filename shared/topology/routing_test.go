@@ -0,0 +1,62 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingPolicyAllows(t *testing.T) {
+	policy := RoutingPolicy{
+		RoleDev:     {AgentManager},
+		RoleManager: {RouteAny},
+		RolePO:      {RoleManager},
+	}
+
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{"dev1", "manager", true},
+		{"dev1", "po", false},
+		{"dev1", "dev2", false},
+		{"manager", "po", true},
+		{"manager", "dev3", true},
+		{"po", "manager", true},
+		{"po", "dev1", false},
+		{"user", "dev1", true},
+		{"", "po", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.allowed, policy.Allows(tt.from, tt.to), "%s → %s", tt.from, tt.to)
+	}
+}
+
+func TestRoutingPolicyAllows_PartialAndEmpty(t *testing.T) {
+	// Roles without an entry are unrestricted
+	policy := RoutingPolicy{RoleDev: {RoleManager, "dev1"}}
+	assert.True(t, policy.Allows("po", "dev4"))
+	assert.True(t, policy.Allows("dev3", "dev1"))
+	assert.False(t, policy.Allows("dev3", "dev2"))
+
+	// An empty entry allows nothing
+	policy = RoutingPolicy{RoleDev: {}}
+	assert.False(t, policy.Allows("dev1", "manager"))
+
+	assert.True(t, RoutingPolicy(nil).Allows("dev1", "po"))
+}
+
+func TestValidateRoute(t *testing.T) {
+	assert.NoError(t, ValidateRoute(RoleDev, []string{RoleManager}))
+	assert.NoError(t, ValidateRoute(RoleManager, []string{RouteAny}))
+	assert.NoError(t, ValidateRoute(RolePO, []string{"manager", "dev2"}))
+
+	assert.Error(t, ValidateRoute("dev1", []string{RoleManager}))
+	assert.Error(t, ValidateRoute("boss", []string{RoleManager}))
+	assert.Error(t, ValidateRoute(RoleDev, []string{"everyone"}))
+}
+
+func TestRoutingPolicyDescribe(t *testing.T) {
+	policy := RoutingPolicy{RoleDev: {RoleManager}, RolePO: {RoleManager}, RoleManager: {}}
+	assert.Equal(t, []string{"po → manager", "manager → (nobody)", "dev → manager"}, policy.Describe())
+}
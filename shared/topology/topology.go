@@ -0,0 +1,193 @@
+package topology
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Roles
+const (
+	RolePO      = "po"
+	RoleManager = "manager"
+	RoleDev     = "dev"
+)
+
+// Fixed agent names and team defaults
+const (
+	AgentPO      = "po"
+	AgentManager = "manager"
+
+	// LeaderCount is the number of non-developer agents (PO and Manager)
+	LeaderCount = 2
+	// DefaultDevCount is the developer count used when none is configured
+	DefaultDevCount = 4
+)
+
+// AgentNamePattern matches any valid agent name (po, manager, dev1, dev2, ...)
+const AgentNamePattern = `po|manager|dev[1-9][0-9]*`
+
+var (
+	agentNameRegex    = regexp.MustCompile(`^(` + AgentNamePattern + `)$`)
+	agentSessionRegex = regexp.MustCompile(`-(` + AgentNamePattern + `)$`)
+)
+
+// Agent describes one member of the team
+type Agent struct {
+	Name        string
+	Role        string
+	Title       string
+	Description string
+	// Index is the 0-based position of the agent's pane in the team layout
+	Index int
+}
+
+// Team describes the agents of a team with a given developer count
+type Team struct {
+	DevCount int
+}
+
+// New creates a team with PO, Manager and devCount developers
+func New(devCount int) Team {
+	if devCount < 0 {
+		devCount = 0
+	}
+	return Team{DevCount: devCount}
+}
+
+// PaneCount returns the number of panes the team occupies
+func (t Team) PaneCount() int {
+	return LeaderCount + t.DevCount
+}
+
+// Agents returns all agents in pane order
+func (t Team) Agents() []Agent {
+	agents := make([]Agent, 0, t.PaneCount())
+	for _, name := range t.Names() {
+		agent, _ := Lookup(name)
+		agents = append(agents, agent)
+	}
+	return agents
+}
+
+// Names returns all agent names in pane order
+func (t Team) Names() []string {
+	names := []string{AgentPO, AgentManager}
+	for i := 1; i <= t.DevCount; i++ {
+		names = append(names, DevName(i))
+	}
+	return names
+}
+
+// Find returns the agent with the given name if it belongs to the team
+func (t Team) Find(name string) (Agent, bool) {
+	agent, ok := Lookup(name)
+	if !ok || agent.Index >= t.PaneCount() {
+		return Agent{}, false
+	}
+	return agent, true
+}
+
+// Contains reports whether the agent belongs to the team
+func (t Team) Contains(name string) bool {
+	_, ok := t.Find(name)
+	return ok
+}
+
+// AgentAt returns the agent occupying the pane at the given 0-based index
+func (t Team) AgentAt(index int) (Agent, bool) {
+	if index < 0 || index >= t.PaneCount() {
+		return Agent{}, false
+	}
+	return Lookup(t.Names()[index])
+}
+
+// Developers returns the developer agents in pane order
+func (t Team) Developers() []Agent {
+	return t.Agents()[LeaderCount:]
+}
+
+// Lookup describes an agent by name independently of any team size
+func Lookup(name string) (Agent, bool) {
+	switch name {
+	case AgentPO:
+		return Agent{
+			Name:        AgentPO,
+			Role:        RolePO,
+			Title:       "PO",
+			Description: "Product Owner (Product Manager)",
+			Index:       0,
+		}, true
+	case AgentManager:
+		return Agent{
+			Name:        AgentManager,
+			Role:        RoleManager,
+			Title:       "Manager",
+			Description: "Project Manager (Flexible team management)",
+			Index:       1,
+		}, true
+	}
+
+	n, ok := DevNumber(name)
+	if !ok {
+		return Agent{}, false
+	}
+	return Agent{
+		Name:        name,
+		Role:        RoleDev,
+		Title:       fmt.Sprintf("Dev%d", n),
+		Description: fmt.Sprintf("Execution Agent %d (Flexible role assignment)", n),
+		Index:       LeaderCount + n - 1,
+	}, true
+}
+
+// IsAgentName reports whether name is a valid agent name for some team size
+func IsAgentName(name string) bool {
+	return agentNameRegex.MatchString(name)
+}
+
+// RoleOf returns the role of the agent; the bare role name "dev" and session-qualified
+// addresses ("team-b:manager") are accepted as well
+func RoleOf(name string) (string, bool) {
+	_, name = SplitAddress(name)
+	if name == RoleDev {
+		return RoleDev, true
+	}
+	agent, ok := Lookup(name)
+	if !ok {
+		return "", false
+	}
+	return agent.Role, true
+}
+
+// DevName returns the name of the n-th developer (1-based)
+func DevName(n int) string {
+	return fmt.Sprintf("dev%d", n)
+}
+
+// DevNumber returns the 1-based developer number of a developer agent name
+func DevNumber(name string) (int, bool) {
+	if !IsAgentName(name) || !strings.HasPrefix(name, RoleDev) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, RoleDev))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// SplitAgentSession splits an individual session name such as "project-dev1" into its base name and agent
+func SplitAgentSession(sessionName string) (string, string, bool) {
+	match := agentSessionRegex.FindStringSubmatch(sessionName)
+	if match == nil {
+		return "", "", false
+	}
+	return strings.TrimSuffix(sessionName, match[0]), match[1], true
+}
+
+// AgentSessionName returns the individual session name of an agent
+func AgentSessionName(baseName, agent string) string {
+	return baseName + "-" + agent
+}
@@ -0,0 +1,96 @@
+package topology
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamNames(t *testing.T) {
+	assert.Equal(t, []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}, New(DefaultDevCount).Names())
+	assert.Equal(t, []string{"po", "manager"}, New(0).Names())
+	assert.Equal(t, []string{"po", "manager"}, New(-1).Names())
+
+	team := New(10)
+	names := team.Names()
+	assert.Len(t, names, 12)
+	assert.Equal(t, "dev10", names[11])
+	assert.Equal(t, 12, team.PaneCount())
+}
+
+func TestTeamFind(t *testing.T) {
+	team := New(6)
+
+	agent, ok := team.Find("dev6")
+	require.True(t, ok)
+	assert.Equal(t, RoleDev, agent.Role)
+	assert.Equal(t, "Dev6", agent.Title)
+	assert.Equal(t, 7, agent.Index)
+
+	assert.False(t, team.Contains("dev7"))
+	assert.True(t, team.Contains("manager"))
+	assert.False(t, team.Contains("unknown"))
+
+	agent, ok = team.AgentAt(1)
+	require.True(t, ok)
+	assert.Equal(t, AgentManager, agent.Name)
+	_, ok = team.AgentAt(8)
+	assert.False(t, ok)
+
+	assert.Len(t, team.Developers(), 6)
+}
+
+func TestIsAgentName(t *testing.T) {
+	for _, name := range []string{"po", "manager", "dev1", "dev4", "dev5", "dev12"} {
+		assert.True(t, IsAgentName(name), name)
+	}
+	for _, name := range []string{"", "dev", "dev0", "dev01", "PO", "Manager", "dev@1", "dev-1", "po1", "ceo"} {
+		assert.False(t, IsAgentName(name), name)
+	}
+}
+
+func TestRoleOf(t *testing.T) {
+	tests := map[string]string{
+		"po":      RolePO,
+		"manager": RoleManager,
+		"dev":     RoleDev,
+		"dev3":    RoleDev,
+		"dev11":   RoleDev,
+	}
+	for name, expected := range tests {
+		role, ok := RoleOf(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, role, name)
+	}
+
+	_, ok := RoleOf("invalid")
+	assert.False(t, ok)
+}
+
+func TestDevNumber(t *testing.T) {
+	n, ok := DevNumber("dev12")
+	assert.True(t, ok)
+	assert.Equal(t, 12, n)
+
+	_, ok = DevNumber("manager")
+	assert.False(t, ok)
+	assert.Equal(t, "dev7", DevName(7))
+}
+
+func TestSplitAgentSession(t *testing.T) {
+	base, agent, ok := SplitAgentSession("my-project-dev10")
+	assert.True(t, ok)
+	assert.Equal(t, "my-project", base)
+	assert.Equal(t, "dev10", agent)
+
+	base, agent, ok = SplitAgentSession("ai-teams-manager")
+	assert.True(t, ok)
+	assert.Equal(t, "ai-teams", base)
+	assert.Equal(t, "manager", agent)
+
+	_, _, ok = SplitAgentSession("ai-teams")
+	assert.False(t, ok)
+
+	assert.Equal(t, "ai-teams-po", AgentSessionName("ai-teams", "po"))
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/shivase/claude-code-agents/internal/tmux"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // Common configuration structure
@@ -145,17 +146,8 @@ func WrapError(command string, err error, code int) *CommandError {
 
 // ValidateAgentName validate agent name
 func ValidateAgentName(agentName string) error {
-	validAgents := map[string]bool{
-		"po":      true,
-		"manager": true,
-		"dev1":    true,
-		"dev2":    true,
-		"dev3":    true,
-		"dev4":    true,
-	}
-
-	if !validAgents[agentName] {
-		return fmt.Errorf("invalid agent name '%s'. Valid agents: po, manager, dev1, dev2, dev3, dev4", agentName)
+	if !topology.IsAgentName(agentName) {
+		return fmt.Errorf("invalid agent name '%s'. Valid agents: po, manager, dev1, dev2, ... devN", agentName)
 	}
 
 	return nil
@@ -4,14 +4,21 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/shivase/claude-code-agents/internal/config"
+	"github.com/shivase/claude-code-agents/internal/layout"
 	"github.com/shivase/claude-code-agents/internal/logger"
 	"github.com/shivase/claude-code-agents/internal/tmux"
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 //go:embed instructions
@@ -84,14 +91,17 @@ func ListAISessions() error {
 
 	tmuxManager := tmux.NewTmuxManager("ai-teams")
 	sessions, err := tmuxManager.ListSessions()
-	if err != nil {
-		if strings.Contains(err.Error(), "no server running") {
-			fmt.Println("📭 No AI team sessions currently running")
-			return nil
-		}
+	if err != nil && !strings.Contains(err.Error(), "no server running") {
 		return fmt.Errorf("tmux session retrieval error: %w", err)
 	}
 
+	// Teams on their own tmux server are listed with the socket to attach through
+	for _, socket := range tmuxsocket.Dedicated() {
+		if sessionName, ok := tmuxsocket.SessionOf(socket); ok {
+			sessions = append(sessions, fmt.Sprintf("%s (tmux -L %s)", sessionName, socket))
+		}
+	}
+
 	if len(sessions) == 0 {
 		fmt.Println("📭 No sessions currently running")
 	} else {
@@ -116,13 +126,25 @@ func DeleteAISession(sessionName string) error {
 	fmt.Printf("🗑️ Deleting session: %s\n", sessionName)
 
 	tmuxManager := tmux.NewTmuxManager(sessionName)
-	if !tmuxManager.SessionExists(sessionName) {
-		fmt.Printf("⚠️ Session '%s' does not exist\n", sessionName)
-		return nil
+	if socket, ok := tmuxsocket.Find(sessionName); ok {
+		// The team runs on its own tmux server: stop the whole server
+		if err := tmuxManager.KillServer(socket); err != nil {
+			return fmt.Errorf("session deletion error: %w", err)
+		}
+	} else {
+		if !tmuxManager.SessionExists(sessionName) {
+			fmt.Printf("⚠️ Session '%s' does not exist\n", sessionName)
+			return nil
+		}
+
+		if err := tmuxManager.KillSession(sessionName); err != nil {
+			return fmt.Errorf("session deletion error: %w", err)
+		}
 	}
 
-	if err := tmuxManager.KillSession(sessionName); err != nil {
-		return fmt.Errorf("session deletion error: %w", err)
+	teamConfig, _ := config.LoadTeamConfig()
+	if err := manifest.Remove(sessionConfigDir(teamConfig), sessionName); err != nil {
+		log.Warn().Err(err).Str("session", sessionName).Msg("Failed to remove session manifest")
 	}
 
 	fmt.Printf("✅ Session '%s' deleted\n", sessionName)
@@ -135,12 +157,13 @@ func DeleteAllAISessions() error {
 	fmt.Println("==============================")
 
 	tmuxManager := tmux.NewTmuxManager("ai-teams")
+
+	// Teams on their own tmux server are deleted by stopping their server,
+	// leaving personal sessions untouched
+	deletedCount := deleteDedicatedServers(tmuxManager)
+
 	sessions, err := tmuxManager.ListSessions()
-	if err != nil {
-		if strings.Contains(err.Error(), "no server running") {
-			fmt.Println("📭 No AI team sessions currently running")
-			return nil
-		}
+	if err != nil && !strings.Contains(err.Error(), "no server running") {
 		return fmt.Errorf("tmux session retrieval error: %w", err)
 	}
 
@@ -154,18 +177,19 @@ func DeleteAllAISessions() error {
 		}
 	}
 
-	if len(aiSessions) == 0 {
+	if len(aiSessions) == 0 && deletedCount == 0 {
 		fmt.Println("📭 No AI team sessions to delete")
 		return nil
 	}
 
-	fmt.Printf("🎯 Sessions to delete: %d\n", len(aiSessions))
-	for i, session := range aiSessions {
-		fmt.Printf("  %d. %s\n", i+1, session)
+	if len(aiSessions) > 0 {
+		fmt.Printf("🎯 Sessions to delete: %d\n", len(aiSessions))
+		for i, session := range aiSessions {
+			fmt.Printf("  %d. %s\n", i+1, session)
+		}
 	}
 
 	// Delete each session
-	deletedCount := 0
 	for _, session := range aiSessions {
 		sessionManager := tmux.NewTmuxManager(session)
 		if err := sessionManager.KillSession(session); err != nil {
@@ -180,6 +204,28 @@ func DeleteAllAISessions() error {
 	return nil
 }
 
+// deleteDedicatedServers stops the tmux servers of teams launched with DEDICATED_SOCKET
+// and returns how many were stopped
+func deleteDedicatedServers(tmuxManager *tmux.TmuxManagerImpl) int {
+	teamConfig, _ := config.LoadTeamConfig()
+
+	deletedCount := 0
+	for _, socket := range tmuxsocket.Dedicated() {
+		sessionName, _ := tmuxsocket.SessionOf(socket)
+		if err := tmuxManager.KillServer(socket); err != nil {
+			fmt.Printf("⚠️ Failed to delete session '%s' (tmux server %s): %v\n", sessionName, socket, err)
+			continue
+		}
+		deletedCount++
+		fmt.Printf("✅ Session '%s' deleted (tmux server %s)\n", sessionName, socket)
+
+		if err := manifest.Remove(sessionConfigDir(teamConfig), sessionName); err != nil {
+			log.Warn().Err(err).Str("session", sessionName).Msg("Failed to remove session manifest")
+		}
+	}
+	return deletedCount
+}
+
 // LaunchSystem system launch function
 func LaunchSystem(sessionName string) error {
 	fmt.Printf("🚀 System startup: %s\n", sessionName)
@@ -220,12 +266,28 @@ func LaunchSystem(sessionName string) error {
 		logger.LogEnvironmentInfo(envInfo, debugMode)
 	}
 
+	// Teams with DEDICATED_SOCKET run on their own tmux server (so does a team still running
+	// on one after the option was turned off)
+	if _, running := tmuxsocket.Find(sessionName); teamConfig.DedicatedSocket || running {
+		tmuxsocket.Use(tmuxsocket.Name(sessionName))
+		fmt.Printf("🔌 Using dedicated tmux server '%s'\n", tmuxsocket.Selected())
+	}
+
 	// Basic tmux management operations
 	tmuxManager := tmux.NewTmuxManager(sessionName)
 
+	// Resolve the pane layout selected by DEFAULT_LAYOUT
+	windows, err := teamConfig.LayoutWindows()
+	if err != nil {
+		return fmt.Errorf("invalid DEFAULT_LAYOUT: %w", err)
+	}
+
 	// Check existing session
 	if tmuxManager.SessionExists(sessionName) {
 		fmt.Printf("🔄 Connecting to existing session '%s'\n", sessionName)
+		if err := reconcileSession(sessionName, configPath, teamConfig, windows, tmuxManager); err != nil {
+			fmt.Printf("⚠️ Session could not be reconciled: %v\n", err)
+		}
 		return tmuxManager.AttachSession(sessionName)
 	}
 
@@ -235,10 +297,26 @@ func LaunchSystem(sessionName string) error {
 		return fmt.Errorf("session creation failed: %w", err)
 	}
 
-	// Create integrated layout (dynamic dev count support)
-	fmt.Println("🎛️ Creating integrated layout...")
-	if err := tmuxManager.CreateIntegratedLayout(sessionName, teamConfig.DevCount); err != nil {
-		return fmt.Errorf("integrated layout creation failed: %w", err)
+	// Create the pane layout
+	if len(windows) > 1 {
+		fmt.Printf("🎛️ Creating %s layout in %d windows...\n", windows[0].Plan.Name, len(windows))
+	} else {
+		fmt.Printf("🎛️ Creating %s layout...\n", windows[0].Plan.Name)
+	}
+	if err := tmuxManager.CreateWindows(sessionName, windows); err != nil {
+		return fmt.Errorf("layout creation failed: %w", err)
+	}
+
+	// Record the team topology so send-agent can resolve agents exactly
+	if err := writeSessionManifest(sessionName, configPath, teamConfig, tmuxManager); err != nil {
+		log.Warn().Err(err).Str("session", sessionName).Msg("Failed to write session manifest")
+		fmt.Printf("⚠️ Session manifest could not be written: %v\n", err)
+	}
+
+	// Start the scheduler sending scheduled and recurring messages (send-agent --at/--in/--every)
+	if err := startScheduler(sessionName, teamConfig); err != nil {
+		log.Warn().Err(err).Str("session", sessionName).Msg("Failed to start message scheduler")
+		fmt.Printf("⚠️ Message scheduler could not be started: %v\n", err)
 	}
 
 	// Claude CLI automatic startup process (configuration file support)
@@ -261,6 +339,140 @@ func LaunchSystem(sessionName string) error {
 	return tmuxManager.AttachSession(sessionName)
 }
 
+// reconcileSession brings an existing session in line with the team configuration: panes that
+// were closed are recreated and Claude CLI is relaunched, with its instructions, only in the
+// panes where it is no longer running
+func reconcileSession(sessionName, configPath string, teamConfig *config.TeamConfig, windows []layout.Window, tmuxManager *tmux.TmuxManagerImpl) error {
+	fmt.Println("🔍 Checking the session against the team configuration...")
+	result, err := tmuxManager.ReconcileWindows(sessionName, windows)
+	if err != nil {
+		return err
+	}
+	for _, agent := range result.Recreated {
+		fmt.Printf("🧩 Recreated the pane of %s\n", agent)
+	}
+	for _, agent := range result.Respawned {
+		fmt.Printf("♻️ Restarted the dead pane of %s\n", agent)
+	}
+	for _, paneID := range result.Extra {
+		fmt.Printf("⚠️ Pane %s belongs to no agent of the team (left untouched)\n", paneID)
+	}
+
+	// Record the agents' current panes (recreated panes have new IDs and no agent options yet)
+	if err := writeSessionManifest(sessionName, configPath, teamConfig, tmuxManager); err != nil {
+		log.Warn().Err(err).Str("session", sessionName).Msg("Failed to write session manifest")
+		fmt.Printf("⚠️ Session manifest could not be written: %v\n", err)
+	}
+
+	// Restart the scheduler if it is no longer running
+	if err := startScheduler(sessionName, teamConfig); err != nil && !strings.Contains(err.Error(), "already running") {
+		log.Warn().Err(err).Str("session", sessionName).Msg("Failed to start message scheduler")
+		fmt.Printf("⚠️ Message scheduler could not be started: %v\n", err)
+	}
+
+	// Agents whose pane was recreated or fell back to a shell
+	var relaunch []topology.Agent
+	for _, agent := range teamConfig.Topology().Agents() {
+		paneID, ok := tmuxManager.AgentPane(agent.Name)
+		if !ok {
+			continue
+		}
+		state, err := tmuxManager.GetAgentState(sessionName, paneID)
+		if err != nil {
+			log.Warn().Err(err).Str("agent", agent.Name).Msg("Failed to get agent state")
+			continue
+		}
+		if state == agentstate.StateExited {
+			relaunch = append(relaunch, agent)
+		}
+	}
+
+	if len(relaunch) == 0 {
+		fmt.Println("✅ All agents are running")
+		return nil
+	}
+
+	names := make([]string, len(relaunch))
+	for i, agent := range relaunch {
+		names[i] = agent.Name
+	}
+	fmt.Printf("🤖 Restarting Claude CLI for %s...\n", strings.Join(names, ", "))
+	if err := tmuxManager.SetupClaudeForAgents(sessionName, teamConfig.ClaudeCLIPath, teamConfig.InstructionsDir, teamConfig, relaunch); err != nil {
+		return fmt.Errorf("claude CLI restart failed: %w", err)
+	}
+	fmt.Println("✅ Claude CLI restarted")
+	return nil
+}
+
+// writeSessionManifest writes the session manifest under the config directory and mirrors it into tmux options
+// (the session may hold panes that belong to no agent; only the agents' panes are recorded)
+func writeSessionManifest(sessionName, configPath string, teamConfig *config.TeamConfig, tmuxManager *tmux.TmuxManagerImpl) error {
+	agents := teamConfig.Topology().Agents()
+	m := &manifest.Manifest{
+		SessionName: sessionName,
+		Layout:      teamConfig.DefaultLayout,
+		DevCount:    teamConfig.DevCount,
+		WorkingDir:  teamConfig.WorkingDir,
+		ConfigPath:  configPath,
+		ConfigHash:  manifest.HashFile(configPath),
+		LaunchedAt:  time.Now(),
+		Groups:      teamConfig.Groups,
+		Routes:      teamConfig.Routes,
+	}
+	resolver := config.NewInstructionResolver(teamConfig)
+	for _, agent := range agents {
+		paneID, ok := tmuxManager.AgentPane(agent.Name)
+		if !ok {
+			return fmt.Errorf("agent %s has no pane", agent.Name)
+		}
+		instruction, err := resolver.ResolveInstructionPath(agent.Name)
+		if err != nil {
+			log.Warn().Err(err).Str("agent", agent.Name).Msg("Failed to resolve instruction file")
+		}
+		m.Agents = append(m.Agents, manifest.Agent{
+			Name:        agent.Name,
+			Role:        agent.Role,
+			Title:       agent.Title,
+			PaneID:      paneID,
+			Instruction: instruction,
+		})
+	}
+
+	manifestPath, err := m.Save(sessionConfigDir(teamConfig))
+	if err != nil {
+		return err
+	}
+
+	return tmuxManager.PublishManifest(m, manifestPath)
+}
+
+// startScheduler starts the per-session message scheduler of send-agent in the background
+func startScheduler(sessionName string, teamConfig *config.TeamConfig) error {
+	sendCommand := teamConfig.SendCommand
+	if sendCommand == "" {
+		sendCommand = "send-agent"
+	}
+
+	path, err := exec.LookPath(sendCommand)
+	if err != nil {
+		return fmt.Errorf("%s not found: %w", sendCommand, err)
+	}
+
+	output, err := exec.Command(path, "schedule", "start", sessionName).CombinedOutput() // #nosec G204
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// sessionConfigDir returns the directory holding per-session state (resolved like send-agent does)
+func sessionConfigDir(teamConfig *config.TeamConfig) string {
+	if teamConfig == nil {
+		return manifest.ConfigDir()
+	}
+	return manifest.ResolveConfigDir(teamConfig.ConfigDir)
+}
+
 // InitializeSystemCommand system initialization command
 func InitializeSystemCommand(forceOverwrite bool, language string) error {
 	fmt.Println("🚀 Claude Code Agents System Initialization")
@@ -431,6 +643,10 @@ DEFAULT_LAYOUT=integrated
 AUTO_ATTACH=false
 IDE_BACKUP_ENABLED=true
 
+# Run each team on its own tmux server (tmux -L cca-<session>), isolated from personal
+# sessions; attach with: tmux -L cca-<session> attach
+DEDICATED_SOCKET=false
+
 # Commands
 SEND_COMMAND=send-agent
 BINARY_NAME=claude-code-agents
@@ -438,6 +654,25 @@ BINARY_NAME=claude-code-agents
 # Developer Settings
 DEV_COUNT=4
 
+# Pane layouts: DEFAULT_LAYOUT takes integrated, grid, grid:<columns>, a tmux layout
+# (tiled, main-vertical, ...), a split tree or the name of a LAYOUT_<NAME> entry.
+# h(...) places panes side by side, v(...) stacks them, ":<percent>" sets a share,
+# and "devs" stands for the developers not placed elsewhere.
+# LAYOUT_WIDE=h(v(po,manager):25,devs)
+
+# Larger teams are spread over windows: PO and manager in the first window, developers
+# in groups of at most DEVS_PER_WINDOW in the following ones (0 = up to 10 in one window)
+DEVS_PER_WINDOW=0
+
+# Recipient groups for send-agent (send-agent frontend "message")
+# GROUP_FRONTEND=dev1,dev2
+
+# Routing policy enforced by send-agent: who may message whom
+# (sender role = recipient roles or agent names, * = anybody; unlisted roles are unrestricted)
+# ROUTE_DEV=manager
+# ROUTE_MANAGER=*
+# ROUTE_PO=manager
+
 # Role-based Instructions
 PO_INSTRUCTION_FILE=po.md
 MANAGER_INSTRUCTION_FILE=manager.md
@@ -593,6 +828,7 @@ func gatherEnvironmentInfo(config *config.TeamConfig) map[string]interface{} {
 	// tmux settings
 	info["tmux_layout"] = config.DefaultLayout
 	info["auto_attach"] = config.AutoAttach
+	info["dedicated_socket"] = config.DedicatedSocket
 	info["pane_count"] = config.PaneCount
 
 	// Timeout settings
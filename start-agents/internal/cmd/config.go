@@ -91,6 +91,7 @@ func displayTeamConfig(teamConfig *config.TeamConfig) {
 	fmt.Printf("   Session Name:         %s\n", teamConfig.SessionName)
 	fmt.Printf("   Default Layout:       %s\n", teamConfig.DefaultLayout)
 	fmt.Printf("   Auto Attach:          %t\n", teamConfig.AutoAttach)
+	fmt.Printf("   Dedicated Socket:     %t\n", teamConfig.DedicatedSocket)
 	fmt.Printf("   Pane Count:           %d\n", teamConfig.PaneCount)
 	fmt.Printf("   IDE Backup Enabled:   %t\n", teamConfig.IDEBackupEnabled)
 	fmt.Printf("   Send Command:         %s\n", teamConfig.SendCommand)
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/shivase/claude-code-agents/internal/layout"
 	"github.com/shivase/claude-code-agents/internal/utils"
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // TeamConfig represents AI Team configuration structure
@@ -35,6 +39,8 @@ type TeamConfig struct {
 	DefaultLayout string
 	AutoAttach    bool
 	PaneCount     int
+	// DedicatedSocket runs each team on its own tmux server (tmux -L cca-<session>)
+	DedicatedSocket bool
 
 	// Authentication Settings
 	AuthCheckInterval time.Duration
@@ -54,6 +60,18 @@ type TeamConfig struct {
 	// Developer settings
 	DevCount int
 
+	// Largest number of developers per window (0 = one window up to 10 developers)
+	DevsPerWindow int
+
+	// Recipient groups for send-agent (GROUP_<NAME>=agent,agent,...)
+	Groups map[string][]string
+
+	// Routing policy enforced by send-agent (ROUTE_<ROLE>=role,agent,...)
+	Routes map[string][]string
+
+	// Pane layouts referenced by DEFAULT_LAYOUT (LAYOUT_<NAME>=<layout>)
+	Layouts map[string]string
+
 	// Role-based Instructions
 	POInstructionFile      string
 	ManagerInstructionFile string
@@ -243,6 +261,8 @@ func LoadTeamConfigFromPath(configPath string) (*TeamConfig, error) {
 			config.BinaryName = value
 		case "AUTO_ATTACH":
 			config.AutoAttach = value == "true"
+		case "DEDICATED_SOCKET":
+			config.DedicatedSocket = value == "true"
 		case "IDE_BACKUP_ENABLED":
 			config.IDEBackupEnabled = value == "true"
 		case "HEALTH_CHECK_INTERVAL":
@@ -273,12 +293,24 @@ func LoadTeamConfigFromPath(configPath string) (*TeamConfig, error) {
 			if count, err := strconv.Atoi(value); err == nil && count > 0 {
 				config.DevCount = count
 			}
+		case "DEVS_PER_WINDOW":
+			if count, err := strconv.Atoi(value); err == nil && count >= 0 {
+				config.DevsPerWindow = count
+			}
 		case "PO_INSTRUCTION_FILE":
 			config.POInstructionFile = value
 		case "MANAGER_INSTRUCTION_FILE":
 			config.ManagerInstructionFile = value
 		case "DEV_INSTRUCTION_FILE":
 			config.DevInstructionFile = value
+		default:
+			if strings.HasPrefix(key, groupKeyPrefix) {
+				config.setGroup(strings.TrimPrefix(key, groupKeyPrefix), value)
+			} else if strings.HasPrefix(key, routeKeyPrefix) {
+				config.setRoute(strings.TrimPrefix(key, routeKeyPrefix), value)
+			} else if strings.HasPrefix(key, layoutKeyPrefix) {
+				config.setLayout(strings.TrimPrefix(key, layoutKeyPrefix), value)
+			}
 		}
 	}
 
@@ -458,35 +490,8 @@ type EffectiveConfig struct {
 
 // GetTeamConfigPath gets configuration file path
 func GetTeamConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		// Return default file in current directory on error
-		return ".claude-code-agents.conf"
-	}
-
-	// Unified configuration directory path
-	configDir := filepath.Join(homeDir, ".claude", "claude-code-agents")
-
-	// Priority 1: Configuration file in claude-code-agents directory
-	claudConfigPath := filepath.Join(configDir, "agents.conf")
-	if _, err := os.Stat(claudConfigPath); err == nil {
-		return claudConfigPath
-	}
-
-	// Priority 2: Configuration file in home directory
-	homeConfigPath := filepath.Join(homeDir, ".claude-code-agents.conf")
-	if _, err := os.Stat(homeConfigPath); err == nil {
-		return homeConfigPath
-	}
-
-	// Priority 3: Configuration file in current directory
-	currentConfigPath := ".claude-code-agents.conf"
-	if _, err := os.Stat(currentConfigPath); err == nil {
-		return currentConfigPath
-	}
-
-	// Default path (in unified directory)
-	return claudConfigPath
+	// Shared with send-agent, which reads CONFIG_DIR from the same file
+	return manifest.TeamConfigPath()
 }
 
 // LoadTeamConfig loads configuration file (no parameter version)
@@ -557,6 +562,7 @@ LOG_LEVEL=%s
 SESSION_NAME=%s
 DEFAULT_LAYOUT=%s
 AUTO_ATTACH=%t
+DEDICATED_SOCKET=%t
 IDE_BACKUP_ENABLED=%t
 
 # Commands
@@ -565,7 +571,8 @@ BINARY_NAME=%s
 
 # Developer Settings
 DEV_COUNT=%d
-
+DEVS_PER_WINDOW=%d
+%s%s%s
 # Role-based Instructions
 PO_INSTRUCTION_FILE=%s
 MANAGER_INSTRUCTION_FILE=%s
@@ -588,10 +595,15 @@ PROCESS_TIMEOUT=%s
 		config.SessionName,
 		config.DefaultLayout,
 		config.AutoAttach,
+		config.DedicatedSocket,
 		config.IDEBackupEnabled,
 		config.SendCommand,
 		config.BinaryName,
 		config.DevCount,
+		config.DevsPerWindow,
+		formatGroups(config.Groups),
+		formatRoutes(config.Routes),
+		formatLayouts(config.Layouts),
 		config.POInstructionFile,
 		config.ManagerInstructionFile,
 		config.DevInstructionFile,
@@ -606,6 +618,133 @@ PROCESS_TIMEOUT=%s
 	return os.WriteFile(tcl.configPath, []byte(content), 0600)
 }
 
+// groupKeyPrefix is the configuration key prefix of recipient groups
+const groupKeyPrefix = "GROUP_"
+
+// setGroup registers a recipient group from a GROUP_<NAME> entry; invalid entries are skipped
+func (tc *TeamConfig) setGroup(key, value string) {
+	name := strings.ToLower(key)
+	if err := topology.ValidateGroupName(name); err != nil {
+		log.Warn().Err(err).Str("key", groupKeyPrefix+key).Msg("Ignoring recipient group")
+		return
+	}
+
+	var members []string
+	for _, member := range topology.SplitRecipients(value) {
+		if !topology.IsAgentName(member) {
+			log.Warn().Str("group", name).Str("member", member).Msg("Ignoring invalid group member")
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(members) == 0 {
+		return
+	}
+
+	if tc.Groups == nil {
+		tc.Groups = make(map[string][]string)
+	}
+	tc.Groups[name] = members
+}
+
+// formatGroups renders recipient groups as GROUP_<NAME> lines in name order
+func formatGroups(groups map[string][]string) string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s%s=%s\n", groupKeyPrefix, strings.ToUpper(name), strings.Join(groups[name], ","))
+	}
+	return b.String()
+}
+
+// routeKeyPrefix is the configuration key prefix of the routing policy
+const routeKeyPrefix = "ROUTE_"
+
+// setRoute registers the allowed recipients of a role from a ROUTE_<ROLE> entry; invalid entries are skipped.
+// An empty value forbids the role to message anybody.
+func (tc *TeamConfig) setRoute(key, value string) {
+	role := strings.ToLower(key)
+	recipients := topology.SplitRecipients(strings.ToLower(value))
+	if err := topology.ValidateRoute(role, recipients); err != nil {
+		log.Warn().Err(err).Str("key", routeKeyPrefix+key).Msg("Ignoring routing rule")
+		return
+	}
+
+	if tc.Routes == nil {
+		tc.Routes = make(map[string][]string)
+	}
+	tc.Routes[role] = append([]string{}, recipients...)
+}
+
+// formatRoutes renders the routing policy as ROUTE_<ROLE> lines in role name order
+func formatRoutes(routes map[string][]string) string {
+	roles := make([]string, 0, len(routes))
+	for role := range routes {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	var b strings.Builder
+	for _, role := range roles {
+		fmt.Fprintf(&b, "%s%s=%s\n", routeKeyPrefix, strings.ToUpper(role), strings.Join(routes[role], ","))
+	}
+	return b.String()
+}
+
+// layoutKeyPrefix is the configuration key prefix of pane layouts
+const layoutKeyPrefix = "LAYOUT_"
+
+// individualLayout is the DEFAULT_LAYOUT value launching one session per agent
+const individualLayout = "individual"
+
+// setLayout registers a pane layout from a LAYOUT_<NAME> entry; invalid entries are skipped
+func (tc *TeamConfig) setLayout(key, value string) {
+	name := strings.ToLower(key)
+	if err := layout.ValidateName(name); err != nil || name == individualLayout {
+		log.Warn().Err(err).Str("key", layoutKeyPrefix+key).Msg("Ignoring layout with a reserved or invalid name")
+		return
+	}
+	if err := layout.Validate(value); err != nil {
+		log.Warn().Err(err).Str("key", layoutKeyPrefix+key).Msg("Ignoring layout")
+		return
+	}
+
+	if tc.Layouts == nil {
+		tc.Layouts = make(map[string]string)
+	}
+	tc.Layouts[name] = value
+}
+
+// formatLayouts renders pane layouts as LAYOUT_<NAME> lines in name order
+func formatLayouts(layouts map[string]string) string {
+	names := make([]string, 0, len(layouts))
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s%s=%s\n", layoutKeyPrefix, strings.ToUpper(name), layouts[name])
+	}
+	return b.String()
+}
+
+// LayoutWindows spreads the team over windows and resolves DEFAULT_LAYOUT for each;
+// the individual launch mode uses the integrated layout
+func (tc *TeamConfig) LayoutWindows() ([]layout.Window, error) {
+	name := tc.DefaultLayout
+	if name == individualLayout {
+		name = layout.Integrated
+	}
+	return layout.Windows(name, tc.Layouts, tc.Topology().Names(), tc.DevsPerWindow)
+}
+
 // GetDevCount gets developer count
 func (tc *TeamConfig) GetDevCount() int {
 	return tc.DevCount
@@ -622,35 +761,44 @@ func (tc *TeamConfig) SetDevCount(count int) {
 
 // GetAgentList gets dynamic agent list
 func (tc *TeamConfig) GetAgentList() []string {
-	agents := []string{"po", "manager"}
-	for i := 1; i <= tc.DevCount; i++ {
-		agents = append(agents, fmt.Sprintf("dev%d", i))
-	}
-	return agents
+	return tc.Topology().Names()
 }
 
 // GetPaneAgentMap gets dynamic pane-agent map
 func (tc *TeamConfig) GetPaneAgentMap() map[string]string {
 	paneMap := make(map[string]string)
-	paneMap["1"] = "po"
-	paneMap["2"] = "manager"
-	for i := 1; i <= tc.DevCount; i++ {
-		paneMap[fmt.Sprintf("%d", i+2)] = fmt.Sprintf("dev%d", i)
+	for i, name := range tc.paneOrder() {
+		paneMap[fmt.Sprintf("%d", i+1)] = name
 	}
 	return paneMap
 }
 
 // GetPaneTitles gets dynamic pane title map
 func (tc *TeamConfig) GetPaneTitles() map[string]string {
+	team := tc.Topology()
 	titles := make(map[string]string)
-	titles["1"] = "PO"
-	titles["2"] = "Manager"
-	for i := 1; i <= tc.DevCount; i++ {
-		titles[fmt.Sprintf("%d", i+2)] = fmt.Sprintf("Dev%d", i)
+	for i, name := range tc.paneOrder() {
+		if agent, ok := team.Find(name); ok {
+			titles[fmt.Sprintf("%d", i+1)] = agent.Title
+		}
 	}
 	return titles
 }
 
+// paneOrder returns the agents in window and pane order of the configured layout (team order when it cannot be resolved)
+func (tc *TeamConfig) paneOrder() []string {
+	windows, err := tc.LayoutWindows()
+	if err != nil {
+		return tc.Topology().Names()
+	}
+	return layout.Agents(windows)
+}
+
+// Topology gets the team topology for the configured developer count
+func (tc *TeamConfig) Topology() topology.Team {
+	return topology.New(tc.DevCount)
+}
+
 // === New methods (dynamic instruction feature) ===
 
 // GetInstructionResolver gets instruction resolver
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"sync"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // InstructionResolverInterface defines instruction resolver interface
@@ -124,12 +126,12 @@ func (ir *InstructionResolver) getEnvironmentSpecificPath(role, environment stri
 		return ""
 	}
 
-	switch role {
-	case "po":
+	switch roleKey(role) {
+	case topology.RolePO:
 		return envConfig.POInstructionPath
-	case "manager":
+	case topology.RoleManager:
 		return envConfig.ManagerInstructionPath
-	case "dev", "dev1", "dev2", "dev3", "dev4":
+	case topology.RoleDev:
 		return envConfig.DevInstructionPath
 	default:
 		return ""
@@ -142,12 +144,12 @@ func (ir *InstructionResolver) getBasePath(role string) string {
 		return ""
 	}
 
-	switch role {
-	case "po":
+	switch roleKey(role) {
+	case topology.RolePO:
 		return ir.config.InstructionConfig.Base.POInstructionPath
-	case "manager":
+	case topology.RoleManager:
 		return ir.config.InstructionConfig.Base.ManagerInstructionPath
-	case "dev", "dev1", "dev2", "dev3", "dev4":
+	case topology.RoleDev:
 		return ir.config.InstructionConfig.Base.DevInstructionPath
 	default:
 		return ""
@@ -156,12 +158,12 @@ func (ir *InstructionResolver) getBasePath(role string) string {
 
 // getLegacyPath gets legacy configuration path
 func (ir *InstructionResolver) getLegacyPath(role string) string {
-	switch role {
-	case "po":
+	switch roleKey(role) {
+	case topology.RolePO:
 		return ir.config.POInstructionFile
-	case "manager":
+	case topology.RoleManager:
 		return ir.config.ManagerInstructionFile
-	case "dev", "dev1", "dev2", "dev3", "dev4":
+	case topology.RoleDev:
 		return ir.config.DevInstructionFile
 	default:
 		return ""
@@ -176,12 +178,12 @@ func (ir *InstructionResolver) getDefaultPath(role string) string {
 		extension = ir.config.InstructionConfig.Global.DefaultExtension
 	}
 
-	switch role {
-	case "po":
+	switch roleKey(role) {
+	case topology.RolePO:
 		return "po" + extension
-	case "manager":
+	case topology.RoleManager:
 		return "manager" + extension
-	case "dev", "dev1", "dev2", "dev3", "dev4":
+	case topology.RoleDev:
 		return "developer" + extension
 	default:
 		return role + extension
@@ -190,7 +192,24 @@ func (ir *InstructionResolver) getDefaultPath(role string) string {
 
 // GetAvailableRoles 利用可能なロール一覧を取得
 func (ir *InstructionResolver) GetAvailableRoles() []string {
-	return []string{"po", "manager", "dev", "dev1", "dev2", "dev3", "dev4"}
+	devCount := topology.DefaultDevCount
+	if ir.config.DevCount > 0 {
+		devCount = ir.config.DevCount
+	}
+
+	roles := []string{topology.RolePO, topology.RoleManager, topology.RoleDev}
+	for _, dev := range topology.New(devCount).Developers() {
+		roles = append(roles, dev.Name)
+	}
+	return roles
+}
+
+// roleKey maps an agent name (e.g. dev5) to its role; unknown names are returned unchanged
+func roleKey(role string) string {
+	if r, ok := topology.RoleOf(role); ok {
+		return r
+	}
+	return role
 }
 
 // ValidateInstructionPaths すべてのinstructionパスを検証
@@ -3,8 +3,8 @@ package launcher
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +12,8 @@ import (
 	"github.com/shivase/claude-code-agents/internal/process"
 	"github.com/shivase/claude-code-agents/internal/tmux"
 	"github.com/shivase/claude-code-agents/internal/utils"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // ClaudeLauncher Claude CLI起動用のヘルパー
@@ -52,7 +54,7 @@ func (cl *ClaudeLauncher) LaunchClaude(target string) error {
 
 	// tmux環境で既存認証を強制使用するための環境変数を設定
 	envSetCmd := fmt.Sprintf("export CLAUDE_CONFIG_DIR=\"%s\"", configDir)
-	cmd := exec.Command("tmux", "send-keys", "-t", target, envSetCmd, "C-m") // #nosec G204
+	cmd := tmuxsocket.Command("send-keys", "-t", target, envSetCmd, "C-m")
 	if err := cmd.Run(); err != nil {
 		log.Warn().Err(err).Msg("⚠️ 環境変数設定警告")
 	}
@@ -86,7 +88,7 @@ func (cl *ClaudeLauncher) launchInPane(paneTarget, claudeCmd string) error {
 	log.Info().Str("pane", paneTarget).Msg("Launching Claude CLI in pane")
 
 	// ペインにClaude CLIを送信
-	cmd := exec.Command("tmux", "send-keys", "-t", paneTarget, claudeCmd, "C-m")
+	cmd := tmuxsocket.Command("send-keys", "-t", paneTarget, claudeCmd, "C-m")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to send Claude CLI command to pane %s: %w", paneTarget, err)
 	}
@@ -114,7 +116,7 @@ func (cl *ClaudeLauncher) launchInSession(sessionName, claudeCmd string) error {
 	log.Info().Str("session", sessionName).Msg("Launching Claude CLI in session")
 
 	// セッションにClaude CLIを送信
-	cmd := exec.Command("tmux", "send-keys", "-t", sessionName, claudeCmd, "C-m")
+	cmd := tmuxsocket.Command("send-keys", "-t", sessionName, claudeCmd, "C-m")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to send Claude CLI command to session %s: %w", sessionName, err)
 	}
@@ -141,8 +143,8 @@ func (cl *ClaudeLauncher) StartAllAgents() error {
 	// 統合監視画面の場合
 	if cl.tmuxManager.SessionExists(cl.config.SessionName) {
 		paneCount, err := cl.tmuxManager.GetPaneCount(cl.config.SessionName)
-		if err == nil && paneCount == 6 {
-			utils.DisplayInfo("Integrated Monitoring Mode", "Launching Claude CLI in 6 panes")
+		if team := cl.config.Team(); err == nil && paneCount == team.PaneCount() {
+			utils.DisplayInfo("Integrated Monitoring Mode", fmt.Sprintf("Launching Claude CLI in %d panes", paneCount))
 			return cl.startIntegratedAgents()
 		}
 	}
@@ -154,22 +156,18 @@ func (cl *ClaudeLauncher) StartAllAgents() error {
 
 // startIntegratedAgents 統合監視画面の各ペインでClaude CLIを起動（認証競合防止のため順次実行）
 func (cl *ClaudeLauncher) startIntegratedAgents() error {
-	agents := []struct {
-		pane int
-		name string
-		file string
-	}{
-		{1, "PO", "po.md"},
-		{2, "Manager", "manager.md"},
-		{3, "Dev1", "developer.md"},
-		{4, "Dev2", "developer.md"},
-		{5, "Dev3", "developer.md"},
-		{6, "Dev4", "developer.md"},
+	paneIDs, err := cl.tmuxManager.GetPaneIDs(cl.config.SessionName)
+	if err != nil {
+		return err
+	}
+	agents, err := paneAgents(cl.config, paneIDs)
+	if err != nil {
+		return err
 	}
 
 	// 認証ファイル競合を防ぐため、順次実行に変更
 	for i, agent := range agents {
-		paneTarget := fmt.Sprintf("%s:1.%d", cl.config.SessionName, agent.pane)
+		paneTarget := agent.id
 
 		utils.DisplayProgress("Claude CLI起動", fmt.Sprintf("%s (ペイン%d) - %d/%d", agent.name, agent.pane, i+1, len(agents)))
 
@@ -203,11 +201,12 @@ func (cl *ClaudeLauncher) startIntegratedAgents() error {
 
 // startIndividualAgents 個別セッションでClaude CLIを起動（認証競合防止のため順次実行）
 func (cl *ClaudeLauncher) startIndividualAgents() error {
-	agents := []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}
+	agents := cl.config.Team().Agents()
 
 	// 認証ファイル競合を防ぐため、順次実行に変更
-	for i, agent := range agents {
-		sessionName := fmt.Sprintf("%s-%s", cl.config.SessionName, agent)
+	for i, teamAgent := range agents {
+		agent := teamAgent.Name
+		sessionName := topology.AgentSessionName(cl.config.SessionName, agent)
 
 		if !cl.tmuxManager.SessionExists(sessionName) {
 			utils.DisplayInfo("セッション確認", fmt.Sprintf("セッション %s が存在しません", sessionName))
@@ -225,15 +224,7 @@ func (cl *ClaudeLauncher) startIndividualAgents() error {
 		time.Sleep(5 * time.Second)
 
 		// インストラクションファイルを送信
-		var instructionFile string
-		switch agent {
-		case "po":
-			instructionFile = "po.md"
-		case "manager":
-			instructionFile = "manager.md"
-		default:
-			instructionFile = "developer.md"
-		}
+		instructionFile := instructionFileFor(teamAgent.Role)
 
 		utils.DisplayProgress("インストラクション送信", fmt.Sprintf("%s にインストラクションを送信中...", agent))
 
@@ -254,41 +245,89 @@ func (cl *ClaudeLauncher) startIndividualAgents() error {
 	return nil
 }
 
+// paneAgent ペイン番号・ペインIDとエージェントの対応
+type paneAgent struct {
+	pane int
+	id   string
+	name string
+	file string
+}
+
+// paneAgents レイアウトのペイン順にペイン配置を作成（ペイン番号は1始まり）
+// paneIDs はセッションのペインID（%N）をペイン順に並べたもので、base-index の設定に関係なくペインを指定するために使う
+func paneAgents(config *LauncherConfig, paneIDs []string) ([]paneAgent, error) {
+	team := config.Team()
+	order := paneOrder(config)
+	if len(paneIDs) != len(order) {
+		return nil, fmt.Errorf("session %s has %d panes but the layout has %d", config.SessionName, len(paneIDs), len(order))
+	}
+
+	agents := make([]paneAgent, 0, team.PaneCount())
+	for i, name := range order {
+		if agent, ok := team.Find(name); ok {
+			agents = append(agents, paneAgent{i + 1, paneIDs[i], agent.Title, instructionFileFor(agent.Role)})
+		}
+	}
+	return agents, nil
+}
+
+// paneOrder レイアウトのペイン順のエージェント名（レイアウトを解決できない場合はチーム順）
+func paneOrder(config *LauncherConfig) []string {
+	plan, err := config.LayoutPlan()
+	if err != nil {
+		return config.Team().Names()
+	}
+	return plan.Agents
+}
+
+// agentForPane ペイン指定（ペインID "%5" またはペイン番号 "3"）からエージェントを推定
+func (cl *ClaudeLauncher) agentForPane(sessionName, pane string) (topology.Agent, bool) {
+	var paneNumber int
+	if strings.HasPrefix(pane, "%") {
+		paneIDs, err := cl.tmuxManager.GetPaneIDs(sessionName)
+		if err != nil {
+			return topology.Agent{}, false
+		}
+		paneNumber = indexOf(paneIDs, pane) + 1
+	} else {
+		number, err := strconv.Atoi(pane)
+		if err != nil {
+			return topology.Agent{}, false
+		}
+		paneNumber = number
+	}
+	order := paneOrder(cl.config)
+	if paneNumber < 1 || paneNumber > len(order) {
+		return topology.Agent{}, false
+	}
+	return cl.config.Team().Find(order[paneNumber-1])
+}
+
 // SendInstructionToAgent エージェントにインストラクションを送信
 func (cl *ClaudeLauncher) SendInstructionToAgent(target, instructionFile string) error {
 	log.Info().Str("instruction_file", instructionFile).Str("target", target).Msg("📤 Starting instruction sending")
 
-	// Determine if target is pane format (session:pane) or session format
-	if strings.Contains(target, ":") {
+	// Determine if target is pane format (pane ID or session:pane) or session format
+	if strings.HasPrefix(target, "%") || strings.Contains(target, ":") {
 		// For pane format, use sendInstructionToPaneWithConfig
-		parts := strings.Split(target, ":")
-		sessionName := parts[0]
-		pane := parts[1]
+		sessionName, pane := cl.config.SessionName, target
+		if parts := strings.SplitN(target, ":", 2); len(parts) == 2 {
+			sessionName, pane = parts[0], parts[1]
+		}
 
-		// Estimate agent name (from pane number)
+		// Estimate agent name (from pane ID or number)
 		var agent string
-		switch {
-		case strings.Contains(pane, ".1"):
-			agent = "po"
-		case strings.Contains(pane, ".2"):
-			agent = "manager"
-		case strings.Contains(pane, ".3"):
-			agent = "dev1"
-		case strings.Contains(pane, ".4"):
-			agent = "dev2"
-		case strings.Contains(pane, ".5"):
-			agent = "dev3"
-		case strings.Contains(pane, ".6"):
-			agent = "dev4"
-		default:
+		if teamAgent, ok := cl.agentForPane(sessionName, pane); ok {
+			agent = teamAgent.Name
+		} else {
 			// Estimate agent name from instructionFile as default
 			switch instructionFile {
 			case "po.md":
-				agent = "po"
+				agent = topology.AgentPO
 			case "manager.md":
-				agent = "manager"
+				agent = topology.AgentManager
 			default:
-				agent = "dev1"
+				agent = topology.DevName(1)
 			}
 		}
 
@@ -321,7 +360,7 @@ func (cl *ClaudeLauncher) SendInstructionToAgent(target, instructionFile string)
 	log.Info().Str("read_cmd", readCmd).Msg("📋 Sending instruction read command")
 
 	// Send cat command
-	cmd := exec.Command("tmux", "send-keys", "-t", target, readCmd, "C-m") // #nosec G204
+	cmd := tmuxsocket.Command("send-keys", "-t", target, readCmd, "C-m")
 	if err := cmd.Run(); err != nil {
 		log.Warn().Err(err).Msg("⚠️ Instruction read command sending error")
 		return fmt.Errorf("failed to send instruction read command: %w", err)
@@ -338,7 +377,7 @@ func (cl *ClaudeLauncher) SendInstructionToAgent(target, instructionFile string)
 
 	// Send Enter multiple times to put Claude CLI in execution state
 	for i := 0; i < 5; i++ {
-		cmd = exec.Command("tmux", "send-keys", "-t", target, "C-m")
+		cmd = tmuxsocket.Command("send-keys", "-t", target, "C-m")
 		if err := cmd.Run(); err != nil {
 			log.Warn().Err(err).Int("attempt", i+1).Msg("⚠️ Enter sending error")
 		}
@@ -363,3 +402,13 @@ func (cl *ClaudeLauncher) optimizeClaudeCLIDisplay() {
 	// Since script command was removed, Claude CLI automatically recognizes pane size
 	// No special optimization processing required
 }
+
+// indexOf スライス内の要素の位置（見つからない場合は -1）
+func indexOf(items []string, item string) int {
+	for i, v := range items {
+		if v == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -10,9 +10,12 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/shivase/claude-code-agents/internal/auth"
+	"github.com/shivase/claude-code-agents/internal/layout"
 	"github.com/shivase/claude-code-agents/internal/process"
 	"github.com/shivase/claude-code-agents/internal/tmux"
 	"github.com/shivase/claude-code-agents/internal/utils"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // ValidateEnvironment validates system environment
@@ -102,6 +105,36 @@ type LauncherConfig struct {
 	WorkingDir      string
 	InstructionsDir string
 	ClaudePath      string
+	DevCount        int
+	// PaneLayout is the pane layout of the integrated session (see internal/layout)
+	PaneLayout string
+	// Layouts are the layouts defined as LAYOUT_<NAME> entries
+	Layouts map[string]string
+}
+
+// Team returns the team topology (defaults to 4 developers when DevCount is not set)
+func (c *LauncherConfig) Team() topology.Team {
+	if c.DevCount <= 0 {
+		return topology.New(topology.DefaultDevCount)
+	}
+	return topology.New(c.DevCount)
+}
+
+// LayoutPlan resolves the pane layout for the team
+func (c *LauncherConfig) LayoutPlan() (*layout.Plan, error) {
+	return layout.Resolve(c.PaneLayout, c.Layouts, c.Team().Names())
+}
+
+// instructionFileFor returns the default instruction file name of a role
+func instructionFileFor(role string) string {
+	switch role {
+	case topology.RolePO:
+		return "po.md"
+	case topology.RoleManager:
+		return "manager.md"
+	default:
+		return "developer.md"
+	}
 }
 
 // SystemLauncher system launcher
@@ -181,9 +214,8 @@ func (sl *SystemLauncher) startIndividualSessions() error {
 	}
 
 	// Create sessions for each agent
-	agents := []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}
-	for _, agent := range agents {
-		sessionName := fmt.Sprintf("%s-%s", sl.config.SessionName, agent)
+	for _, agent := range sl.config.Team().Names() {
+		sessionName := topology.AgentSessionName(sl.config.SessionName, agent)
 
 		if sl.tmuxManager.SessionExists(sessionName) {
 			if utils.IsVerboseLogging() {
@@ -258,18 +290,18 @@ func (sl *SystemLauncher) startIntegratedMonitor() error {
 
 	// Create integrated layout
 	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("Integrated layout creation", "Creating 6-pane integrated layout...")
+		utils.DisplayProgress("Integrated layout creation", "Creating integrated layout...")
 	}
 	if err := sl.createIntegratedLayout(); err != nil {
 		utils.DisplayError("Integrated layout creation failed", err)
 		return fmt.Errorf("failed to create integrated layout: %w", err)
 	}
 	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("Integrated layout creation completed", "6-pane integrated layout has been created")
+		utils.DisplaySuccess("Integrated layout creation completed", "Integrated layout has been created")
 	}
 
 	// Deploy agents to each pane
-	log.Info().Msg("🔄 Agent deployment Deploying agents to 6 panes")
+	log.Info().Msg("🔄 Agent deployment Deploying agents to panes")
 	sl.setupAgentsInPanes()
 	log.Info().Msg("✅ Agent deployment completed All agents have been deployed successfully")
 
@@ -285,82 +317,17 @@ func (sl *SystemLauncher) startIntegratedMonitor() error {
 	return nil
 }
 
-// createIntegratedLayout creates integrated layout (same configuration as claude.sh)
+// createIntegratedLayout creates the panes of the integrated session arranged by the configured layout
 func (sl *SystemLauncher) createIntegratedLayout() error {
-	sessionName := sl.config.SessionName
-
-	// Create 6-pane configuration step by step (same configuration as claude.sh)
-	log.Info().Msg("Creating 6-pane layout (claude.sh compatible)...")
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("6ペインレイアウト作成", "claude.shと同じ6ペイン構成を段階的に作成中...")
-	}
-
-	// 1. 左右分割（左側、右側）
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("左右分割", "左右分割を作成中...")
-	}
-	if err := sl.executeCommand(fmt.Sprintf("split-window -h -t %s", sessionName)); err != nil {
-		utils.DisplayError("左右分割失敗", err)
-		return fmt.Errorf("failed to create horizontal split: %w", err)
-	}
-	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("左右分割完了", "左右分割が作成されました (ペイン0,1)")
-	}
-	log.Debug().Msg("✓ Horizontal split created (panes 0,1)")
-
-	// 2. 左側を上下分割（上: PO、下: Manager）
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("左側上下分割", "左側ペインを上下分割中（PO/Manager）...")
-	}
-	if err := sl.executeCommand(fmt.Sprintf("split-window -v -t %s:1.1", sessionName)); err != nil {
-		utils.DisplayError("左側上下分割失敗", err)
-		return fmt.Errorf("failed to split left pane: %w", err)
-	}
-	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("左側上下分割完了", "左側ペインが上下分割されました (PO/Manager)")
-	}
-	log.Debug().Msg("✓ Left pane split for PO/Manager (panes 0,1,2)")
-
-	// 3. 右側を上下分割（上: Dev1、下: 残り）
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("右側上下分割", "右側ペインを上下分割中（Dev1/残り）...")
-	}
-	if err := sl.executeCommand(fmt.Sprintf("split-window -v -t %s:1.2", sessionName)); err != nil {
-		utils.DisplayError("右側上下分割失敗", err)
-		return fmt.Errorf("failed to split right pane: %w", err)
-	}
-	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("右側上下分割完了", "右側ペインが上下分割されました (Dev1/残り)")
-	}
-	log.Debug().Msg("✓ Right pane split for Dev1 (panes 0,1,2,3)")
-
-	// 4. 右下をさらに分割（Dev2用）
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("右下分割", "右下ペインをさらに分割中（Dev2用）...")
-	}
-	if err := sl.executeCommand(fmt.Sprintf("split-window -v -t %s:1.3", sessionName)); err != nil {
-		utils.DisplayError("右下分割失敗", err)
-		return fmt.Errorf("failed to split bottom right pane for Dev2: %w", err)
-	}
-	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("右下分割完了", "右下ペインが分割されました (Dev2用)")
+	plan, err := sl.config.LayoutPlan()
+	if err != nil {
+		return fmt.Errorf("invalid layout: %w", err)
 	}
-	log.Debug().Msg("✓ Bottom right split for Dev2 (panes 0,1,2,3,4)")
 
-	// 5. 最後のペインを分割（Dev3用）
-	if utils.IsVerboseLogging() {
-		utils.DisplayProgress("最終分割", "最後のペインを分割中（Dev3用）...")
-	}
-	if err := sl.executeCommand(fmt.Sprintf("split-window -v -t %s:1.4", sessionName)); err != nil {
-		utils.DisplayError("最終分割失敗", err)
-		return fmt.Errorf("failed to split last pane for Dev3: %w", err)
-	}
-	if utils.IsVerboseLogging() {
-		utils.DisplaySuccess("最終分割完了", "最終分割が完了しました (Dev3用)")
+	log.Info().Str("layout", plan.Name).Int("panes", len(plan.Agents)).Msg("Creating pane layout...")
+	if err := sl.tmuxManager.CreateLayout(sl.config.SessionName, plan); err != nil {
+		return err
 	}
-	log.Debug().Msg("✓ Final split for Dev3 (panes 0,1,2,3,4,5)")
-
-	log.Info().Msg("6-pane layout created successfully (claude.sh compatible)")
 
 	// レイアウト最適化
 	if utils.IsVerboseLogging() {
@@ -378,25 +345,6 @@ func (sl *SystemLauncher) createIntegratedLayout() error {
 func (sl *SystemLauncher) optimizeLayout() {
 	sessionName := sl.config.SessionName
 
-	// ペインサイズを最適化（左側50%、右側50%に正確に分割）
-	resizeCommands := []string{
-		// 最初に左側ペイン（PO+Manager）を50%に設定
-		fmt.Sprintf("resize-pane -t %s:1.1 -p 50", sessionName), // 左側全体を50%に
-		// 左側内部でPOとManagerを上下均等分割
-		fmt.Sprintf("resize-pane -t %s:1.2 -p 50", sessionName), // Manager を左側の50%に
-		// 右側のペイン群は自動的に残りの50%を占有する
-		// 右側内部でDev1-4を均等分割（25%ずつ）
-		fmt.Sprintf("resize-pane -t %s:1.4 -p 25", sessionName), // Dev2
-		fmt.Sprintf("resize-pane -t %s:1.5 -p 25", sessionName), // Dev3
-		fmt.Sprintf("resize-pane -t %s:1.6 -p 25", sessionName), // Dev4
-	}
-
-	for _, cmd := range resizeCommands {
-		if err := sl.executeCommand(cmd); err != nil {
-			log.Warn().Err(err).Str("cmd", cmd).Msg("Failed to execute resize command")
-		}
-	}
-
 	// Claude CLI表示最適化のためのtmux設定
 	optimizationCommands := []string{
 		// ペインタイトルの設定
@@ -444,18 +392,16 @@ func (sl *SystemLauncher) optimizeLayout() {
 
 // setupAgentsInPanes 各ペインにエージェントを配置（claude.shと同じ構成）
 func (sl *SystemLauncher) setupAgentsInPanes() {
-	// claude.shと同じ構成: 左側にPO/Manager、右側にDev1-Dev4
-	agents := []struct {
-		pane int
-		name string
-		file string
-	}{
-		{1, "PO", "po.md"},           // 左上
-		{2, "Manager", "manager.md"}, // 左下
-		{3, "Dev1", "developer.md"},  // 右上
-		{4, "Dev2", "developer.md"},  // 右上中
-		{5, "Dev3", "developer.md"},  // 右下中
-		{6, "Dev4", "developer.md"},  // 右下
+	// claude.shと同じ構成: 左側にPO/Manager、右側にDev1-DevN
+	paneIDs, err := sl.tmuxManager.GetPaneIDs(sl.config.SessionName)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get pane IDs")
+		return
+	}
+	agents, err := paneAgents(sl.config, paneIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to assign agents to panes")
+		return
 	}
 
 	// 順次実行（並列実行を避けるため）
@@ -467,12 +413,11 @@ func (sl *SystemLauncher) setupAgentsInPanes() {
 		}
 
 		// ペインタイトルを設定
-		paneTarget := fmt.Sprintf("%s:1.%d", sl.config.SessionName, agent.pane)
-		if err := sl.executeCommand(fmt.Sprintf("select-pane -t %s -T %s", paneTarget, agent.name)); err != nil {
+		if err := sl.executeCommand(fmt.Sprintf("select-pane -t %s -T %s", agent.id, agent.name)); err != nil {
 			log.Warn().Err(err).Msgf("Failed to set pane title for %s", agent.name)
 		}
 
-		sl.setupAgent(agent.pane, agent.name, agent.file)
+		sl.setupAgent(agent.id, agent.name, agent.file)
 
 		if utils.IsVerboseLogging() {
 			utils.DisplaySuccess("エージェント配置完了", fmt.Sprintf("%s エージェントがペイン%dに配置されました", agent.name, agent.pane))
@@ -488,10 +433,8 @@ func (sl *SystemLauncher) setupAgentsInPanes() {
 	}
 }
 
-// setupAgent エージェントをペインにセットアップ
-func (sl *SystemLauncher) setupAgent(pane int, name, instructionFile string) {
-	sessionName := sl.config.SessionName
-	paneTarget := fmt.Sprintf("%s:1.%d", sessionName, pane)
+// setupAgent エージェントをペイン（ペインID）にセットアップ
+func (sl *SystemLauncher) setupAgent(paneTarget, name, instructionFile string) {
 
 	// ペインタイトルを設定
 	if utils.IsVerboseLogging() {
@@ -515,7 +458,7 @@ func (sl *SystemLauncher) setupAgent(pane int, name, instructionFile string) {
 		utils.DisplayProgress("ペインサイズ確認", fmt.Sprintf("%s のペインサイズを確認中...", name))
 
 		// ログ出力用にペインサイズを取得（コマンド送信はしない）
-		cmd := exec.Command("tmux", "display-message", "-t", paneTarget, "-p", "#{pane_width}x#{pane_height}") // #nosec G204
+		cmd := tmuxsocket.Command("display-message", "-t", paneTarget, "-p", "#{pane_width}x#{pane_height}")
 		sizeOutput, err := cmd.Output()
 		if err == nil {
 			size := strings.TrimSpace(string(sizeOutput))
@@ -542,8 +485,8 @@ func (sl *SystemLauncher) setupAgent(pane int, name, instructionFile string) {
 	homeDir, _ := os.UserHomeDir()
 
 	// tmuxペインサイズを取得して環境変数に設定
-	widthCmd := exec.Command("tmux", "display-message", "-t", paneTarget, "-p", "#{pane_width}")   // #nosec G204
-	heightCmd := exec.Command("tmux", "display-message", "-t", paneTarget, "-p", "#{pane_height}") // #nosec G204
+	widthCmd := tmuxsocket.Command("display-message", "-t", paneTarget, "-p", "#{pane_width}")
+	heightCmd := tmuxsocket.Command("display-message", "-t", paneTarget, "-p", "#{pane_height}")
 
 	widthOutput, _ := widthCmd.Output()
 	heightOutput, _ := heightCmd.Output()
@@ -604,6 +547,9 @@ func (sl *SystemLauncher) setupAgent(pane int, name, instructionFile string) {
 			ClaudePath:      sl.config.ClaudePath,
 			WorkingDir:      sl.config.WorkingDir,
 			InstructionsDir: sl.config.InstructionsDir,
+			DevCount:        sl.config.DevCount,
+			PaneLayout:      sl.config.PaneLayout,
+			Layouts:         sl.config.Layouts,
 		})
 
 		if err := claudeLauncher.SendInstructionToAgent(paneTarget, instructionFile); err != nil {
@@ -683,7 +629,7 @@ func (sl *SystemLauncher) executeCommand(cmd string) error {
 
 	log.Debug().Str("command", cmd).Msg("Executing tmux command")
 
-	execCmd := exec.Command("tmux", parts...) // #nosec G204
+	execCmd := tmuxsocket.Command(parts...)
 	if output, err := execCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("tmux command failed: %s (output: %s)", cmd, string(output))
 	}
@@ -693,15 +639,14 @@ func (sl *SystemLauncher) executeCommand(cmd string) error {
 
 // sendKeys tmuxペインにキーを送信
 func (sl *SystemLauncher) sendKeys(target, keys string) error {
-	cmd := exec.Command("tmux", "send-keys", "-t", target, keys, "C-m")
+	cmd := tmuxsocket.Command("send-keys", "-t", target, keys, "C-m")
 	return cmd.Run()
 }
 
 // cleanupIndividualSessions 個別セッションをクリーンアップ
 func (sl *SystemLauncher) cleanupIndividualSessions() {
-	agents := []string{"po", "manager", "dev1", "dev2", "dev3", "dev4"}
-	for _, agent := range agents {
-		sessionName := fmt.Sprintf("%s-%s", sl.config.SessionName, agent)
+	for _, agent := range sl.config.Team().Names() {
+		sessionName := topology.AgentSessionName(sl.config.SessionName, agent)
 		if err := sl.tmuxManager.KillSession(sessionName); err != nil {
 			log.Warn().Err(err).Msgf("Failed to kill session %s", sessionName)
 		}
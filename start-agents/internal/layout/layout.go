@@ -0,0 +1,391 @@
+package layout
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Pane layouts of team sessions
+//
+// DEFAULT_LAYOUT selects how the agent panes of the team window are arranged:
+//
+//	integrated               PO and manager on the left, developers on the right
+//	                         (the developers form a grid once there are more than 4)
+//	grid, grid:<columns>     all agents in a grid, filled row by row
+//	tiled, main-vertical, main-horizontal, even-horizontal, even-vertical
+//	                         tmux built-in layouts
+//	h(...), v(...)           an explicit split tree
+//	<name>                   a layout defined as LAYOUT_<NAME>=<layout> in agents.conf
+//
+// A split tree places its children side by side (h) or stacked (v). A child may
+// take a share of its parent in percent; children without one split the rest
+// equally. The leaf "devs" stands for a grid of the developers not placed
+// elsewhere, so one tree fits any DEV_COUNT:
+//
+//	h(v(po,manager):35,devs)
+
+// Layout names
+const (
+	// Integrated is the default layout
+	Integrated = "integrated"
+	// Grid places all agents in a grid ("grid:<columns>" fixes the column count)
+	Grid = "grid"
+	// DevsLeaf is the split tree leaf standing for the remaining developers
+	DevsLeaf = "devs"
+)
+
+// Builtins are the tmux layouts applied with select-layout
+var Builtins = []string{"tiled", "main-vertical", "main-horizontal", "even-horizontal", "even-vertical"}
+
+// maxDevRows is the number of developer rows of the integrated layout before another column is added
+const maxDevRows = 4
+
+// maxNesting limits how deeply named layouts may refer to each other
+const maxNesting = 8
+
+// nameRegex matches the names of layouts defined in agents.conf
+var nameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Direction is the split direction of a node
+type Direction int
+
+const (
+	// Horizontal places children side by side, left to right
+	Horizontal Direction = iota + 1
+	// Vertical stacks children, top to bottom
+	Vertical
+)
+
+// Node is a cell of a split tree: a leaf holds an agent, a split holds children
+type Node struct {
+	Agent    string
+	Split    Direction
+	Children []*Node
+	// Percent is the share of the parent (0 = an equal part of what the other children leave)
+	Percent int
+}
+
+// Plan is a layout resolved for the agents of a team
+type Plan struct {
+	// Name is the layout as configured
+	Name string
+	// Builtin is the tmux layout applied with select-layout (empty for split trees)
+	Builtin string
+	// Root is the split tree (nil for built-in layouts)
+	Root *Node
+	// Agents lists the agents in pane order
+	Agents []string
+}
+
+// PaneIndex returns the 0-based pane index of the agent
+func (p *Plan) PaneIndex(agent string) (int, bool) {
+	for i, name := range p.Agents {
+		if name == agent {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// IsBuiltin reports whether name is a tmux built-in layout
+func IsBuiltin(name string) bool {
+	for _, builtin := range Builtins {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSplitTree reports whether the layout is written as a split tree
+func IsSplitTree(spec string) bool {
+	spec = strings.TrimSpace(spec)
+	return strings.HasPrefix(spec, "h(") || strings.HasPrefix(spec, "v(")
+}
+
+// ValidateName checks the name of a layout defined in agents.conf
+func ValidateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid layout name '%s' (lowercase letters, digits, '-' and '_')", name)
+	}
+	if name == Integrated || name == Grid || name == "h" || name == "v" || IsBuiltin(name) {
+		return fmt.Errorf("layout name '%s' is reserved", name)
+	}
+	return nil
+}
+
+// Validate checks a layout definition without a team (used for LAYOUT_<NAME> entries)
+func Validate(spec string) error {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return fmt.Errorf("layout is empty")
+	case IsSplitTree(spec):
+		_, err := Parse(spec)
+		return err
+	case spec == Integrated || IsBuiltin(spec):
+		return nil
+	case spec == Grid || strings.HasPrefix(spec, Grid+":"):
+		_, err := gridColumns(spec)
+		return err
+	default:
+		return fmt.Errorf("unknown layout '%s' (%s)", spec, strings.Join(Names(), ", "))
+	}
+}
+
+// Names returns the built-in layout names
+func Names() []string {
+	names := append([]string{Integrated, Grid}, Builtins...)
+	sort.Strings(names[2:])
+	return names
+}
+
+// Resolve resolves a layout name or definition for the agents (in team order).
+// custom holds the layouts defined in agents.conf by lower-case name.
+func Resolve(spec string, custom map[string]string, agents []string) (*Plan, error) {
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no agents to lay out")
+	}
+
+	name := strings.TrimSpace(spec)
+	if name == "" {
+		name = Integrated
+	}
+
+	definition, err := definitionOf(name, custom)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Name: name}
+	switch {
+	case IsBuiltin(definition):
+		plan.Builtin = definition
+		plan.Agents = append([]string(nil), agents...)
+		return plan, nil
+	case definition == Integrated:
+		plan.Root = Preset(agents)
+	case definition == Grid || strings.HasPrefix(definition, Grid+":"):
+		columns, err := gridColumns(definition)
+		if err != nil {
+			return nil, err
+		}
+		plan.Root = GridOf(agents, columns)
+	case IsSplitTree(definition):
+		root, err := Parse(definition)
+		if err != nil {
+			return nil, fmt.Errorf("layout '%s': %w", name, err)
+		}
+		if root, err = place(root, agents); err != nil {
+			return nil, fmt.Errorf("layout '%s': %w", name, err)
+		}
+		plan.Root = root
+	default:
+		return nil, fmt.Errorf("unknown layout '%s' (%s, or a LAYOUT_<NAME> entry)", name, strings.Join(Names(), ", "))
+	}
+
+	plan.Agents = plan.Root.Leaves()
+	return plan, nil
+}
+
+// definitionOf follows layout names defined in agents.conf to their definition
+func definitionOf(name string, custom map[string]string) (string, error) {
+	definition := name
+	for depth := 0; ; depth++ {
+		value, ok := custom[strings.ToLower(definition)]
+		if !ok {
+			return definition, nil
+		}
+		if depth >= maxNesting {
+			return "", fmt.Errorf("layout '%s' refers to itself", name)
+		}
+		definition = strings.TrimSpace(value)
+	}
+}
+
+// Preset returns the integrated layout for the agents: PO and manager stacked on the
+// left, the developers in a column on the right, or in a grid of up to 4 rows when
+// there are more of them
+func Preset(agents []string) *Node {
+	var leaders, devs []string
+	for _, agent := range agents {
+		if role, _ := topology.RoleOf(agent); role == topology.RoleDev {
+			devs = append(devs, agent)
+		} else {
+			leaders = append(leaders, agent)
+		}
+	}
+
+	switch {
+	case len(devs) == 0:
+		return split(Horizontal, leaves(leaders)...)
+	case len(leaders) == 0:
+		return devGrid(devs)
+	}
+
+	left := split(Vertical, leaves(leaders)...)
+	columns := devColumns(len(devs))
+	left.Percent = 50
+	if columns > 1 {
+		left.Percent = 100 / (columns + 1)
+		if left.Percent < 25 {
+			left.Percent = 25
+		}
+	}
+	return split(Horizontal, left, devGrid(devs))
+}
+
+// GridOf places the agents in a grid filled row by row (columns <= 0 picks a near-square grid)
+func GridOf(agents []string, columns int) *Node {
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(agents)))))
+	}
+	if columns >= len(agents) {
+		return split(Horizontal, leaves(agents)...)
+	}
+
+	var rows []*Node
+	for start := 0; start < len(agents); start += columns {
+		end := start + columns
+		if end > len(agents) {
+			end = len(agents)
+		}
+		rows = append(rows, split(Horizontal, leaves(agents[start:end])...))
+	}
+	return split(Vertical, rows...)
+}
+
+// devGrid arranges developers in a single column, or in columns of up to maxDevRows rows
+func devGrid(devs []string) *Node {
+	columns := devColumns(len(devs))
+	if columns == 1 {
+		return split(Vertical, leaves(devs)...)
+	}
+	return GridOf(devs, columns)
+}
+
+func devColumns(devs int) int {
+	if devs <= maxDevRows {
+		return 1
+	}
+	return (devs + maxDevRows - 1) / maxDevRows
+}
+
+func gridColumns(spec string) (int, error) {
+	value := strings.TrimPrefix(strings.TrimPrefix(spec, Grid), ":")
+	if value == "" {
+		return 0, nil
+	}
+	columns, err := strconv.Atoi(value)
+	if err != nil || columns < 1 {
+		return 0, fmt.Errorf("invalid grid column count '%s'", value)
+	}
+	return columns, nil
+}
+
+func split(direction Direction, children ...*Node) *Node {
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &Node{Split: direction, Children: children}
+}
+
+func leaves(agents []string) []*Node {
+	nodes := make([]*Node, len(agents))
+	for i, agent := range agents {
+		nodes[i] = &Node{Agent: agent}
+	}
+	return nodes
+}
+
+// Leaves returns the agents of the tree in pane order (depth first, left to right, top to bottom)
+func (n *Node) Leaves() []string {
+	if n.Split == 0 {
+		return []string{n.Agent}
+	}
+	var agents []string
+	for _, child := range n.Children {
+		agents = append(agents, child.Leaves()...)
+	}
+	return agents
+}
+
+// place expands the "devs" leaf and checks that the tree holds every agent exactly once
+func place(root *Node, agents []string) (*Node, error) {
+	team := map[string]bool{}
+	for _, agent := range agents {
+		team[agent] = true
+	}
+
+	placed := map[string]bool{}
+	devsLeaves := 0
+	for _, agent := range root.Leaves() {
+		switch {
+		case agent == DevsLeaf:
+			devsLeaves++
+		case !team[agent]:
+			return nil, fmt.Errorf("agent '%s' is not part of the team", agent)
+		case placed[agent]:
+			return nil, fmt.Errorf("agent '%s' is placed twice", agent)
+		default:
+			placed[agent] = true
+		}
+	}
+	if devsLeaves > 1 {
+		return nil, fmt.Errorf("'%s' may be used only once", DevsLeaf)
+	}
+
+	var remaining []string
+	for _, agent := range agents {
+		if placed[agent] {
+			continue
+		}
+		if role, _ := topology.RoleOf(agent); role != topology.RoleDev || devsLeaves == 0 {
+			return nil, fmt.Errorf("agent '%s' is not placed", agent)
+		}
+		remaining = append(remaining, agent)
+	}
+
+	root = expandDevs(root, remaining)
+	if root == nil {
+		return nil, fmt.Errorf("layout places no agents")
+	}
+	return root, nil
+}
+
+// expandDevs replaces the "devs" leaf by a grid of the developers, dropping it when there are none
+func expandDevs(n *Node, devs []string) *Node {
+	if n.Split == 0 {
+		if n.Agent != DevsLeaf {
+			return n
+		}
+		if len(devs) == 0 {
+			return nil
+		}
+		grid := devGrid(devs)
+		grid.Percent = n.Percent
+		return grid
+	}
+
+	children := n.Children[:0]
+	for _, child := range n.Children {
+		if child = expandDevs(child, devs); child != nil {
+			children = append(children, child)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		children[0].Percent = n.Percent
+		return children[0]
+	}
+	n.Children = children
+	return n
+}
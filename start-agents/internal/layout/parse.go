@@ -0,0 +1,131 @@
+package layout
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Parse parses a split tree such as "h(v(po,manager):35,devs)"
+func Parse(spec string) (*Node, error) {
+	p := &parser{input: strings.Join(strings.Fields(spec), "")}
+	node, err := p.node()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected '%c'", p.input[p.pos])
+	}
+	if node.Split == 0 {
+		return nil, fmt.Errorf("a split tree must start with h(...) or v(...)")
+	}
+	return node, nil
+}
+
+// parser reads split trees:
+//
+//	node  := agent | ("h" | "v") "(" child ("," child)* ")"
+//	child := node [":" percent]
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid split tree at position %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) node() (*Node, error) {
+	name := p.word()
+	if name == "" {
+		if p.pos >= len(p.input) {
+			return nil, p.errorf("unexpected end")
+		}
+		return nil, p.errorf("expected an agent or h(...)/v(...), found '%c'", p.peek())
+	}
+
+	if p.peek() != '(' {
+		return &Node{Agent: name}, nil
+	}
+
+	var direction Direction
+	switch name {
+	case "h":
+		direction = Horizontal
+	case "v":
+		direction = Vertical
+	default:
+		return nil, p.errorf("unknown split '%s' (use h or v)", name)
+	}
+	p.pos++
+
+	node := &Node{Split: direction}
+	total := 0
+	for {
+		child, err := p.node()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() == ':' {
+			p.pos++
+			percent, err := p.percent()
+			if err != nil {
+				return nil, err
+			}
+			child.Percent = percent
+			total += percent
+		}
+		node.Children = append(node.Children, child)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case ')':
+			p.pos++
+		default:
+			return nil, p.errorf("expected ',' or ')'")
+		}
+		break
+	}
+
+	if total > 100 {
+		return nil, p.errorf("shares add up to %d%%", total)
+	}
+	return node, nil
+}
+
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := rune(p.input[p.pos])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToLower(p.input[start:p.pos])
+}
+
+func (p *parser) percent() (int, error) {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	value, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil || value < 1 || value > 99 {
+		return 0, p.errorf("share must be a percentage between 1 and 99")
+	}
+	if p.peek() == '%' {
+		p.pos++
+	}
+	return value, nil
+}
@@ -0,0 +1,41 @@
+package layout
+
+// Insertion recreates the pane of an agent missing from a window by splitting the pane of
+// another agent of the plan
+type Insertion struct {
+	// Agent is the agent whose pane is recreated
+	Agent string
+	// Anchor is the agent whose pane is split ("" when no agent of the plan has a pane)
+	Anchor string
+	// Before places the new pane before the anchor's pane instead of after it
+	Before bool
+}
+
+// Insertions returns the panes to create for the agents of the plan that have none (present
+// lists those that do), in an order that keeps the panes in plan order: each agent follows
+// the agent before it, or precedes the first present agent when it leads the plan
+func (p *Plan) Insertions(present map[string]bool) []Insertion {
+	first := ""
+	for _, agent := range p.Agents {
+		if present[agent] {
+			first = agent
+			break
+		}
+	}
+
+	var insertions []Insertion
+	previous := ""
+	for _, agent := range p.Agents {
+		if present[agent] {
+			previous = agent
+			continue
+		}
+		if previous != "" {
+			insertions = append(insertions, Insertion{Agent: agent, Anchor: previous})
+		} else {
+			insertions = append(insertions, Insertion{Agent: agent, Anchor: first, Before: first != ""})
+		}
+		previous = agent
+	}
+	return insertions
+}
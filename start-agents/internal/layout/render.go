@@ -0,0 +1,110 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Render returns the tmux layout string (as accepted by select-layout) placing the panes of a
+// window of the given size according to the tree. paneIDs are the window's panes in index
+// order; tmux assigns them to the cells in the order of Leaves.
+func Render(root *Node, paneIDs []string, width, height int) (string, error) {
+	if cells := len(root.Leaves()); cells != len(paneIDs) {
+		return "", fmt.Errorf("layout has %d cells but the window has %d panes", cells, len(paneIDs))
+	}
+
+	var b strings.Builder
+	next := 0
+	if err := renderNode(&b, root, paneIDs, &next, 0, 0, width, height); err != nil {
+		return "", err
+	}
+	body := b.String()
+	return fmt.Sprintf("%04x,%s", Checksum(body), body), nil
+}
+
+func renderNode(b *strings.Builder, n *Node, paneIDs []string, next *int, x, y, width, height int) error {
+	fmt.Fprintf(b, "%dx%d,%d,%d", width, height, x, y)
+	if n.Split == 0 {
+		fmt.Fprintf(b, ",%s", strings.TrimPrefix(paneIDs[*next], "%"))
+		*next++
+		return nil
+	}
+
+	total, open, closing := width, "{", "}"
+	if n.Split == Vertical {
+		total, open, closing = height, "[", "]"
+	}
+	sizes, err := shares(n.Children, total)
+	if err != nil {
+		return fmt.Errorf("window %dx%d is too small for the layout: %w", width, height, err)
+	}
+
+	b.WriteString(open)
+	offset := 0
+	for i, child := range n.Children {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		var err error
+		if n.Split == Vertical {
+			err = renderNode(b, child, paneIDs, next, x, y+offset, width, sizes[i])
+		} else {
+			err = renderNode(b, child, paneIDs, next, x+offset, y, sizes[i], height)
+		}
+		if err != nil {
+			return err
+		}
+		offset += sizes[i] + 1 // one cell for the pane border
+	}
+	b.WriteString(closing)
+	return nil
+}
+
+// shares splits total cells (including the borders between children) among the children
+func shares(children []*Node, total int) ([]int, error) {
+	available := total - (len(children) - 1)
+	sizes := make([]int, len(children))
+
+	used, flexible := 0, 0
+	for i, child := range children {
+		if child.Percent > 0 {
+			sizes[i] = available * child.Percent / 100
+			used += sizes[i]
+		} else {
+			flexible++
+		}
+	}
+
+	rest := available - used
+	last := len(children) - 1
+	for i := range children {
+		if children[i].Percent > 0 {
+			continue
+		}
+		sizes[i] = rest / flexible
+		last = i
+	}
+	// Rounding leftovers go to the last flexible child (or the last child)
+	sum := 0
+	for _, size := range sizes {
+		sum += size
+	}
+	sizes[last] += available - sum
+
+	for _, size := range sizes {
+		if size < 1 {
+			return nil, fmt.Errorf("%d panes do not fit in %d cells", len(children), total)
+		}
+	}
+	return sizes, nil
+}
+
+// Checksum computes the checksum tmux expects in front of a layout string
+func Checksum(layout string) uint16 {
+	var csum uint16
+	for i := 0; i < len(layout); i++ {
+		csum = (csum >> 1) + ((csum & 1) << 15)
+		csum += uint16(layout[i])
+	}
+	return csum
+}
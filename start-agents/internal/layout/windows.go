@@ -0,0 +1,123 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shivase/cloud-code-agents/shared/topology"
+)
+
+// Multi-window teams
+//
+// A team whose developers do not fit on one screen is spread over several
+// windows: the first window holds the PO and the manager, the following ones
+// hold groups of developers of equal size. DEVS_PER_WINDOW sets the largest
+// group; 0 keeps teams of up to AutoDevsPerWindow developers in one window.
+// The leaders' window uses DEFAULT_LAYOUT; developer windows use it as well
+// when it is a grid or a tmux layout, and a developer grid otherwise.
+
+// AutoDevsPerWindow is the developer count up to which a team stays in one window by default
+const AutoDevsPerWindow = 10
+
+// LeadersWindow is the name of the window holding the PO and the manager
+const LeadersWindow = "leads"
+
+// Window is a tmux window of a team session with the layout of its panes
+type Window struct {
+	// Name is the window name (empty for a team in a single window)
+	Name string
+	// Plan arranges the agents of the window
+	Plan *Plan
+}
+
+// Agents returns the agents of all windows in window and pane order
+func Agents(windows []Window) []string {
+	var agents []string
+	for _, w := range windows {
+		agents = append(agents, w.Plan.Agents...)
+	}
+	return agents
+}
+
+// Windows spreads the agents (in team order) over windows and resolves the layout of each
+func Windows(spec string, custom map[string]string, agents []string, devsPerWindow int) ([]Window, error) {
+	var leaders, devs []string
+	for _, agent := range agents {
+		if role, _ := topology.RoleOf(agent); role == topology.RoleDev {
+			devs = append(devs, agent)
+		} else {
+			leaders = append(leaders, agent)
+		}
+	}
+
+	groups := GroupDevelopers(devs, devsPerWindow)
+	if len(groups) <= 1 || len(leaders) == 0 {
+		plan, err := Resolve(spec, custom, agents)
+		if err != nil {
+			return nil, err
+		}
+		return []Window{{Plan: plan}}, nil
+	}
+
+	plan, err := Resolve(spec, custom, leaders)
+	if err != nil {
+		return nil, fmt.Errorf("%s window: %w", LeadersWindow, err)
+	}
+	windows := []Window{{Name: LeadersWindow, Plan: plan}}
+
+	devSpec, err := devWindowLayout(spec, custom)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		plan, err := Resolve(devSpec, custom, group)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, Window{Name: groupName(group), Plan: plan})
+	}
+	return windows, nil
+}
+
+// GroupDevelopers splits the developers into groups of equal size with at most devsPerWindow members
+// (0 = a single group up to AutoDevsPerWindow developers)
+func GroupDevelopers(devs []string, devsPerWindow int) [][]string {
+	if len(devs) == 0 {
+		return nil
+	}
+	if devsPerWindow <= 0 {
+		devsPerWindow = AutoDevsPerWindow
+	}
+
+	count := (len(devs) + devsPerWindow - 1) / devsPerWindow
+	size := (len(devs) + count - 1) / count
+	var groups [][]string
+	for start := 0; start < len(devs); start += size {
+		end := start + size
+		if end > len(devs) {
+			end = len(devs)
+		}
+		groups = append(groups, devs[start:end])
+	}
+	return groups
+}
+
+// devWindowLayout returns the layout of developer windows: DEFAULT_LAYOUT when it fits any agents, integrated otherwise
+func devWindowLayout(spec string, custom map[string]string) (string, error) {
+	definition, err := definitionOf(spec, custom)
+	if err != nil {
+		return "", err
+	}
+	if IsBuiltin(definition) || definition == Grid || strings.HasPrefix(definition, Grid+":") {
+		return spec, nil
+	}
+	return Integrated, nil
+}
+
+// groupName names a developer window after its first and last developer, e.g. "dev1-dev6"
+func groupName(group []string) string {
+	if len(group) == 1 {
+		return group[0]
+	}
+	return group[0] + "-" + group[len(group)-1]
+}
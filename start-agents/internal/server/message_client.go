@@ -2,14 +2,17 @@ package server
 
 import (
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/shivase/claude-code-agents/internal/tmux"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
+// defaultPaneCount - Pane count of a default team (PO + Manager + 4 Dev)
+const defaultPaneCount = topology.LeaderCount + topology.DefaultDevCount
+
 // MessageClient - tmux-based message client
 type MessageClient struct {
 	sessionName string
@@ -22,7 +25,7 @@ func NewMessageClient(sessionName string) *MessageClient {
 
 	// Auto-detect if session name is empty
 	if sessionName == "" {
-		expectedPaneCount := 6 // Default value (PO + Manager + 4 Dev)
+		expectedPaneCount := defaultPaneCount
 		if detectedSession, err := tmuxManager.FindDefaultAISession(expectedPaneCount); err == nil {
 			sessionName = detectedSession
 		} else {
@@ -32,7 +35,7 @@ func NewMessageClient(sessionName string) *MessageClient {
 
 	// If specified session doesn't exist, try auto-detection
 	if !tmuxManager.SessionExists(sessionName) {
-		expectedPaneCount := 6 // Default value (PO + Manager + 4 Dev)
+		expectedPaneCount := defaultPaneCount
 		if detectedSession, err := tmuxManager.FindDefaultAISession(expectedPaneCount); err == nil {
 			sessionName = detectedSession
 		}
@@ -89,14 +92,17 @@ func (mc *MessageClient) GetStatus(agent string) (bool, error) {
 	}
 
 	// Check if tmux pane exists
-	cmd := exec.Command("tmux", "list-panes", "-t", mc.sessionName) // #nosec G204
-	output, err := cmd.Output()
+	panes, err := mc.tmuxManager.GetPaneList(mc.sessionName)
 	if err != nil {
 		return false, fmt.Errorf("failed to check pane status: %w", err)
 	}
 
-	// Check if pane exists
-	return strings.Contains(string(output), fmt.Sprintf("%d:", paneIndex)), nil
+	for _, pane := range panes {
+		if strings.HasPrefix(pane, fmt.Sprintf("%d:", paneIndex)) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // IsServerRunning - Check tmux session running state
@@ -113,7 +119,7 @@ func (mc *MessageClient) GetSessionName() string {
 func (mc *MessageClient) CheckConnection() error {
 	// First try auto-detection (if session doesn't exist or exists but not an AI session)
 	if !mc.tmuxManager.SessionExists(mc.sessionName) {
-		detectedSession, sessionType, err := mc.tmuxManager.DetectActiveAISession(6)
+		detectedSession, sessionType, err := mc.tmuxManager.DetectActiveAISession(defaultPaneCount)
 		if err != nil {
 			return fmt.Errorf("no active AI sessions found: %w", err)
 		}
@@ -127,9 +133,9 @@ func (mc *MessageClient) CheckConnection() error {
 			return fmt.Errorf("failed to get pane count for session %s: %w", mc.sessionName, err)
 		}
 
-		// If not 6 panes or 1 pane, detect other AI sessions
-		if paneCount != 6 && paneCount != 1 {
-			detectedSession, sessionType, err := mc.tmuxManager.DetectActiveAISession(6)
+		// If not an integrated team or 1 pane, detect other AI sessions
+		if paneCount <= topology.LeaderCount && paneCount != 1 {
+			detectedSession, sessionType, err := mc.tmuxManager.DetectActiveAISession(defaultPaneCount)
 			if err != nil {
 				return fmt.Errorf("session %s has %d panes (not AI session) and no other AI sessions found: %w", mc.sessionName, paneCount, err)
 			}
@@ -145,9 +151,9 @@ func (mc *MessageClient) CheckConnection() error {
 		return fmt.Errorf("failed to get pane count for session %s: %w", mc.sessionName, err)
 	}
 
-	// Allow integrated monitoring (6 panes) or individual session (1 pane)
-	if paneCount != 6 && paneCount != 1 {
-		return fmt.Errorf("expected 6 panes (integrated) or 1 pane (individual) but found %d in session %s", paneCount, mc.sessionName)
+	// Allow integrated monitoring (PO + Manager + devs) or individual session (1 pane)
+	if paneCount <= topology.LeaderCount && paneCount != 1 {
+		return fmt.Errorf("expected at least %d panes (integrated) or 1 pane (individual) but found %d in session %s", topology.LeaderCount+1, paneCount, mc.sessionName)
 	}
 
 	return nil
@@ -155,20 +161,5 @@ func (mc *MessageClient) CheckConnection() error {
 
 // getAgentPaneIndex - Get pane index from agent name (same configuration as claude.sh)
 func (mc *MessageClient) getAgentPaneIndex(agent string) (int, error) {
-	switch strings.ToLower(agent) {
-	case "po":
-		return 1, nil // Top left
-	case "manager":
-		return 2, nil // Bottom left
-	case "dev1":
-		return 3, nil // Top right
-	case "dev2":
-		return 4, nil // Top right middle
-	case "dev3":
-		return 5, nil // Bottom right middle
-	case "dev4":
-		return 6, nil // Bottom right
-	default:
-		return -1, fmt.Errorf("unknown agent: %s", agent)
-	}
+	return agentPaneNumber(agent)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/shivase/claude-code-agents/internal/tmux"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // MessageServer - tmux-based message server
@@ -58,7 +59,7 @@ func (ms *MessageServer) performHealthCheck() {
 		return
 	}
 
-	if paneCount != 6 {
+	if paneCount <= topology.LeaderCount {
 		log.Warn().Int("pane_count", paneCount).Str("session", ms.sessionName).Msg("unexpected pane count")
 	}
 
@@ -137,22 +138,16 @@ func (ms *MessageServer) GetAgentStatus(agent string) (bool, error) {
 
 // getAgentPaneIndex - Get pane index from agent name
 func (ms *MessageServer) getAgentPaneIndex(agent string) (int, error) {
-	switch strings.ToLower(agent) {
-	case "po":
-		return 0, nil
-	case "manager":
-		return 1, nil
-	case "dev1":
-		return 2, nil
-	case "dev2":
-		return 3, nil
-	case "dev3":
-		return 4, nil
-	case "dev4":
-		return 5, nil
-	default:
+	return agentPaneNumber(agent)
+}
+
+// agentPaneNumber - Get the 1-based pane number of an agent in the integrated layout (shared by server and client)
+func agentPaneNumber(agent string) (int, error) {
+	teamAgent, ok := topology.Lookup(strings.ToLower(agent))
+	if !ok {
 		return -1, fmt.Errorf("unknown agent: %s", agent)
 	}
+	return teamAgent.Index + 1, nil
 }
 
 // Stop - Stop server
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
 // SendInstructionToPaneWithConfig sends instruction file using configuration
@@ -23,21 +24,22 @@ func (tm *TmuxManagerImpl) SendInstructionToPaneWithConfig(sessionName, pane, ag
 		GetDevInstructionFile() string
 	}
 
+	role, _ := topology.RoleOf(agent)
 	if ic, ok := config.(InstructionConfig); ok {
-		switch agent {
-		case "po":
+		switch role {
+		case topology.RolePO:
 			if ic.GetPOInstructionFile() != "" {
 				instructionFile = filepath.Join(instructionsDir, ic.GetPOInstructionFile())
 			} else {
 				instructionFile = filepath.Join(instructionsDir, "po.md")
 			}
-		case "manager":
+		case topology.RoleManager:
 			if ic.GetManagerInstructionFile() != "" {
 				instructionFile = filepath.Join(instructionsDir, ic.GetManagerInstructionFile())
 			} else {
 				instructionFile = filepath.Join(instructionsDir, "manager.md")
 			}
-		case "dev1", "dev2", "dev3", "dev4":
+		case topology.RoleDev:
 			if ic.GetDevInstructionFile() != "" {
 				instructionFile = filepath.Join(instructionsDir, ic.GetDevInstructionFile())
 			} else {
@@ -49,12 +51,12 @@ func (tm *TmuxManagerImpl) SendInstructionToPaneWithConfig(sessionName, pane, ag
 		}
 	} else {
 		// Use default file names if configuration is not provided
-		switch agent {
-		case "po":
+		switch role {
+		case topology.RolePO:
 			instructionFile = filepath.Join(instructionsDir, "po.md")
-		case "manager":
+		case topology.RoleManager:
 			instructionFile = filepath.Join(instructionsDir, "manager.md")
-		case "dev1", "dev2", "dev3", "dev4":
+		case topology.RoleDev:
 			instructionFile = filepath.Join(instructionsDir, "developer.md")
 		default:
 			log.Error().Str("agent", agent).Msg("❌ Unknown agent type")
@@ -1,6 +1,10 @@
 package tmux
 
-import "time"
+import (
+	"time"
+
+	"github.com/shivase/claude-code-agents/internal/layout"
+)
 
 // TmuxManagerInterface interface for tmux operation management
 type TmuxManagerInterface interface {
@@ -16,6 +20,8 @@ type TmuxManagerInterface interface {
 	AttachSession(sessionName string) error
 	// CreateIntegratedLayout creates integrated monitoring screen layout
 	CreateIntegratedLayout(sessionName string, devCount int) error
+	// CreateLayout creates one pane per agent arranged according to the layout plan
+	CreateLayout(sessionName string, plan *layout.Plan) error
 	// CreateIndividualLayout creates individual session layout
 	CreateIndividualLayout(sessionName string) error
 	// SplitWindow splits a window
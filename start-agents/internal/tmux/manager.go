@@ -1,27 +1,63 @@
 package tmux
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/cloud-code-agents/shared/agentstate"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
 )
 
-// Pre-compiled regular expressions (performance optimization)
-var (
-	agentSessionRegex = regexp.MustCompile(`-(po|manager|dev\d+)$`)
-)
+// agentPane returns the pane ID of an agent recorded by CreateWindows, or its 1-based pane
+// number in the integrated layout when no windows have been created
+func (tm *TmuxManagerImpl) agentPane(agent topology.Agent) string {
+	if paneID, ok := tm.agentPanes[agent.Name]; ok {
+		return paneID
+	}
+	return strconv.Itoa(agent.Index + 1)
+}
+
+// paneTarget returns the tmux target of a pane given by ID (%N) or by its 1-based number in
+// the session's pane order. Numbers are resolved to pane IDs so that targets do not depend on
+// the base-index and pane-base-index options.
+func (tm *TmuxManagerImpl) paneTarget(sessionName, pane string) (string, error) {
+	if strings.HasPrefix(pane, "%") {
+		return pane, nil
+	}
+	number, err := strconv.Atoi(pane)
+	if err != nil {
+		return "", fmt.Errorf("invalid pane '%s' (a pane ID or number)", pane)
+	}
+	paneIDs, err := tm.GetPaneIDs(sessionName)
+	if err != nil {
+		return "", err
+	}
+	if number < 1 || number > len(paneIDs) {
+		return "", fmt.Errorf("session %s has no pane %d (%d panes)", sessionName, number, len(paneIDs))
+	}
+	return paneIDs[number-1], nil
+}
+
+// isAgentSession reports whether the session is an individual agent session (e.g. project-dev1)
+func isAgentSession(sessionName string) bool {
+	_, _, ok := topology.SplitAgentSession(sessionName)
+	return ok
+}
 
 // TmuxManagerImpl manages tmux operations
 type TmuxManagerImpl struct {
 	sessionName string
 	layout      string
+	agentPanes  map[string]string // agent name → pane ID of the windows created by CreateWindows
 }
 
 // NewTmuxManager creates a new tmux manager
@@ -34,15 +70,20 @@ func NewTmuxManager(sessionName string) *TmuxManagerImpl {
 
 // SessionExists checks if a session exists
 func (tm *TmuxManagerImpl) SessionExists(sessionName string) bool {
-	cmd := exec.Command("tmux", "has-session", "-t", sessionName)
+	cmd := tmuxsocket.Command("has-session", "-t", sessionName)
 	return cmd.Run() == nil
 }
 
 // ListSessions retrieves session list
 func (tm *TmuxManagerImpl) ListSessions() ([]string, error) {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
+	cmd := tmuxsocket.Command("list-sessions", "-F", "#{session_name}")
 	output, err := cmd.Output()
 	if err != nil {
+		// Keep tmux's message (e.g. "no server running") for the caller
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to list sessions: %w (%s)", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
 		return nil, fmt.Errorf("failed to list sessions: %w", err)
 	}
 
@@ -63,7 +104,7 @@ func (tm *TmuxManagerImpl) CreateSession(sessionName string) error {
 		return fmt.Errorf("session %s already exists", sessionName)
 	}
 
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName)
+	cmd := tmuxsocket.Command("new-session", "-d", "-s", sessionName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create session %s: %w", sessionName, err)
 	}
@@ -77,7 +118,7 @@ func (tm *TmuxManagerImpl) KillSession(sessionName string) error {
 		return nil // No error if session doesn't exist
 	}
 
-	cmd := exec.Command("tmux", "kill-session", "-t", sessionName)
+	cmd := tmuxsocket.Command("kill-session", "-t", sessionName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to kill session %s: %w", sessionName, err)
 	}
@@ -85,14 +126,36 @@ func (tm *TmuxManagerImpl) KillSession(sessionName string) error {
 	return nil
 }
 
+// KillServer stops the tmux server of a dedicated socket (tmux -L) with all its sessions
+func (tm *TmuxManagerImpl) KillServer(socket string) error {
+	cmd := exec.Command("tmux", "-L", socket, "kill-server") // #nosec G204
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stop tmux server %s: %w (output: %s)", socket, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 // AttachSession attaches to a session
 func (tm *TmuxManagerImpl) AttachSession(sessionName string) error {
 	if !tm.SessionExists(sessionName) {
 		return fmt.Errorf("session %s does not exist", sessionName)
 	}
 
+	// A client inside tmux cannot attach without nesting: switch it to the session when it
+	// runs on the same server, otherwise show how to attach from another terminal
+	if inside, _ := IsInsideTmux(); inside {
+		if tmuxsocket.OnCallerServer() {
+			if output, err := tmuxsocket.Command("switch-client", "-t", sessionName).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to switch to session %s: %w (output: %s)", sessionName, err, strings.TrimSpace(string(output)))
+			}
+			return nil
+		}
+		fmt.Printf("💡 Session '%s' runs on tmux server %s; attach with:\n   %s\n", sessionName, tmuxsocket.Selected(), tmuxsocket.AttachCommand(sessionName))
+		return nil
+	}
+
 	// Execute tmux attach-session (non-interactively)
-	cmd := exec.Command("tmux", "attach-session", "-t", sessionName)
+	cmd := tmuxsocket.Command("attach-session", "-t", sessionName)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -111,6 +174,21 @@ func (tm *TmuxManagerImpl) AttachSession(sessionName string) error {
 
 // CreateIntegratedLayout creates integrated monitoring screen layout (supports dynamic dev count)
 func (tm *TmuxManagerImpl) CreateIntegratedLayout(sessionName string, devCount int) error {
+	plan, err := layout.Resolve(layout.Integrated, nil, topology.New(devCount).Names())
+	if err != nil {
+		return err
+	}
+	return tm.CreateLayout(sessionName, plan)
+}
+
+// CreateLayout creates one pane per agent of the plan in the session's window and arranges them accordingly
+func (tm *TmuxManagerImpl) CreateLayout(sessionName string, plan *layout.Plan) error {
+	return tm.CreateWindows(sessionName, []layout.Window{{Plan: plan}})
+}
+
+// CreateWindows creates the team's windows, the first one being the session's window, with one pane
+// per agent arranged by each window's plan. Agents are addressed through their pane IDs afterwards.
+func (tm *TmuxManagerImpl) CreateWindows(sessionName string, windows []layout.Window) error {
 	// Create session if it doesn't exist
 	if !tm.SessionExists(sessionName) {
 		if err := tm.CreateSession(sessionName); err != nil {
@@ -118,70 +196,174 @@ func (tm *TmuxManagerImpl) CreateIntegratedLayout(sessionName string, devCount i
 		}
 	}
 
-	// Set window name
-	if err := tm.RenameWindow(sessionName, sessionName); err != nil {
-		return fmt.Errorf("failed to rename window: %w", err)
+	tm.agentPanes = make(map[string]string)
+	var firstWindow string
+	for i, w := range windows {
+		name := w.Name
+		if name == "" {
+			name = sessionName
+		}
+		windowID, err := tm.createWindow(sessionName, name, i == 0)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			firstWindow = windowID
+		}
+
+		paneIDs, err := tm.createPanes(windowID, w.Plan)
+		if err != nil {
+			return fmt.Errorf("window %s: %w", name, err)
+		}
+		for j, agent := range w.Plan.Agents {
+			tm.agentPanes[agent] = paneIDs[j]
+		}
+		log.Info().Str("session", sessionName).Str("window", name).Str("layout", w.Plan.Name).Int("panes", len(paneIDs)).Msg("Window layout created")
 	}
 
-	// Create dynamic pane configuration (PO + Manager + Dev count)
-	totalPanes := 2 + devCount
+	if len(windows) > 1 {
+		if err := tmuxsocket.Command("select-window", "-t", firstWindow).Run(); err != nil {
+			log.Warn().Err(err).Str("window", firstWindow).Msg("Failed to select the first window")
+		}
+	}
 
-	// Add a small delay for split timing
-	sleep := func() {
-		time.Sleep(50 * time.Millisecond)
+	// Set pane titles
+	agents := layout.Agents(windows)
+	if err := tm.SetPaneTitles(sessionName, countDevelopers(agents)); err != nil {
+		return fmt.Errorf("failed to set pane titles: %w", err)
+	}
+
+	log.Info().Str("session", sessionName).Int("windows", len(windows)).Int("total_panes", len(agents)).Msg("Layout created successfully")
+	return nil
+}
+
+// createWindow returns the ID of the session's window (first) or of a new window, named name
+func (tm *TmuxManagerImpl) createWindow(sessionName, name string, first bool) (string, error) {
+	var cmd *exec.Cmd
+	if first {
+		cmd = tmuxsocket.Command("display-message", "-p", "-t", sessionName, "#{window_id}")
+	} else {
+		cmd = tmuxsocket.Command("new-window", "-d", "-P", "-F", "#{window_id}", "-t", sessionName+":", "-n", name)
 	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to create window %s: %w (output: %s)", name, err, strings.TrimSpace(string(output)))
+	}
+	windowID := strings.TrimSpace(string(output))
 
-	// Create layout with PO/Manager on left, Dev on right
-	// 1. Split first pane horizontally (left | right)
-	if err := tm.SplitWindow(sessionName, "-h"); err != nil {
-		return fmt.Errorf("failed to split window horizontally: %w", err)
+	if first {
+		if err := tm.RenameWindow(windowID, name); err != nil {
+			return "", fmt.Errorf("failed to rename window: %w", err)
+		}
 	}
-	sleep()
+	return windowID, nil
+}
 
-	// 2. Split left side (pane 1) vertically (PO | Manager)
-	if err := tm.SplitWindow(sessionName+":1.1", "-v"); err != nil {
-		return fmt.Errorf("failed to split left pane vertically: %w", err)
+// createPanes splits the window into one pane per agent of the plan, arranges them and returns their IDs in pane order
+func (tm *TmuxManagerImpl) createPanes(windowID string, plan *layout.Plan) ([]string, error) {
+	// Retile after each split so the window never runs out of room
+	for i := 1; i < len(plan.Agents); i++ {
+		if err := tm.SplitWindow(windowID, "-v"); err != nil {
+			return nil, fmt.Errorf("failed to create pane %d: %w", i+1, err)
+		}
+		if err := tm.SelectLayout(windowID, "tiled"); err != nil {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
 	}
-	sleep()
 
-	// 3. Split right side (pane 3) for developers
-	// First developer uses pane 3
-	// For subsequent developers, always split the first right pane (pane 3)
-	for i := 2; i <= devCount; i++ {
-		// Always split pane 3 to maintain equal spacing
-		target := fmt.Sprintf("%s:1.3", sessionName)
-		if err := tm.SplitWindow(target, "-v"); err != nil {
-			return fmt.Errorf("failed to split dev pane %d: %w", i, err)
+	// Arrange the panes (panes are assigned to the layout cells in pane order)
+	if err := tm.applyLayout(windowID, plan); err != nil {
+		log.Warn().Err(err).Str("window", windowID).Str("layout", plan.Name).Msg("Failed to apply layout, using tiled")
+		fmt.Printf("⚠️ Layout '%s' could not be applied (%v); using tiled\n", plan.Name, err)
+		if err := tm.SelectLayout(windowID, "tiled"); err != nil {
+			return nil, err
 		}
-		sleep()
 	}
 
-	// Adjust pane sizes
-	if err := tm.AdjustPaneSizes(sessionName, devCount); err != nil {
-		return fmt.Errorf("failed to adjust pane sizes: %w", err)
+	paneIDs, err := tm.windowPaneIDs(windowID)
+	if err != nil {
+		return nil, err
+	}
+	if len(paneIDs) != len(plan.Agents) {
+		return nil, fmt.Errorf("expected %d panes, found %d", len(plan.Agents), len(paneIDs))
 	}
+	return paneIDs, nil
+}
 
-	// Set pane titles
-	if err := tm.SetPaneTitles(sessionName, devCount); err != nil {
-		return fmt.Errorf("failed to set pane titles: %w", err)
+// applyLayout selects the plan's built-in layout or renders its split tree for the current window size
+func (tm *TmuxManagerImpl) applyLayout(windowID string, plan *layout.Plan) error {
+	if plan.Builtin != "" {
+		return tm.SelectLayout(windowID, plan.Builtin)
+	}
+
+	paneIDs, err := tm.windowPaneIDs(windowID)
+	if err != nil {
+		return err
+	}
+	width, height, err := tm.getWindowSize(windowID)
+	if err != nil {
+		return err
+	}
+	custom, err := layout.Render(plan.Root, paneIDs, width, height)
+	if err != nil {
+		return err
+	}
+	return tm.SelectLayout(windowID, custom)
+}
+
+// windowPaneIDs retrieves the pane IDs of a window in pane order
+func (tm *TmuxManagerImpl) windowPaneIDs(windowID string) ([]string, error) {
+	output, err := tmuxsocket.Command("list-panes", "-t", windowID, "-F", "#{pane_id}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes of window %s: %w", windowID, err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// windowIDs retrieves the window IDs of the session
+func (tm *TmuxManagerImpl) windowIDs(sessionName string) ([]string, error) {
+	output, err := tmuxsocket.Command("list-windows", "-t", sessionName, "-F", "#{window_id}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows: %w", err)
 	}
+	return strings.Fields(string(output)), nil
+}
+
+// AgentPane returns the pane ID of an agent in the windows created by CreateWindows
+func (tm *TmuxManagerImpl) AgentPane(agent string) (string, bool) {
+	paneID, ok := tm.agentPanes[agent]
+	return paneID, ok
+}
 
-	log.Info().Str("session", sessionName).Int("dev_count", devCount).Int("total_panes", totalPanes).Msg("Dynamic integrated layout created successfully")
+// SelectLayout applies a tmux layout (a built-in name or a layout string) to the target window
+func (tm *TmuxManagerImpl) SelectLayout(target, tmuxLayout string) error {
+	cmd := tmuxsocket.Command("select-layout", "-t", target, tmuxLayout)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("tmux command failed: select-layout %s (output: %s)", tmuxLayout, strings.TrimSpace(string(output)))
+	}
 	return nil
 }
 
+// countDevelopers returns the number of developers among the agents
+func countDevelopers(agents []string) int {
+	count := 0
+	for _, agent := range agents {
+		if role, _ := topology.RoleOf(agent); role == topology.RoleDev {
+			count++
+		}
+	}
+	return count
+}
+
 // SetupClaudeInPanes starts Claude CLI automatically and sends instructions in each pane
 func (tm *TmuxManagerImpl) SetupClaudeInPanes(sessionName string, claudeCLIPath string, instructionsDir string, devCount int) error {
 
-	// Dynamic pane configuration map (pane number → agent name)
-	paneAgentMap := make(map[string]string)
-	paneAgentMap["1"] = "po"
-	paneAgentMap["2"] = "manager"
-	for i := 1; i <= devCount; i++ {
-		paneAgentMap[fmt.Sprintf("%d", i+2)] = fmt.Sprintf("dev%d", i)
-	}
+	// Team agents in pane order (pane number → agent name)
+	agents := topology.New(devCount).Agents()
 
-	for pane, agent := range paneAgentMap {
+	for _, teamAgent := range agents {
+		pane, agent := tm.agentPane(teamAgent), teamAgent.Name
 
 		// Start Claude CLI in each pane
 		if err := tm.startClaudeInPane(sessionName, pane, agent, claudeCLIPath); err != nil {
@@ -197,7 +379,8 @@ func (tm *TmuxManagerImpl) SetupClaudeInPanes(sessionName string, claudeCLIPath
 	time.Sleep(2 * time.Second)
 
 	// Send instruction files
-	for pane, agent := range paneAgentMap {
+	for _, teamAgent := range agents {
+		pane, agent := tm.agentPane(teamAgent), teamAgent.Name
 		if err := tm.sendInstructionToPane(sessionName, pane, agent, instructionsDir); err != nil {
 			log.Warn().Str("session", sessionName).Str("pane", pane).Str("agent", agent).Err(err).Msg("Failed to send instruction to pane (non-critical)")
 			// Instruction send failure is warning level (can continue)
@@ -225,15 +408,14 @@ func (tm *TmuxManagerImpl) SetupClaudeInPanesWithConfig(sessionName string, clau
 		instructionConfig = ic
 	}
 
-	// Dynamic pane configuration map (pane number → agent name)
-	paneAgentMap := make(map[string]string)
-	paneAgentMap["1"] = "po"
-	paneAgentMap["2"] = "manager"
-	for i := 1; i <= devCount; i++ {
-		paneAgentMap[fmt.Sprintf("%d", i+2)] = fmt.Sprintf("dev%d", i)
-	}
+	// Team agents in pane order (pane number → agent name)
+	return tm.SetupClaudeForAgents(sessionName, claudeCLIPath, instructionsDir, instructionConfig, topology.New(devCount).Agents())
+}
 
-	for pane, agent := range paneAgentMap {
+// SetupClaudeForAgents starts Claude CLI and sends the instructions in the panes of the given agents
+func (tm *TmuxManagerImpl) SetupClaudeForAgents(sessionName string, claudeCLIPath string, instructionsDir string, config interface{}, agents []topology.Agent) error {
+	for _, teamAgent := range agents {
+		pane, agent := tm.agentPane(teamAgent), teamAgent.Name
 		// Start Claude CLI in each pane
 		if err := tm.startClaudeInPane(sessionName, pane, agent, claudeCLIPath); err != nil {
 			log.Error().Str("session", sessionName).Str("pane", pane).Str("agent", agent).Err(err).Msg("Failed to start Claude CLI in pane")
@@ -248,8 +430,9 @@ func (tm *TmuxManagerImpl) SetupClaudeInPanesWithConfig(sessionName string, clau
 	time.Sleep(2 * time.Second)
 
 	// Send instruction files
-	for pane, agent := range paneAgentMap {
-		if err := tm.SendInstructionToPaneWithConfig(sessionName, pane, agent, instructionsDir, instructionConfig); err != nil {
+	for _, teamAgent := range agents {
+		pane, agent := tm.agentPane(teamAgent), teamAgent.Name
+		if err := tm.SendInstructionToPaneWithConfig(sessionName, pane, agent, instructionsDir, config); err != nil {
 			log.Warn().Str("session", sessionName).Str("pane", pane).Str("agent", agent).Err(err).Msg("Failed to send instruction to pane (non-critical)")
 			// Instruction send failure is warning level (can continue)
 		}
@@ -285,12 +468,13 @@ func (tm *TmuxManagerImpl) sendInstructionToPane(sessionName, pane, agent, instr
 
 	// Determine instruction file path
 	var instructionFile string
-	switch agent {
-	case "po":
+	role, _ := topology.RoleOf(agent)
+	switch role {
+	case topology.RolePO:
 		instructionFile = filepath.Join(instructionsDir, "po.md")
-	case "manager":
+	case topology.RoleManager:
 		instructionFile = filepath.Join(instructionsDir, "manager.md")
-	case "dev1", "dev2", "dev3", "dev4":
+	case topology.RoleDev:
 		instructionFile = filepath.Join(instructionsDir, "developer.md")
 	default:
 		log.Error().Str("agent", agent).Msg("❌ Unknown agent type")
@@ -359,13 +543,8 @@ func (tm *TmuxManagerImpl) sendInstructionToPane(sessionName, pane, agent, instr
 
 // CreateIndividualLayout creates individual session layout
 func (tm *TmuxManagerImpl) CreateIndividualLayout(sessionName string, devCount int) error {
-	agents := []string{"po", "manager"}
-	for i := 1; i <= devCount; i++ {
-		agents = append(agents, fmt.Sprintf("dev%d", i))
-	}
-
-	for _, agent := range agents {
-		agentSession := fmt.Sprintf("%s-%s", sessionName, agent)
+	for _, agent := range topology.New(devCount).Names() {
+		agentSession := topology.AgentSessionName(sessionName, agent)
 
 		if err := tm.CreateSession(agentSession); err != nil {
 			return fmt.Errorf("failed to create session for %s: %w", agent, err)
@@ -383,7 +562,7 @@ func (tm *TmuxManagerImpl) CreateIndividualLayout(sessionName string, devCount i
 
 // SplitWindow splits a window
 func (tm *TmuxManagerImpl) SplitWindow(target, direction string) error {
-	cmd := exec.Command("tmux", "split-window", direction, "-t", target)
+	cmd := tmuxsocket.Command("split-window", direction, "-t", target)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("tmux command failed: split-window %s -t %s (output: %s)", direction, target, string(output))
@@ -393,7 +572,7 @@ func (tm *TmuxManagerImpl) SplitWindow(target, direction string) error {
 
 // RenameWindow renames a window
 func (tm *TmuxManagerImpl) RenameWindow(sessionName, windowName string) error {
-	cmd := exec.Command("tmux", "rename-window", "-t", sessionName, windowName)
+	cmd := tmuxsocket.Command("rename-window", "-t", sessionName, windowName)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to rename window: %w", err)
 	}
@@ -432,9 +611,18 @@ func (tm *TmuxManagerImpl) AdjustPaneSizes(sessionName string, devCount int) err
 	// Calculate left side width (50% of total)
 	leftWidth := (windowWidth * leftSidePercentage) / 100
 
+	// Resize panes by ID so that the sizes do not depend on the base-index options
+	target := func(paneNumber int) string {
+		paneID, err := tm.paneTarget(sessionName, strconv.Itoa(paneNumber))
+		if err != nil {
+			log.Warn().Int("pane", paneNumber).Err(err).Msg("Failed to resolve pane")
+		}
+		return paneID
+	}
+
 	// 1. Adjust left-right split (left 50%, right 50%)
 	time.Sleep(100 * time.Millisecond)
-	leftCmd := exec.Command("tmux", "resize-pane", "-t", fmt.Sprintf("%s:1.1", sessionName), "-x", fmt.Sprintf("%d", leftWidth)) // #nosec G204
+	leftCmd := tmuxsocket.Command("resize-pane", "-t", target(1), "-x", fmt.Sprintf("%d", leftWidth))
 	if err := leftCmd.Run(); err != nil {
 		log.Warn().Str("pane", "1").Int("width", leftWidth).Err(err).Msg("Failed to adjust left pane")
 	}
@@ -442,7 +630,7 @@ func (tm *TmuxManagerImpl) AdjustPaneSizes(sessionName string, devCount int) err
 	// 2. Adjust left side vertical split (PO/Manager 50% each)
 	time.Sleep(100 * time.Millisecond)
 	poHeight := windowHeight / 2
-	poCmd := exec.Command("tmux", "resize-pane", "-t", fmt.Sprintf("%s:1.1", sessionName), "-y", fmt.Sprintf("%d", poHeight)) // #nosec G204
+	poCmd := tmuxsocket.Command("resize-pane", "-t", target(1), "-y", fmt.Sprintf("%d", poHeight))
 	if err := poCmd.Run(); err != nil {
 		log.Warn().Str("pane", "PO").Int("height", poHeight).Err(err).Msg("Failed to adjust PO/Manager split")
 	}
@@ -457,7 +645,7 @@ func (tm *TmuxManagerImpl) AdjustPaneSizes(sessionName string, devCount int) err
 
 		// Set each pane height equally
 		time.Sleep(100 * time.Millisecond)
-		cmd := exec.Command("tmux", "resize-pane", "-t", fmt.Sprintf("%s:1.%d", sessionName, paneNumber), "-y", fmt.Sprintf("%d", devPaneHeight)) // #nosec G204
+		cmd := tmuxsocket.Command("resize-pane", "-t", target(paneNumber), "-y", fmt.Sprintf("%d", devPaneHeight))
 		if err := cmd.Run(); err != nil {
 			log.Warn().Str("pane", fmt.Sprintf("%d", paneNumber)).Int("height", devPaneHeight).Err(err).Msg("Failed to resize pane with equal spacing")
 		} else {
@@ -467,7 +655,7 @@ func (tm *TmuxManagerImpl) AdjustPaneSizes(sessionName string, devCount int) err
 
 	// 4. Finally readjust left-right width (maintain 50% each)
 	time.Sleep(100 * time.Millisecond)
-	finalLeftCmd := exec.Command("tmux", "resize-pane", "-t", fmt.Sprintf("%s:1.1", sessionName), "-x", fmt.Sprintf("%d", leftWidth)) // #nosec G204
+	finalLeftCmd := tmuxsocket.Command("resize-pane", "-t", target(1), "-x", fmt.Sprintf("%d", leftWidth))
 	if err := finalLeftCmd.Run(); err != nil {
 		log.Warn().Err(err).Msg("Failed to perform final left-right width adjustment")
 	}
@@ -478,42 +666,38 @@ func (tm *TmuxManagerImpl) AdjustPaneSizes(sessionName string, devCount int) err
 
 // SetPaneTitles sets pane titles (supports dynamic dev count)
 func (tm *TmuxManagerImpl) SetPaneTitles(sessionName string, devCount int) error {
-	// Configure to display pane titles
-	cmd := exec.Command("tmux", "set-option", "-t", sessionName, "pane-border-status", "top")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set pane border status: %w", err)
-	}
-
-	cmd = exec.Command("tmux", "set-option", "-t", sessionName, "pane-border-format", "#T")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to set pane border format: %w", err)
-	}
-
-	// Disable automatic rename
-	cmd = exec.Command("tmux", "set-window-option", "-t", sessionName, "automatic-rename", "off")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to disable automatic rename: %w", err)
-	}
-
-	cmd = exec.Command("tmux", "set-window-option", "-t", sessionName, "allow-rename", "off")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to disable allow rename: %w", err)
+	// Configure every window of the team to display pane titles
+	windows, err := tm.windowIDs(sessionName)
+	if err != nil {
+		return err
+	}
+	windowOptions := []struct {
+		option string
+		value  string
+	}{
+		{"pane-border-status", "top"},
+		{"pane-border-format", "#T"},
+		{"automatic-rename", "off"},
+		{"allow-rename", "off"},
+	}
+	for _, windowID := range windows {
+		for _, o := range windowOptions {
+			cmd := tmuxsocket.Command("set-window-option", "-t", windowID, o.option, o.value)
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("failed to set %s: %w", o.option, err)
+			}
+		}
 	}
 
 	// Set title for each pane (supports dynamic dev count)
-	titles := make(map[string]string)
-	titles["1"] = "PO"      // Top left
-	titles["2"] = "Manager" // Bottom left
-
-	// Dynamically set developer titles
-	for i := 1; i <= devCount; i++ {
-		paneNumber := fmt.Sprintf("%d", i+2)
-		titles[paneNumber] = fmt.Sprintf("Dev%d", i)
-	}
-
-	for pane, title := range titles {
-		target := fmt.Sprintf("%s:1.%s", sessionName, pane)
-		cmd = exec.Command("tmux", "select-pane", "-t", target, "-T", title) // #nosec G204
+	for _, agent := range topology.New(devCount).Agents() {
+		target, err := tm.paneTarget(sessionName, tm.agentPane(agent))
+		if err != nil {
+			log.Warn().Str("agent", agent.Name).Err(err).Msg("Failed to set pane title")
+			continue
+		}
+		title := agent.Title
+		cmd := tmuxsocket.Command("select-pane", "-t", target, "-T", title)
 		if err := cmd.Run(); err != nil {
 			log.Warn().Str("pane", target).Str("title", title).Err(err).Msg("Failed to set pane title")
 		}
@@ -522,9 +706,9 @@ func (tm *TmuxManagerImpl) SetPaneTitles(sessionName string, devCount int) error
 	return nil
 }
 
-// GetPaneCount retrieves pane count
+// GetPaneCount retrieves pane count (of all windows of the session)
 func (tm *TmuxManagerImpl) GetPaneCount(sessionName string) (int, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName)
+	cmd := tmuxsocket.Command("list-panes", "-s", "-t", sessionName)
 	output, err := cmd.Output()
 	if err != nil {
 		log.Debug().Str("session", sessionName).Err(err).Msg("Failed to get pane count")
@@ -538,9 +722,10 @@ func (tm *TmuxManagerImpl) GetPaneCount(sessionName string) (int, error) {
 	return paneCount, nil
 }
 
-// GetPaneList retrieves pane list
+// GetPaneList retrieves pane list as "<number>:<title>", numbering the panes of all windows
+// from 1 in pane order (independent of the pane-base-index option)
 func (tm *TmuxManagerImpl) GetPaneList(sessionName string) ([]string, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", sessionName, "-F", "#{pane_index}:#{pane_title}")
+	cmd := tmuxsocket.Command("list-panes", "-s", "-t", sessionName, "-F", "#{pane_title}")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list panes: %w", err)
@@ -548,10 +733,8 @@ func (tm *TmuxManagerImpl) GetPaneList(sessionName string) ([]string, error) {
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	var panes []string
-	for _, line := range lines {
-		if strings.TrimSpace(line) != "" {
-			panes = append(panes, strings.TrimSpace(line))
-		}
+	for i, line := range lines {
+		panes = append(panes, fmt.Sprintf("%d:%s", i+1, strings.TrimSpace(line)))
 	}
 
 	return panes, nil
@@ -559,8 +742,11 @@ func (tm *TmuxManagerImpl) GetPaneList(sessionName string) ([]string, error) {
 
 // SendKeysToPane sends keys to a pane
 func (tm *TmuxManagerImpl) SendKeysToPane(sessionName, pane, keys string) error {
-	target := fmt.Sprintf("%s:1.%s", sessionName, pane)
-	cmd := exec.Command("tmux", "send-keys", "-t", target, keys) // #nosec G204
+	target, err := tm.paneTarget(sessionName, pane)
+	if err != nil {
+		return err
+	}
+	cmd := tmuxsocket.Command("send-keys", "-t", target, keys)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to send keys to pane %s: %w", target, err)
 	}
@@ -569,8 +755,11 @@ func (tm *TmuxManagerImpl) SendKeysToPane(sessionName, pane, keys string) error
 
 // SendKeysWithEnter sends keys to a pane with Enter
 func (tm *TmuxManagerImpl) SendKeysWithEnter(sessionName, pane, keys string) error {
-	target := fmt.Sprintf("%s:1.%s", sessionName, pane)
-	cmd := exec.Command("tmux", "send-keys", "-t", target, keys, "C-m") // #nosec G204
+	target, err := tm.paneTarget(sessionName, pane)
+	if err != nil {
+		return err
+	}
+	cmd := tmuxsocket.Command("send-keys", "-t", target, keys, "C-m")
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to send keys with enter to pane %s: %w", target, err)
 	}
@@ -599,9 +788,9 @@ func (tm *TmuxManagerImpl) GetAITeamSessions(expectedPaneCount int) (map[string]
 		case err == nil && paneCount == expectedPaneCount:
 			result["integrated"] = append(result["integrated"], session)
 			log.Debug().Str("session", session).Msg("Added as integrated session")
-		case agentSessionRegex.MatchString(session):
+		case isAgentSession(session):
 			// Determine individual session layout
-			baseName := agentSessionRegex.ReplaceAllString(session, "")
+			baseName, _, _ := topology.SplitAgentSession(session)
 			if !containsString(result["individual"], baseName) {
 				result["individual"] = append(result["individual"], baseName)
 				log.Debug().Str("session", session).Str("base_name", baseName).Msg("Added as individual session")
@@ -717,12 +906,8 @@ func (tm *TmuxManagerImpl) DeleteAITeamSessions(sessionName string, devCount int
 	}
 
 	// For individual session layout
-	agents := []string{"po", "manager"}
-	for i := 1; i <= devCount; i++ {
-		agents = append(agents, fmt.Sprintf("dev%d", i))
-	}
-	for _, agent := range agents {
-		agentSession := fmt.Sprintf("%s-%s", sessionName, agent)
+	for _, agent := range topology.New(devCount).Names() {
+		agentSession := topology.AgentSessionName(sessionName, agent)
 		if tm.SessionExists(agentSession) {
 			log.Info().Str("session", agentSession).Msg("Deleting individual session")
 			if err := tm.KillSession(agentSession); err != nil {
@@ -752,61 +937,76 @@ func containsString(slice []string, item string) bool {
 
 // WaitForPaneReady waits for pane to be ready
 func (tm *TmuxManagerImpl) WaitForPaneReady(sessionName, pane string, timeout time.Duration) error {
-	target := fmt.Sprintf("%s:1.%s", sessionName, pane)
 	start := time.Now()
 
 	for time.Since(start) < timeout {
-		// Check if pane exists
-		cmd := exec.Command("tmux", "list-panes", "-t", sessionName)
-		output, err := cmd.Output()
-		if err != nil {
-			time.Sleep(100 * time.Millisecond)
-			continue
-		}
-
-		// Check if pane exists
-		if strings.Contains(string(output), pane) {
-			return nil
+		// Check if pane exists (a pane number resolves only once the pane has been created)
+		if target, err := tm.paneTarget(sessionName, pane); err == nil {
+			cmd := tmuxsocket.Command("display-message", "-p", "-t", target, "#{pane_id}")
+			if cmd.Run() == nil {
+				return nil
+			}
 		}
 
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	return fmt.Errorf("timeout waiting for pane %s to be ready", target)
+	return fmt.Errorf("timeout waiting for pane %s of session %s to be ready", pane, sessionName)
+}
+
+// GetAgentState classifies the Claude CLI agent running in the specified pane
+func (tm *TmuxManagerImpl) GetAgentState(sessionName, pane string) (agentstate.State, error) {
+	target, err := tm.paneTarget(sessionName, pane)
+	if err != nil {
+		return agentstate.StateUnknown, err
+	}
+	snapshot, err := agentstate.Inspect(target)
+	if err != nil {
+		return agentstate.StateUnknown, err
+	}
+	return snapshot.State, nil
 }
 
-// waitForClaudeReady waits for Claude CLI to be ready (new implementation)
+// waitForClaudeReady waits until Claude CLI is idle at its prompt
 func (tm *TmuxManagerImpl) waitForClaudeReady(sessionName, pane string, timeout time.Duration) error {
-	target := fmt.Sprintf("%s:1.%s", sessionName, pane)
+	target, err := tm.paneTarget(sessionName, pane)
+	if err != nil {
+		return err
+	}
 	start := time.Now()
 
 	log.Info().Str("target", target).Dur("timeout", timeout).Msg("🔄 Starting Claude CLI readiness wait")
 
+	lastState := agentstate.StateUnknown
 	for time.Since(start) < timeout {
-		// Get pane content and check if Claude CLI is ready
-		cmd := exec.Command("tmux", "capture-pane", "-t", target, "-p") // #nosec G204
-		output, err := cmd.Output()
+		state, err := tm.GetAgentState(sessionName, pane)
 		if err != nil {
 			time.Sleep(500 * time.Millisecond)
 			continue
 		}
 
-		paneContent := string(output)
+		if state != lastState {
+			log.Debug().Str("target", target).Str("state", string(state)).Msg("Claude CLI state changed")
+		}
 
-		// Check typical output patterns when Claude CLI has started
-		if strings.Contains(paneContent, "claude") ||
-			strings.Contains(paneContent, ">") ||
-			strings.Contains(paneContent, "$") ||
-			len(strings.TrimSpace(paneContent)) > 10 {
+		switch state {
+		case agentstate.StateIdle:
 			log.Info().Str("target", target).Msg("✅ Claude CLI readiness detected")
 			return nil
+		case agentstate.StateAwaitingInput:
+			if lastState != state {
+				log.Warn().Str("target", target).Msg("⚠️ Claude CLI is waiting for a confirmation dialog")
+			}
+		case agentstate.StateRateLimited:
+			return fmt.Errorf("claude CLI in pane %s is rate limited", target)
 		}
+		lastState = state
 
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	log.Warn().Str("target", target).Dur("elapsed", time.Since(start)).Msg("⚠️ Claude CLI readiness wait timeout")
-	return fmt.Errorf("timeout waiting for Claude CLI to be ready in pane %s", target)
+	log.Warn().Str("target", target).Str("state", string(lastState)).Dur("elapsed", time.Since(start)).Msg("⚠️ Claude CLI readiness wait timeout")
+	return fmt.Errorf("timeout waiting for Claude CLI to be ready in pane %s (state: %s)", target, lastState)
 }
 
 // GetSessionInfo retrieves session information
@@ -847,7 +1047,7 @@ func (tm *TmuxManagerImpl) GetSessionInfo(sessionName string, expectedPaneCount
 // getWindowSize retrieves window size
 func (tm *TmuxManagerImpl) getWindowSize(sessionName string) (int, int, error) {
 	// Get width
-	widthCmd := exec.Command("tmux", "display-message", "-t", sessionName, "-p", "#{window_width}")
+	widthCmd := tmuxsocket.Command("display-message", "-t", sessionName, "-p", "#{window_width}")
 	widthOutput, err := widthCmd.Output()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get window width: %w", err)
@@ -859,7 +1059,7 @@ func (tm *TmuxManagerImpl) getWindowSize(sessionName string) (int, int, error) {
 	}
 
 	// Get height
-	heightCmd := exec.Command("tmux", "display-message", "-t", sessionName, "-p", "#{window_height}")
+	heightCmd := tmuxsocket.Command("display-message", "-t", sessionName, "-p", "#{window_height}")
 	heightOutput, err := heightCmd.Output()
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get window height: %w", err)
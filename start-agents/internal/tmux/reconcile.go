@@ -0,0 +1,200 @@
+package tmux
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// Reconciliation describes what ReconcileWindows changed in a running session
+type Reconciliation struct {
+	// Recreated lists the agents whose pane was recreated
+	Recreated []string
+	// Respawned lists the agents whose dead pane was restarted
+	Respawned []string
+	// Extra lists the panes that belong to no agent of the team
+	Extra []string
+}
+
+// sessionPane is a pane of a running session
+type sessionPane struct {
+	id     string
+	window string
+	dead   bool
+	agent  string
+}
+
+// sessionPanes retrieves the panes of the session in pane order with the agent published on them
+func (tm *TmuxManagerImpl) sessionPanes(sessionName string) ([]sessionPane, error) {
+	format := strings.Join([]string{"#{pane_id}", "#{window_id}", "#{pane_dead}", "#{" + manifest.OptionAgent + "}"}, "\t")
+	output, err := tmuxsocket.Command("list-panes", "-s", "-t", sessionName, "-F", format).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes of session %s: %w", sessionName, err)
+	}
+
+	var panes []sessionPane
+	// Only trim the line breaks: the agent field of the last pane may be empty
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		panes = append(panes, sessionPane{id: fields[0], window: fields[1], dead: fields[2] == "1", agent: fields[3]})
+	}
+	return panes, nil
+}
+
+// ReconcileWindows brings a running session in line with the team's windows: panes of agents
+// that were closed are recreated next to their neighbours and dead panes are restarted. Agents
+// are found through the agent option published on their panes (sessions launched before the
+// manifest existed are matched in pane order). Agents are addressed through their pane IDs
+// afterwards, as after CreateWindows.
+func (tm *TmuxManagerImpl) ReconcileWindows(sessionName string, windows []layout.Window) (*Reconciliation, error) {
+	panes, err := tm.sessionPanes(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	agents := layout.Agents(windows)
+	team := make(map[string]bool, len(agents))
+	for _, agent := range agents {
+		team[agent] = true
+	}
+
+	published := false
+	for _, pane := range panes {
+		published = published || pane.agent != ""
+	}
+	if !published {
+		for i := range panes {
+			if i < len(agents) {
+				panes[i].agent = agents[i]
+			}
+		}
+	}
+
+	result := &Reconciliation{}
+	tm.agentPanes = make(map[string]string)
+	agentWindows := make(map[string]string)
+	for _, pane := range panes {
+		if _, taken := tm.agentPanes[pane.agent]; !team[pane.agent] || taken {
+			result.Extra = append(result.Extra, pane.id)
+			continue
+		}
+		tm.agentPanes[pane.agent] = pane.id
+		agentWindows[pane.agent] = pane.window
+
+		if pane.dead {
+			if err := tmuxsocket.Command("respawn-pane", "-k", "-t", pane.id).Run(); err != nil {
+				return nil, fmt.Errorf("failed to restart the pane of %s: %w", pane.agent, err)
+			}
+			result.Respawned = append(result.Respawned, pane.agent)
+		}
+	}
+
+	for _, w := range windows {
+		present := make(map[string]bool)
+		windowID := ""
+		for _, agent := range w.Plan.Agents {
+			if _, ok := tm.agentPanes[agent]; ok {
+				present[agent] = true
+				if windowID == "" {
+					windowID = agentWindows[agent]
+				}
+			}
+		}
+		if len(present) == len(w.Plan.Agents) {
+			continue
+		}
+
+		recreated, err := tm.recreatePanes(sessionName, windowID, w, present)
+		if err != nil {
+			return nil, err
+		}
+		result.Recreated = append(result.Recreated, recreated...)
+	}
+
+	if len(result.Recreated) > 0 {
+		if err := tm.SetPaneTitles(sessionName, countDevelopers(agents)); err != nil {
+			return nil, fmt.Errorf("failed to set pane titles: %w", err)
+		}
+	}
+
+	log.Info().Str("session", sessionName).Strs("recreated", result.Recreated).Strs("respawned", result.Respawned).Strs("extra", result.Extra).Msg("Session reconciled")
+	return result, nil
+}
+
+// recreatePanes creates the panes of the window's agents that have none (the whole window when
+// windowID is empty) and arranges the window again. It returns the agents whose pane was created.
+func (tm *TmuxManagerImpl) recreatePanes(sessionName, windowID string, w layout.Window, present map[string]bool) ([]string, error) {
+	name := w.Name
+	if name == "" {
+		name = sessionName
+	}
+
+	if windowID == "" {
+		newWindow, err := tm.createWindow(sessionName, name, false)
+		if err != nil {
+			return nil, err
+		}
+		paneIDs, err := tm.createPanes(newWindow, w.Plan)
+		if err != nil {
+			return nil, fmt.Errorf("window %s: %w", name, err)
+		}
+		for i, agent := range w.Plan.Agents {
+			tm.agentPanes[agent] = paneIDs[i]
+		}
+		return append([]string(nil), w.Plan.Agents...), nil
+	}
+
+	var recreated []string
+	for _, insertion := range w.Plan.Insertions(present) {
+		// Retile before each split so the window does not run out of room
+		if err := tm.SelectLayout(windowID, "tiled"); err != nil {
+			return recreated, err
+		}
+		paneID, err := tm.splitPane(tm.agentPanes[insertion.Anchor], insertion.Before)
+		if err != nil {
+			return recreated, fmt.Errorf("failed to recreate the pane of %s: %w", insertion.Agent, err)
+		}
+		tm.agentPanes[insertion.Agent] = paneID
+		recreated = append(recreated, insertion.Agent)
+	}
+
+	// The layout places the panes by their order, which holds only while the window contains
+	// exactly the plan's panes
+	paneIDs, err := tm.windowPaneIDs(windowID)
+	if err != nil {
+		return recreated, err
+	}
+	inOrder := len(paneIDs) == len(w.Plan.Agents)
+	for i := 0; inOrder && i < len(paneIDs); i++ {
+		inOrder = paneIDs[i] == tm.agentPanes[w.Plan.Agents[i]]
+	}
+	if !inOrder {
+		log.Warn().Str("window", windowID).Msg("Window holds panes outside the layout, keeping the tiled arrangement")
+		return recreated, nil
+	}
+	if err := tm.applyLayout(windowID, w.Plan); err != nil {
+		log.Warn().Err(err).Str("window", windowID).Str("layout", w.Plan.Name).Msg("Failed to apply layout, using tiled")
+	}
+	return recreated, nil
+}
+
+// splitPane splits the target pane and returns the ID of the new pane, placed after the
+// target in pane order (before it when before is set)
+func (tm *TmuxManagerImpl) splitPane(target string, before bool) (string, error) {
+	args := []string{"split-window", "-d", "-v", "-P", "-F", "#{pane_id}", "-t", target}
+	if before {
+		args = append(args, "-b")
+	}
+	output, err := tmuxsocket.Command(args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("tmux command failed: split-window -t %s (output: %s)", target, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,84 @@
+package tmux
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+)
+
+// GetPaneIDs retrieves stable pane IDs (%N) of all panes in the session, in pane order
+func (tm *TmuxManagerImpl) GetPaneIDs(sessionName string) ([]string, error) {
+	cmd := tmuxsocket.Command("list-panes", "-s", "-t", sessionName, "-F", "#{pane_id}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pane IDs: %w", err)
+	}
+
+	var paneIDs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if paneID := strings.TrimSpace(line); paneID != "" {
+			paneIDs = append(paneIDs, paneID)
+		}
+	}
+
+	return paneIDs, nil
+}
+
+// SetSessionOption sets a tmux session option
+func (tm *TmuxManagerImpl) SetSessionOption(sessionName, option, value string) error {
+	cmd := tmuxsocket.Command("set-option", "-t", sessionName, option, value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set session option %s: %w (output: %s)", option, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SetPaneOption sets a tmux pane option
+func (tm *TmuxManagerImpl) SetPaneOption(paneID, option, value string) error {
+	cmd := tmuxsocket.Command("set-option", "-p", "-t", paneID, option, value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set pane option %s on %s: %w (output: %s)", option, paneID, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// PublishManifest mirrors the session manifest into tmux user options so that
+// send-agent can resolve agents even without access to the manifest file
+func (tm *TmuxManagerImpl) PublishManifest(m *manifest.Manifest, manifestPath string) error {
+	sessionOptions := []struct {
+		option string
+		value  string
+	}{
+		{manifest.OptionManifest, manifestPath},
+		{manifest.OptionDevCount, strconv.Itoa(m.DevCount)},
+		{manifest.OptionConfigHash, m.ConfigHash},
+		{manifest.OptionWorkingDir, m.WorkingDir},
+		{manifest.OptionLaunchedAt, m.LaunchedAt.Format(time.RFC3339)},
+	}
+
+	for _, opt := range sessionOptions {
+		if err := tm.SetSessionOption(m.SessionName, opt.option, opt.value); err != nil {
+			return err
+		}
+	}
+
+	for _, agent := range m.Agents {
+		if agent.PaneID == "" {
+			continue
+		}
+		if err := tm.SetPaneOption(agent.PaneID, manifest.OptionAgent, agent.Name); err != nil {
+			return err
+		}
+		if err := tm.SetPaneOption(agent.PaneID, manifest.OptionRole, agent.Role); err != nil {
+			return err
+		}
+	}
+
+	log.Debug().Str("session", m.SessionName).Str("manifest", manifestPath).Msg("Session manifest published to tmux options")
+	return nil
+}
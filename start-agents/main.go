@@ -7,7 +7,6 @@ import (
 
 	"github.com/shivase/claude-code-agents/internal/cmd"
 	"github.com/shivase/claude-code-agents/internal/logger"
-	"github.com/shivase/claude-code-agents/internal/tmux"
 )
 
 func main() {
@@ -21,12 +20,6 @@ func main() {
 		}
 	}
 
-	// Check if running inside tmux environment
-	isInTmux, tmuxErr := tmux.IsInsideTmux()
-	if isInTmux {
-		tmux.PrintErrorMessage(debugMode, tmuxErr)
-		os.Exit(1)
-	}
 	logLevel := "info"
 	if debugMode {
 		logLevel = "debug"
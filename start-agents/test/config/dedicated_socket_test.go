@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTeamConfigDedicatedSocket - DEDICATED_SOCKET設定の読み込みと保存のテスト
+func TestTeamConfigDedicatedSocket(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	loader := config.NewTeamConfigLoader(configPath)
+
+	// 未設定の場合はデフォルトのtmuxサーバーを使用
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.False(t, tc.DedicatedSocket)
+
+	tc.DedicatedSocket = true
+	require.NoError(t, loader.SaveTeamConfig(tc))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "DEDICATED_SOCKET=true\n")
+
+	reloaded, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.True(t, reloaded.DedicatedSocket)
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadTeamConfigLayouts - LAYOUT_<NAME>設定の読み込みと保存のテスト
+func TestLoadTeamConfigLayouts(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	content := `DEV_COUNT=6
+DEFAULT_LAYOUT=wide
+LAYOUT_WIDE=h(v(po,manager):25,devs)
+LAYOUT_SQUARE=grid:3
+LAYOUT_TILED=even-vertical
+LAYOUT_BROKEN=h(po,manager
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"wide":   "h(v(po,manager):25,devs)",
+		"square": "grid:3",
+	}, tc.Layouts)
+
+	windows, err := tc.LayoutWindows()
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, "wide", windows[0].Plan.Name)
+	assert.Equal(t, []string{"po", "manager", "dev1", "dev2", "dev3", "dev4", "dev5", "dev6"}, windows[0].Plan.Agents)
+
+	loader := config.NewTeamConfigLoader(configPath)
+	require.NoError(t, loader.SaveTeamConfig(tc))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "LAYOUT_SQUARE=grid:3\nLAYOUT_WIDE=h(v(po,manager):25,devs)\n")
+
+	reloaded, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, tc.Layouts, reloaded.Layouts)
+}
+
+// TestTeamConfigLayoutPlan - DEFAULT_LAYOUTとペイン順の対応のテスト
+func TestTeamConfigLayoutPlan(t *testing.T) {
+	tc := &config.TeamConfig{DevCount: 2, DefaultLayout: "h(devs,v(manager,po))"}
+
+	paneMap := tc.GetPaneAgentMap()
+	assert.Equal(t, "dev1", paneMap["1"])
+	assert.Equal(t, "po", paneMap["4"])
+	assert.Equal(t, "Manager", tc.GetPaneTitles()["3"])
+
+	// The individual launch mode keeps the integrated pane order
+	tc.DefaultLayout = "individual"
+	windows, err := tc.LayoutWindows()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"po", "manager", "dev1", "dev2"}, windows[0].Plan.Agents)
+
+	tc.DefaultLayout = "nonexistent"
+	_, err = tc.LayoutWindows()
+	assert.Error(t, err)
+}
+
+// TestTeamConfigDevsPerWindow - DEVS_PER_WINDOWによる複数ウィンドウ構成のテスト
+func TestTeamConfigDevsPerWindow(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	content := `DEV_COUNT=5
+DEVS_PER_WINDOW=3
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tc.DevsPerWindow)
+
+	windows, err := tc.LayoutWindows()
+	require.NoError(t, err)
+	require.Len(t, windows, 3)
+	assert.Equal(t, []string{"po", "manager"}, windows[0].Plan.Agents)
+	assert.Equal(t, []string{"dev4", "dev5"}, windows[2].Plan.Agents)
+
+	// Pane numbers follow the window order
+	assert.Equal(t, "dev3", tc.GetPaneAgentMap()["5"])
+}
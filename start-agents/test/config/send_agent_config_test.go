@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadTeamConfigGroups - GROUP_<NAME>設定の読み込みテスト
+func TestLoadTeamConfigGroups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	content := `DEV_COUNT=4
+GROUP_FRONTEND=dev1, dev2
+GROUP_LEADS=po,manager
+GROUP_ALL=dev1
+GROUP_BROKEN=dev1,unknown
+GROUP_EMPTY=
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"frontend": {"dev1", "dev2"},
+		"leads":    {"po", "manager"},
+		"broken":   {"dev1"},
+	}, tc.Groups)
+}
+
+// TestSaveTeamConfigGroups - グループ設定が保存後も維持されることのテスト
+func TestSaveTeamConfigGroups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	loader := config.NewTeamConfigLoader(configPath)
+
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	tc.Groups = map[string][]string{"backend": {"dev3", "dev4"}, "frontend": {"dev1"}}
+	require.NoError(t, loader.SaveTeamConfig(tc))
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "GROUP_BACKEND=dev3,dev4\nGROUP_FRONTEND=dev1\n")
+
+	reloaded, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, tc.Groups, reloaded.Groups)
+}
+
+// TestLoadTeamConfigRoutes - ROUTE_<ROLE>設定の読み込みと保存のテスト
+func TestLoadTeamConfigRoutes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "agents.conf")
+	content := `ROUTE_DEV=manager
+ROUTE_MANAGER=*
+ROUTE_PO=Manager, dev1
+ROUTE_DEV1=po
+ROUTE_QA=manager
+ROUTE_MANAGER_X=everyone
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+
+	tc, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"dev":     {"manager"},
+		"manager": {"*"},
+		"po":      {"manager", "dev1"},
+	}, tc.Routes)
+
+	require.NoError(t, config.NewTeamConfigLoader(configPath).SaveTeamConfig(tc))
+	reloaded, err := config.LoadTeamConfigFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, tc.Routes, reloaded.Routes)
+}
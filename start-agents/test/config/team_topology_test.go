@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTeamConfigTopology - DEV_COUNTに応じたエージェント構成のテスト
+func TestTeamConfigTopology(t *testing.T) {
+	tc := &config.TeamConfig{DevCount: 6}
+
+	assert.Equal(t, []string{"po", "manager", "dev1", "dev2", "dev3", "dev4", "dev5", "dev6"}, tc.GetAgentList())
+
+	paneMap := tc.GetPaneAgentMap()
+	assert.Len(t, paneMap, 8)
+	assert.Equal(t, "po", paneMap["1"])
+	assert.Equal(t, "dev6", paneMap["8"])
+
+	titles := tc.GetPaneTitles()
+	assert.Equal(t, "Manager", titles["2"])
+	assert.Equal(t, "Dev5", titles["7"])
+}
+
+// TestInstructionResolverDynamicDevs - dev5以降も開発者ロールとして解決されることのテスト
+func TestInstructionResolverDynamicDevs(t *testing.T) {
+	tc := &config.TeamConfig{DevCount: 6, InstructionsDir: t.TempDir()}
+	resolver := config.NewInstructionResolver(tc)
+
+	roles := resolver.GetAvailableRoles()
+	assert.Contains(t, roles, "dev6")
+	assert.NotContains(t, roles, "dev7")
+
+	devPath, err := resolver.ResolveInstructionPath("dev")
+	assert.NoError(t, err)
+	dev6Path, err := resolver.ResolveInstructionPath("dev6")
+	assert.NoError(t, err)
+	assert.Equal(t, devPath, dev6Path)
+}
@@ -0,0 +1,177 @@
+package layout
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shape renders a tree compactly, e.g. "h(v(po,manager):50,v(dev1,dev2))"
+func shape(n *layout.Node) string {
+	var s string
+	if n.Split == 0 {
+		s = n.Agent
+	} else {
+		parts := make([]string, len(n.Children))
+		for i, child := range n.Children {
+			parts[i] = shape(child)
+		}
+		direction := "h"
+		if n.Split == layout.Vertical {
+			direction = "v"
+		}
+		s = direction + "(" + strings.Join(parts, ",") + ")"
+	}
+	if n.Percent > 0 {
+		s += fmt.Sprintf(":%d", n.Percent)
+	}
+	return s
+}
+
+// TestIntegratedPreset - 開発者数に応じた統合レイアウトのテスト
+func TestIntegratedPreset(t *testing.T) {
+	tests := []struct {
+		devs  int
+		shape string
+	}{
+		{0, "h(po,manager)"},
+		{1, "h(v(po,manager):50,dev1)"},
+		{4, "h(v(po,manager):50,v(dev1,dev2,dev3,dev4))"},
+		{6, "h(v(po,manager):33,v(h(dev1,dev2),h(dev3,dev4),h(dev5,dev6)))"},
+		{10, "h(v(po,manager):25,v(h(dev1,dev2,dev3),h(dev4,dev5,dev6),h(dev7,dev8,dev9),dev10))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("devs=%d", tt.devs), func(t *testing.T) {
+			plan, err := layout.Resolve("", nil, topology.New(tt.devs).Names())
+			require.NoError(t, err)
+			assert.Equal(t, layout.Integrated, plan.Name)
+			assert.Equal(t, tt.shape, shape(plan.Root))
+			assert.Equal(t, topology.New(tt.devs).Names(), plan.Agents)
+		})
+	}
+}
+
+// TestResolveGridAndBuiltins - grid・tmux組み込みレイアウトの解決テスト
+func TestResolveGridAndBuiltins(t *testing.T) {
+	agents := topology.New(3).Names()
+
+	plan, err := layout.Resolve("grid", nil, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "v(h(po,manager,dev1),h(dev2,dev3))", shape(plan.Root))
+
+	plan, err = layout.Resolve("grid:2", nil, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "v(h(po,manager),h(dev1,dev2),dev3)", shape(plan.Root))
+
+	plan, err = layout.Resolve("main-vertical", nil, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "main-vertical", plan.Builtin)
+	assert.Nil(t, plan.Root)
+	assert.Equal(t, agents, plan.Agents)
+
+	_, err = layout.Resolve("grid:0", nil, agents)
+	assert.Error(t, err)
+	_, err = layout.Resolve("spiral", nil, agents)
+	assert.Error(t, err)
+}
+
+// TestResolveSplitTree - 分割ツリーとdevs展開・ペイン順のテスト
+func TestResolveSplitTree(t *testing.T) {
+	agents := topology.New(5).Names()
+	custom := map[string]string{"wide": "h(v(po,manager):25,devs)", "alias": "wide", "loop": "loop"}
+
+	plan, err := layout.Resolve("alias", custom, agents)
+	require.NoError(t, err)
+	assert.Equal(t, "alias", plan.Name)
+	assert.Equal(t, "h(v(po,manager):25,v(h(dev1,dev2),h(dev3,dev4),dev5))", shape(plan.Root))
+
+	plan, err = layout.Resolve("v(h(dev2, dev1), h(manager:30, po), devs)", nil, agents)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev2", "dev1", "manager", "po", "dev3", "dev4", "dev5"}, plan.Agents)
+	index, ok := plan.PaneIndex("po")
+	assert.True(t, ok)
+	assert.Equal(t, 3, index)
+
+	// devs disappears when every developer is placed explicitly
+	plan, err = layout.Resolve("h(po,manager,dev1,devs)", nil, topology.New(1).Names())
+	require.NoError(t, err)
+	assert.Equal(t, "h(po,manager,dev1)", shape(plan.Root))
+
+	_, err = layout.Resolve("loop", custom, agents)
+	assert.ErrorContains(t, err, "refers to itself")
+}
+
+// TestResolveSplitTreeErrors - 不正な分割ツリーのエラーテスト
+func TestResolveSplitTreeErrors(t *testing.T) {
+	agents := topology.New(2).Names()
+	tests := map[string]string{
+		"h(po,manager)":                 "agent 'dev1' is not placed",
+		"h(v(po,manager),devs,dev1)":    "",
+		"h(po,po,manager,devs)":         "placed twice",
+		"h(po,manager,dev9,devs)":       "not part of the team",
+		"h(v(po,manager),devs,devs)":    "only once",
+		"h(po:60,manager:50,devs)":      "add up to 110%",
+		"h(po:0,manager,devs)":          "between 1 and 99",
+		"h(po,manager,devs":             "expected ',' or ')'",
+		"h(x(po),manager,devs)":         "unknown split 'x'",
+		"h(po,manager,devs)extra":       "unexpected 'e'",
+		"h(po,,manager,devs)":           "expected an agent",
+		"v(h(po,manager),h(dev1,dev2))": "",
+	}
+
+	for spec, message := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := layout.Resolve(spec, nil, agents)
+			if message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, message)
+		})
+	}
+}
+
+// TestValidate - LAYOUT_<NAME>の名前と定義の検証テスト
+func TestValidate(t *testing.T) {
+	assert.NoError(t, layout.Validate("h(v(po,manager):35,devs)"))
+	assert.NoError(t, layout.Validate("grid:4"))
+	assert.NoError(t, layout.Validate("tiled"))
+	assert.Error(t, layout.Validate(""))
+	assert.Error(t, layout.Validate("h(po"))
+	assert.Error(t, layout.Validate("grid:x"))
+
+	assert.NoError(t, layout.ValidateName("wide"))
+	assert.Error(t, layout.ValidateName("tiled"))
+	assert.Error(t, layout.ValidateName("grid"))
+	assert.Error(t, layout.ValidateName("Wide Screen"))
+}
+
+// TestRender - tmuxレイアウト文字列の生成テスト
+func TestRender(t *testing.T) {
+	plan, err := layout.Resolve("", nil, topology.New(2).Names())
+	require.NoError(t, err)
+
+	rendered, err := layout.Render(plan.Root, []string{"%1", "%2", "%3", "%4"}, 100, 40)
+	require.NoError(t, err)
+
+	body := "100x40,0,0{49x40,0,0[49x19,0,0,1,49x20,0,20,2],50x40,50,0[50x19,50,0,3,50x20,50,20,4]}"
+	assert.Equal(t, fmt.Sprintf("%04x,%s", layout.Checksum(body), body), rendered)
+
+	_, err = layout.Render(plan.Root, []string{"%1", "%2"}, 100, 40)
+	assert.ErrorContains(t, err, "4 cells but the window has 2 panes")
+
+	_, err = layout.Render(plan.Root, []string{"%1", "%2", "%3", "%4"}, 100, 2)
+	assert.ErrorContains(t, err, "too small")
+}
+
+// TestChecksum - tmuxのレイアウトチェックサムとの一致テスト
+func TestChecksum(t *testing.T) {
+	// Layout reported by tmux list-windows for a window with two side-by-side panes
+	assert.Equal(t, uint16(0x020a), layout.Checksum("80x24,0,0{40x24,0,0,1,39x24,41,0,2}"))
+}
@@ -0,0 +1,38 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlanInsertions - 閉じられたペインをペイン順を保って再作成する位置のテスト
+func TestPlanInsertions(t *testing.T) {
+	plan, err := layout.Resolve("", nil, topology.New(3).Names())
+	require.NoError(t, err)
+
+	// 全エージェントが存在する場合は何もしない
+	all := map[string]bool{"po": true, "manager": true, "dev1": true, "dev2": true, "dev3": true}
+	assert.Empty(t, plan.Insertions(all))
+
+	// 途中のエージェントは直前のエージェントの後ろに作成
+	assert.Equal(t, []layout.Insertion{
+		{Agent: "dev1", Anchor: "manager"},
+		{Agent: "dev2", Anchor: "dev1"},
+	}, plan.Insertions(map[string]bool{"po": true, "manager": true, "dev3": true}))
+
+	// 先頭のエージェントは最初に存在するエージェントの前に作成
+	assert.Equal(t, []layout.Insertion{
+		{Agent: "po", Anchor: "dev1", Before: true},
+		{Agent: "manager", Anchor: "po"},
+	}, plan.Insertions(map[string]bool{"dev1": true, "dev2": true, "dev3": true}))
+
+	// 全エージェントが存在しない場合は基準ペインなし
+	insertions := plan.Insertions(map[string]bool{})
+	require.Len(t, insertions, 5)
+	assert.Equal(t, layout.Insertion{Agent: "po"}, insertions[0])
+	assert.Equal(t, layout.Insertion{Agent: "dev3", Anchor: "dev2"}, insertions[4])
+}
@@ -0,0 +1,57 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGroupDevelopers - 開発者を均等なウィンドウグループに分割するテスト
+func TestGroupDevelopers(t *testing.T) {
+	devs := topology.New(14).Names()[topology.LeaderCount:]
+
+	assert.Len(t, layout.GroupDevelopers(devs[:10], 0), 1)
+
+	groups := layout.GroupDevelopers(devs, 0)
+	require.Len(t, groups, 2)
+	assert.Len(t, groups[0], 7)
+	assert.Len(t, groups[1], 7)
+
+	groups = layout.GroupDevelopers(devs, 4)
+	require.Len(t, groups, 4)
+	assert.Equal(t, []string{"dev13", "dev14"}, groups[3])
+
+	assert.Nil(t, layout.GroupDevelopers(nil, 4))
+}
+
+// TestWindows - 複数ウィンドウ構成とウィンドウごとのレイアウトのテスト
+func TestWindows(t *testing.T) {
+	windows, err := layout.Windows("", nil, topology.New(4).Names(), 0)
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Empty(t, windows[0].Name)
+
+	agents := topology.New(14).Names()
+	windows, err = layout.Windows("h(v(po,manager):35,devs)", nil, agents, 0)
+	require.NoError(t, err)
+	require.Len(t, windows, 3)
+	assert.Equal(t, layout.LeadersWindow, windows[0].Name)
+	assert.Equal(t, "v(po,manager)", shape(windows[0].Plan.Root))
+	assert.Equal(t, "dev1-dev7", windows[1].Name)
+	assert.Equal(t, "v(h(dev1,dev2),h(dev3,dev4),h(dev5,dev6),dev7)", shape(windows[1].Plan.Root))
+	assert.Equal(t, "dev8-dev14", windows[2].Name)
+	assert.Equal(t, agents, layout.Agents(windows))
+
+	// Grids and tmux layouts apply to every window
+	windows, err = layout.Windows("tiled", nil, topology.New(3).Names(), 1)
+	require.NoError(t, err)
+	require.Len(t, windows, 4)
+	assert.Equal(t, "dev2", windows[2].Name)
+	assert.Equal(t, "tiled", windows[2].Plan.Builtin)
+
+	_, err = layout.Windows("h(v(po,dev1),manager,devs)", nil, agents, 0)
+	assert.ErrorContains(t, err, "leads window")
+}
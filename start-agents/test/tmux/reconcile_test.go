@@ -0,0 +1,102 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shivase/claude-code-agents/internal/layout"
+	"github.com/shivase/claude-code-agents/internal/tmux"
+	"github.com/shivase/cloud-code-agents/shared/manifest"
+	"github.com/shivase/cloud-code-agents/shared/tmuxsocket"
+	"github.com/shivase/cloud-code-agents/shared/topology"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestServer 専用ソケットのtmuxサーバーでテストセッションを作成する
+func startTestServer(t *testing.T, sessionName string) *tmux.TmuxManagerImpl {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux is not installed")
+	}
+
+	// 専用サーバーのプレフィックスを付けず、他のプロセスやテストから見えないようにする
+	name := strings.NewReplacer("/", "-", " ", "-").Replace(t.Name())
+	socket := fmt.Sprintf("%s-%d-%d", name, os.Getpid(), time.Now().UnixNano())
+	t.Setenv(tmuxsocket.EnvSocket, socket)
+	t.Cleanup(func() {
+		_ = exec.Command("tmux", "-L", socket, "kill-server").Run()
+		_ = os.Remove(filepath.Join(tmuxsocket.Dir(), socket))
+	})
+
+	require.NoError(t, tmuxsocket.Command("new-session", "-d", "-s", sessionName, "-x", "200", "-y", "60").Run())
+	return tmux.NewTmuxManager(sessionName)
+}
+
+// paneOption ペインのユーザーオプションを取得する
+func paneOption(t *testing.T, paneID, option string) string {
+	t.Helper()
+	output, err := tmuxsocket.Command("display-message", "-p", "-t", paneID, "#{"+option+"}").Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(output))
+}
+
+// TestReconcileWindows - 閉じられたペインの再作成と管理外ペインの保持のテスト
+func TestReconcileWindows(t *testing.T) {
+	sessionName := "reconcile-test"
+	tm := startTestServer(t, sessionName)
+
+	windows, err := layout.Windows("", nil, topology.New(3).Names(), 0)
+	require.NoError(t, err)
+	require.NoError(t, tm.CreateWindows(sessionName, windows))
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+
+	m := &manifest.Manifest{SessionName: sessionName}
+	for _, agent := range topology.New(3).Agents() {
+		paneID, ok := tm.AgentPane(agent.Name)
+		require.True(t, ok)
+		m.Agents = append(m.Agents, manifest.Agent{Name: agent.Name, Role: agent.Role, PaneID: paneID})
+	}
+	require.NoError(t, tm.PublishManifest(m, manifestPath))
+
+	// 管理外のペインを追加し、managerのペインを閉じる
+	dev3, _ := tm.AgentPane("dev3")
+	output, err := tmuxsocket.Command("split-window", "-d", "-P", "-F", "#{pane_id}", "-t", dev3).Output()
+	require.NoError(t, err)
+	extra := strings.TrimSpace(string(output))
+	manager, _ := tm.AgentPane("manager")
+	require.NoError(t, tmuxsocket.Command("kill-pane", "-t", manager).Run())
+
+	reconciler := tmux.NewTmuxManager(sessionName)
+	result, err := reconciler.ReconcileWindows(sessionName, windows)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"manager"}, result.Recreated)
+	assert.Equal(t, []string{extra}, result.Extra)
+	assert.Empty(t, result.Respawned)
+
+	// 全エージェントのペインが解決でき、管理外ペインは残る
+	recreated, ok := reconciler.AgentPane("manager")
+	require.True(t, ok)
+	assert.NotEqual(t, manager, recreated)
+	paneIDs, err := reconciler.GetPaneIDs(sessionName)
+	require.NoError(t, err)
+	assert.Len(t, paneIDs, 6)
+	assert.Contains(t, paneIDs, extra)
+
+	// セッションのペイン数に関係なくエージェントのペインにオプションを再設定できる
+	m.Agents[1].PaneID = recreated
+	require.NoError(t, reconciler.PublishManifest(m, manifestPath))
+	assert.Equal(t, "manager", paneOption(t, recreated, manifest.OptionAgent))
+	assert.Equal(t, "", paneOption(t, extra, manifest.OptionAgent))
+
+	// 2回目は何も変更しない
+	result, err = tmux.NewTmuxManager(sessionName).ReconcileWindows(sessionName, windows)
+	require.NoError(t, err)
+	assert.Empty(t, result.Recreated)
+	assert.Equal(t, []string{extra}, result.Extra)
+}